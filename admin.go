@@ -0,0 +1,222 @@
+package cronjob
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// jobView is the JSON form of a JobInfo served by Handler.
+type jobView struct {
+	ID           string            `json:"id"`
+	Expression   string            `json:"expression"`
+	NextRun      *time.Time        `json:"next_run,omitempty"`
+	NextRuns     []time.Time       `json:"next_runs,omitempty"`
+	LastRun      *time.Time        `json:"last_run,omitempty"`
+	LastError    string            `json:"last_error,omitempty"`
+	LastDuration string            `json:"last_duration,omitempty"`
+	RunCount     int               `json:"run_count"`
+	Paused       bool              `json:"paused"`
+	Group        string            `json:"group,omitempty"`
+	Priority     int               `json:"priority,omitempty"`
+	QueueDepth   int               `json:"queue_depth,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	History      []runView         `json:"history,omitempty"`
+}
+
+// runView is the JSON form of a RunRecord served by Handler.
+type runView struct {
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Duration   string    `json:"duration"`
+	Outcome    string    `json:"outcome"`
+	Error      string    `json:"error,omitempty"`
+	Stdout     string    `json:"stdout,omitempty"`
+	Stderr     string    `json:"stderr,omitempty"`
+	StatusCode int       `json:"status_code,omitempty"`
+}
+
+func newJobView(info JobInfo) jobView {
+	view := jobView{
+		ID:         info.ID,
+		Expression: info.Expression,
+		RunCount:   info.RunCount,
+		Paused:     info.Paused,
+		Group:      info.Group,
+		Priority:   info.Priority,
+		QueueDepth: info.QueueDepth,
+		Metadata:   info.Metadata,
+	}
+	if !info.NextRun.IsZero() {
+		view.NextRun = &info.NextRun
+	}
+	if !info.LastRun.IsZero() {
+		view.LastRun = &info.LastRun
+		view.LastDuration = info.LastDuration.String()
+	}
+	if info.LastError != nil {
+		view.LastError = info.LastError.Error()
+	}
+	return view
+}
+
+func newRunView(record RunRecord) runView {
+	view := runView{
+		Start:      record.Start,
+		End:        record.End,
+		Duration:   record.Duration.String(),
+		Outcome:    record.Outcome.String(),
+		Stdout:     record.Stdout,
+		Stderr:     record.Stderr,
+		StatusCode: record.StatusCode,
+	}
+	if record.Err != nil {
+		view.Error = record.Err.Error()
+	}
+	return view
+}
+
+// Handler returns an http.Handler exposing a JSON admin API for the
+// scheduler:
+//
+//	GET  /jobs               list every job, with their next runs (?n=) and
+//	                         most recent runs (?history=), default none
+//	GET  /jobs/{id}          one job, with its next runs (?n=, default 5)
+//	GET  /jobs/{id}/history  the job's recent runs
+//	POST /jobs/{id}/pause    pause the job
+//	POST /jobs/{id}/resume   resume the job
+//	POST /jobs/{id}/run      trigger a run now
+//
+// The ?n= and ?history= counts are at most 100; larger ones are rejected
+// with 400 Bad Request.
+//
+// Mount it under a prefix with http.StripPrefix, and protect it as any
+// other admin endpoint: it has no authentication of its own.
+func (c *CronScheduler) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /jobs", func(w http.ResponseWriter, r *http.Request) {
+		n, err := queryCount(r, "n", 0)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		runs, err := queryCount(r, "history", 0)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		infos := c.ListJobInfo()
+		views := make([]jobView, 0, len(infos))
+		for _, info := range infos {
+			view := newJobView(info)
+			if n > 0 {
+				// A job removed since the listing has no next runs.
+				view.NextRuns, _ = c.NextRuns(info.ID, n)
+			}
+			if runs > 0 {
+				history, _ := c.History(info.ID)
+				for _, record := range history[max(len(history)-runs, 0):] {
+					view.History = append(view.History, newRunView(record))
+				}
+			}
+			views = append(views, view)
+		}
+		writeJSON(w, http.StatusOK, views)
+	})
+	mux.HandleFunc("GET /jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		n, err := queryCount(r, "n", 5)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		id := r.PathValue("id")
+		info, err := c.JobInfo(id)
+		if err != nil {
+			writeJobError(w, err)
+			return
+		}
+		view := newJobView(info)
+		if view.NextRuns, err = c.NextRuns(id, n); err != nil {
+			writeJobError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, view)
+	})
+	mux.HandleFunc("GET /jobs/{id}/history", func(w http.ResponseWriter, r *http.Request) {
+		history, err := c.History(r.PathValue("id"))
+		if err != nil {
+			writeJobError(w, err)
+			return
+		}
+		views := make([]runView, 0, len(history))
+		for _, record := range history {
+			views = append(views, newRunView(record))
+		}
+		writeJSON(w, http.StatusOK, views)
+	})
+	mux.HandleFunc("POST /jobs/{id}/pause", func(w http.ResponseWriter, r *http.Request) {
+		if err := c.PauseJob(r.PathValue("id")); err != nil {
+			writeJobError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /jobs/{id}/resume", func(w http.ResponseWriter, r *http.Request) {
+		if err := c.ResumeJob(r.PathValue("id")); err != nil {
+			writeJobError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /jobs/{id}/run", func(w http.ResponseWriter, r *http.Request) {
+		if err := c.RunNow(r.PathValue("id")); err != nil {
+			writeJobError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+	return mux
+}
+
+// maxQueryCount caps the counts the handler accepts in ?n= and ?history=,
+// so no request makes it compute an unbounded list of runs.
+const maxQueryCount = 100
+
+// queryCount returns the integer query parameter name of r, from 0 to
+// maxQueryCount, or def if it is not set.
+func queryCount(r *http.Request, name string, def int) (int, error) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > maxQueryCount {
+		return 0, fmt.Errorf("%s must be an integer from 0 to %d", name, maxQueryCount)
+	}
+	return n, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// writeJobError maps a scheduler error to its HTTP status.
+func writeJobError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrJobNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, ErrJobSkipped):
+		writeError(w, http.StatusConflict, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,169 @@
+package cronjob
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Warning is a problem with an expression that parses but may not fire as
+// its author meant, as found by Analyze.
+type Warning struct {
+	// Field is the name of the field the warning is about, such as
+	// "day-of-month", or empty if it is about the whole expression.
+	Field string
+	// Never is set when the expression can never fire again.
+	Never bool
+	// Message describes the problem, e.g. "day 31 does not exist in
+	// February".
+	Message string
+}
+
+func (w Warning) String() string {
+	if w.Field == "" {
+		return w.Message
+	}
+	return fmt.Sprintf("%s field: %s", w.Field, w.Message)
+}
+
+// Analyze reports problems with the expression that make it fire never or
+// irregularly: days of the month that never fall in the months it lists,
+// as in "0 0 31 2 *", which never fires, days 29 to 31, which some months
+// lack so the job skips them, and day and year fields that leave no day to
+// fire on. "@every" and "@reboot" expressions have no warnings.
+func (expr *CronExpression) Analyze() []Warning {
+	return analyze(expr, time.Now())
+}
+
+// ValidateWarnings validates expr as Validate does and, if it is valid,
+// returns the warnings Analyze reports for it, so dead schedules can be
+// caught when they are entered.
+func ValidateWarnings(expr string, opts ...ParseOption) ([]Warning, error) {
+	parsed, err := ParseCronExpression(expr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return parsed.Analyze(), nil
+}
+
+// ValidateWarnings validates expr as Validate does and, if it is a valid
+// cron expression, returns the warnings Analyze reports for it as the
+// scheduler would run it. The scheduler logs the same warnings, with
+// WithLogger, when a job is added.
+func (c *CronScheduler) ValidateWarnings(expr string) ([]Warning, error) {
+	schedule, err := c.parseSchedule(expr, "")
+	if err != nil {
+		return nil, err
+	}
+	if expr, ok := schedule.(*CronExpression); ok {
+		return expr.Analyze(), nil
+	}
+	return nil, nil
+}
+
+// logWarnings logs the warnings Analyze reports for a job's schedule, parsed
+// from expr, if the scheduler has a logger.
+func (c *CronScheduler) logWarnings(expr string, schedule Schedule) {
+	parsed, ok := schedule.(*CronExpression)
+	if c.logger == nil || !ok {
+		return
+	}
+	for _, warning := range parsed.Analyze() {
+		c.logger.Warn("job schedule warning",
+			slog.String("expr", expr),
+			slog.String("warning", warning.String()))
+	}
+}
+
+// analyze returns the warnings for expr firing after from.
+func analyze(expr *CronExpression, from time.Time) []Warning {
+	if expr.reboot || expr.interval > 0 {
+		return nil
+	}
+	var warnings []Warning
+	if !expr.anyDayOfMonth {
+		warnings = missingDays(expr)
+	}
+	if !firesOnSomeDay(expr, from) {
+		warnings = append(warnings, Warning{Never: true, Message: "the expression never fires"})
+	}
+	return warnings
+}
+
+// missingDays warns about each part of the day-of-month field naming a day
+// that some of the listed months lack.
+func missingDays(expr *CronExpression) []Warning {
+	type part struct {
+		name string
+		// days is how many days a month needs for the part to fall in it.
+		days int
+	}
+	var parts []part
+	for _, day := range expr.daysOfMonth.values() {
+		parts = append(parts, part{fmt.Sprintf("day %d", day), day})
+	}
+	for _, day := range normalizeValues(expr.nearestWeekdays) {
+		parts = append(parts, part{fmt.Sprintf("day %dW", day), day})
+	}
+	for _, offset := range normalizeValues(expr.lastDaysOfMonth) {
+		if offset > 0 {
+			parts = append(parts, part{fmt.Sprintf("L-%d", offset), offset + 1})
+		}
+	}
+	var warnings []Warning
+	for _, p := range parts {
+		if p.days < 29 {
+			continue
+		}
+		var months []string
+		for _, month := range expr.months.values() {
+			switch days := monthDays[month-1]; {
+			case days < p.days:
+				months = append(months, time.Month(month).String())
+			case month == 2 && p.days == 29:
+				months = append(months, "February outside leap years")
+			}
+		}
+		if months != nil {
+			warnings = append(warnings, Warning{
+				Field:   "day-of-month",
+				Message: fmt.Sprintf("%s does not exist in %s", p.name, joinList(months)),
+			})
+		}
+	}
+	return warnings
+}
+
+// monthDays is the most days each month can have, by month number from 1.
+var monthDays = [12]int{31, 29, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+
+// firesOnSomeDay reports whether there is a day after from matching the
+// day, month and year fields of expr. The days of 28 years hold every
+// combination of weekday and leap year, so with no year field the search
+// stops after them, and otherwise at the end of the last listed year.
+func firesOnSomeDay(expr *CronExpression, from time.Time) bool {
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := day.AddDate(28, 0, 0)
+	if expr.years != nil {
+		end = time.Date(slices.Max(expr.years)+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	for ; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if expr.years != nil && !slices.Contains(expr.years, day.Year()) {
+			continue
+		}
+		if expr.months.has(int(day.Month())) && isDayMatching(expr, day) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinList joins items as in "a, b and c".
+func joinList(items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
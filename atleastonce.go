@@ -0,0 +1,174 @@
+package cronjob
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ErrRunInterrupted is reported to the OnError handler, wrapped with the
+// run's scheduled time, for a run of a WithAtLeastOnce job that a previous
+// process started but did not finish, when the job's RecoveryPolicy is
+// DiscardInterrupted.
+var ErrRunInterrupted = errors.New("job run interrupted")
+
+// RecoveryPolicy controls what a WithAtLeastOnce job does about the runs a
+// previous process started but did not finish, as after a crash.
+type RecoveryPolicy int
+
+const (
+	// RerunInterrupted runs the job again for every interrupted run, one
+	// after the other and in order, with the run's original scheduled
+	// time. This is the default.
+	RerunInterrupted RecoveryPolicy = iota
+	// DiscardInterrupted does not run them again, reporting each to the
+	// OnError handler as an ErrRunInterrupted instead.
+	DiscardInterrupted
+)
+
+// WithAtLeastOnce makes the job's scheduled runs survive crashes, for
+// critical jobs: each run is recorded in the scheduler's JobStore before
+// it is dispatched and cleared once its task has run, whatever the outcome,
+// so on the next Start the runs a previous process left unfinished, and
+// those dropped before their task started, are found and handled by
+// policy. A run may then happen twice, if the process died
+// after the task finished but before the run was cleared, so the task
+// should be idempotent. It only applies to jobs saved in the store, such
+// as those added with AddNamedJob, and to the runs the scheduler starts
+// for the job's fire times; runs queued by QueueOne or QueueAll, runs
+// started by WithDependsOn and manually triggered runs are not recorded.
+// Interrupted runs are not also counted as missed by WithCatchUp, nor
+// reported by WithLatenessTolerance when they are rerun.
+func WithAtLeastOnce(policy RecoveryPolicy) JobOption {
+	return func(j *Job) {
+		j.atLeastOnce = true
+		j.recovery = policy
+	}
+}
+
+// restoreRecord gives a job being added the run state saved in record by a
+// previous process.
+func (j *Job) restoreRecord(record JobRecord) {
+	if j.lastSuccess.IsZero() {
+		j.lastSuccess = record.LastSuccess
+	}
+	if j.atLeastOnce {
+		j.pending = slices.Clone(record.Pending)
+		j.interrupted = slices.Clone(record.Pending)
+	}
+}
+
+// takeInterrupted returns the runs a previous process left unfinished for
+// recoverRuns, once and only while the scheduler is running. The caller
+// must hold c.mutex.
+func (c *CronScheduler) takeInterrupted(job *Job) []time.Time {
+	if !c.running || len(job.interrupted) == 0 {
+		return nil
+	}
+	ticks := job.interrupted
+	job.interrupted = nil
+	return ticks
+}
+
+// beginRun records the run of job scheduled at tick in the store before it
+// is dispatched, if the job is WithAtLeastOnce. The caller must not hold
+// c.mutex.
+func (c *CronScheduler) beginRun(job *Job, tick time.Time) {
+	c.mutex.Lock()
+	if !job.atLeastOnce || !job.persisted || c.byID[job.ID] != job {
+		c.mutex.Unlock()
+		return
+	}
+	job.pending = append(job.pending, tick)
+	c.mutex.Unlock()
+	if err := c.saveRecord(job); err != nil {
+		c.reportError(job, fmt.Errorf("saving pending run: %w", err))
+	}
+}
+
+// finishRun clears the run of job scheduled at tick from the store once it
+// has finished, if it was recorded there. The caller must not hold
+// c.mutex.
+func (c *CronScheduler) finishRun(job *Job, tick time.Time) {
+	c.mutex.Lock()
+	i := slices.IndexFunc(job.pending, tick.Equal)
+	if i < 0 {
+		c.mutex.Unlock()
+		return
+	}
+	job.pending = slices.Delete(job.pending, i, i+1)
+	saved := job.persisted && c.byID[job.ID] == job
+	c.mutex.Unlock()
+	if !saved {
+		return
+	}
+	if err := c.saveRecord(job); err != nil {
+		c.reportError(job, fmt.Errorf("saving finished run: %w", err))
+	}
+}
+
+// saveRecord saves the job's current record to the store. Saves are
+// serialized and each takes the record when its turn comes, so the last
+// save holds the latest state. The caller must not hold c.mutex.
+func (c *CronScheduler) saveRecord(job *Job) error {
+	c.recordMutex.Lock()
+	defer c.recordMutex.Unlock()
+	c.mutex.Lock()
+	store, record := c.store, job.record()
+	c.mutex.Unlock()
+	if store == nil {
+		return nil
+	}
+	return store.Save(record)
+}
+
+// reportError passes err about job to the OnError handler, if any. The
+// caller must not hold c.mutex.
+func (c *CronScheduler) reportError(job *Job, err error) {
+	c.mutex.Lock()
+	onError := c.onError
+	c.mutex.Unlock()
+	if onError != nil {
+		onError(job.ID, err)
+	}
+}
+
+// recoverRuns handles the runs of job a previous process left unfinished,
+// scheduled at ticks, as its RecoveryPolicy says. Reruns stop early if the
+// scheduler stops or pauses, the job is removed or a rerun is dropped, as
+// by the job's overlap policy, leaving the rest recorded for the next
+// Start.
+func (c *CronScheduler) recoverRuns(job *Job, ticks []time.Time) {
+	for i, tick := range ticks {
+		if job.recovery == DiscardInterrupted {
+			c.reportError(job, fmt.Errorf("%w: scheduled at %v", ErrRunInterrupted, tick))
+			c.finishRun(job, tick)
+			continue
+		}
+		c.mutex.Lock()
+		if !c.running || c.paused || job.ctx.Err() != nil {
+			job.interrupted = append(ticks[i:], job.interrupted...)
+			c.mutex.Unlock()
+			return
+		}
+		done := make(chan error, 1)
+		ctx := c.ctx
+		job.recovering = tick
+		if c.tryStart(job, done) {
+			c.pool.submit(job.priority, func() { c.execute(ctx, job, tick, done) })
+		}
+		c.mutex.Unlock()
+		err := <-done
+		c.mutex.Lock()
+		job.recovering = time.Time{}
+		if errors.Is(err, ErrJobSkipped) {
+			job.interrupted = append(ticks[i:], job.interrupted...)
+			c.mutex.Unlock()
+			return
+		}
+		c.mutex.Unlock()
+		// A rerun queued by QueueOne or QueueAll ran without its tick.
+		c.finishRun(job, tick)
+	}
+}
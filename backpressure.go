@@ -0,0 +1,64 @@
+package cronjob
+
+import (
+	"log/slog"
+	"time"
+)
+
+// DefaultBackpressureThreshold is how many runs may be queued across all
+// jobs before an EventBackpressure is sent, unless changed with
+// WithBackpressureThreshold.
+const DefaultBackpressureThreshold = 10
+
+// WithBackpressureThreshold sets how many runs may be queued by the
+// QueueOne and QueueAll policies across all jobs before the scheduler sends
+// an EventBackpressure and, with WithLogger, logs a warning, so jobs that
+// keep falling behind their schedule can be detected. The event is sent
+// each time the number of queued runs rises to n. Zero or a negative n
+// disables it.
+func WithBackpressureThreshold(n int) SchedulerOption {
+	return func(c *CronScheduler) {
+		c.backpressureThreshold = n
+	}
+}
+
+// QueueDepth returns the number of runs queued by the QueueOne and QueueAll
+// policies across all jobs, waiting for earlier runs of their job to
+// finish. JobInfo reports the depth of each job's queue.
+func (c *CronScheduler) QueueDepth() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.queuedRuns
+}
+
+// queueRun queues a run of job behind the ones in progress, with done, if
+// not nil, waiting for its result. The caller must hold c.mutex.
+func (c *CronScheduler) queueRun(job *Job, done chan<- error) {
+	var waiters []chan<- error
+	if done != nil {
+		waiters = append(waiters, done)
+	}
+	job.queued = append(job.queued, waiters)
+	c.queuedRuns++
+	if c.backpressureThreshold <= 0 || c.queuedRuns != c.backpressureThreshold {
+		return
+	}
+	c.publish(JobEvent{Type: EventBackpressure, JobID: job.ID, Time: time.Now(), Queued: c.queuedRuns})
+	if c.logger != nil {
+		c.logger.Warn("job queue backpressure",
+			slog.String("job", job.ID),
+			slog.Int("queued", c.queuedRuns))
+	}
+}
+
+// dequeueRuns removes the n oldest runs queued for job and returns their
+// waiters. The caller must hold c.mutex.
+func (c *CronScheduler) dequeueRuns(job *Job, n int) [][]chan<- error {
+	runs := job.queued[:n:n]
+	job.queued = job.queued[n:]
+	if len(job.queued) == 0 {
+		job.queued = nil
+	}
+	c.queuedRuns -= n
+	return runs
+}
@@ -0,0 +1,99 @@
+package cronjob
+
+import "time"
+
+// CircuitBreaker pauses a job that keeps failing, so a broken job stops
+// hammering its dependencies on every fire time.
+type CircuitBreaker struct {
+	// Threshold is the number of consecutive failed runs that opens the
+	// circuit, pausing the job. Values below 1 disable the breaker.
+	Threshold int
+	// Cooldown, if positive, is how long the circuit stays open before the
+	// job is resumed. A resumed job that fails again opens the circuit
+	// again at once. Zero keeps the job paused until ResumeJob.
+	Cooldown time.Duration
+}
+
+// WithCircuitBreaker makes the scheduler pause the job after
+// breaker.Threshold consecutive failed runs, sending an EventCircuitOpen,
+// and resume it after breaker.Cooldown, sending an EventCircuitClosed.
+// Runs that return an error, panic or time out count as failed; retries
+// within a run do not.
+func WithCircuitBreaker(breaker CircuitBreaker) JobOption {
+	return func(j *Job) {
+		j.breaker = breaker
+	}
+}
+
+// checkCircuit opens job's circuit if the run that just ended with err
+// reached its breaker's threshold. The caller must hold c.mutex.
+func (c *CronScheduler) checkCircuit(job *Job, err error) {
+	if err == nil || job.breaker.Threshold <= 0 || job.paused ||
+		job.stats.consecutiveFailures < job.breaker.Threshold {
+		return
+	}
+	c.pauseJob(job)
+	job.circuitOpen = true
+	c.emit(EventCircuitOpen, job, err)
+	if job.breaker.Cooldown <= 0 {
+		return
+	}
+	var cooldown *time.Timer
+	cooldown = time.AfterFunc(job.breaker.Cooldown, func() {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		// The job may have been resumed by hand, its circuit opened again,
+		// disabled or removed meanwhile.
+		if job.cooldown != cooldown || job.disabled || job.ctx.Err() != nil {
+			return
+		}
+		c.resumeJob(job)
+		c.emit(EventCircuitClosed, job, nil)
+	})
+	job.cooldown = cooldown
+}
+
+// stopCooldown stops the timer closing job's open circuit, if any. The
+// caller must hold c.mutex.
+func (j *Job) stopCooldown() {
+	if j.cooldown != nil {
+		j.cooldown.Stop()
+		j.cooldown = nil
+	}
+}
+
+// WithPanicLimit makes the scheduler disable the job once its task has
+// panicked n times within window, so a bug is noticed rather than spamming
+// stack traces forever. A disabled job is paused, sending an EventDisabled
+// with the last panic, and listed in Health's Disabled jobs until ResumeJob
+// enables it again. A zero window counts every panic since the job was
+// added or enabled.
+func WithPanicLimit(n int, window time.Duration) JobOption {
+	return func(j *Job) {
+		j.panicLimit = n
+		j.panicWindow = window
+	}
+}
+
+// checkPanics disables job if the run that just ended with err panicked
+// once too often. The caller must hold c.mutex.
+func (c *CronScheduler) checkPanics(job *Job, err error) {
+	if job.panicLimit <= 0 || outcomeOf(err) != OutcomePanic {
+		return
+	}
+	now := time.Now()
+	job.panics = append(job.panics, now)
+	if job.panicWindow > 0 {
+		i := 0
+		for i < len(job.panics) && now.Sub(job.panics[i]) > job.panicWindow {
+			i++
+		}
+		job.panics = job.panics[i:]
+	}
+	if len(job.panics) < job.panicLimit || job.disabled {
+		return
+	}
+	c.pauseJob(job)
+	job.disabled = true
+	c.emit(EventDisabled, job, err)
+}
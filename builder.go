@@ -0,0 +1,170 @@
+package cronjob
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ScheduleBuilder builds a CronExpression from method calls instead of a
+// string, so a schedule is checked as it is built rather than parsed:
+//
+//	schedule, err := cronjob.NewSchedule().EveryDay().At(9, 30).Build()
+//	schedule, err := cronjob.NewSchedule().OnWeekdays(time.Monday).EveryNMinutes(15).Build()
+//
+// Methods setting the time of day replace what earlier ones set, and
+// methods setting days narrow down the days the schedule fires on. The
+// first invalid value is reported by Build.
+type ScheduleBuilder struct {
+	expr CronExpression
+	err  error
+}
+
+// NewSchedule returns a builder for a schedule firing every minute, on
+// every day.
+func NewSchedule() *ScheduleBuilder {
+	return &ScheduleBuilder{expr: CronExpression{
+		seconds:       newFieldSet([]int{0}),
+		minutes:       newFieldSet(stepValues(0, 59, 1)),
+		hours:         newFieldSet(stepValues(0, 23, 1)),
+		daysOfMonth:   newFieldSet(stepValues(1, 31, 1)),
+		months:        newFieldSet(stepValues(1, 12, 1)),
+		daysOfWeek:    newFieldSet(stepValues(0, 6, 1)),
+		anyDayOfMonth: true,
+		anyDayOfWeek:  true,
+	}}
+}
+
+// EveryNMinutes fires every n minutes of every hour, from minute 0. n must
+// be between 1 and 59.
+func (b *ScheduleBuilder) EveryNMinutes(n int) *ScheduleBuilder {
+	if b.check("minute", n, 1, 59, "invalid step") {
+		b.expr.minutes = newFieldSet(stepValues(0, 59, n))
+		b.expr.hours = newFieldSet(stepValues(0, 23, 1))
+	}
+	return b
+}
+
+// EveryNHours fires on the hour every n hours of the day, from midnight. n
+// must be between 1 and 23.
+func (b *ScheduleBuilder) EveryNHours(n int) *ScheduleBuilder {
+	if b.check("hour", n, 1, 23, "invalid step") {
+		b.expr.minutes = newFieldSet([]int{0})
+		b.expr.hours = newFieldSet(stepValues(0, 23, n))
+	}
+	return b
+}
+
+// EveryDay fires once a day, at midnight unless At sets another time. It
+// clears the days set by OnWeekdays and OnDaysOfMonth.
+func (b *ScheduleBuilder) EveryDay() *ScheduleBuilder {
+	b.expr.minutes = newFieldSet([]int{0})
+	b.expr.hours = newFieldSet([]int{0})
+	b.expr.daysOfMonth, b.expr.anyDayOfMonth = newFieldSet(stepValues(1, 31, 1)), true
+	b.expr.daysOfWeek, b.expr.anyDayOfWeek = newFieldSet(stepValues(0, 6, 1)), true
+	return b
+}
+
+// At fires at hour:minute, once on each day the schedule fires on.
+func (b *ScheduleBuilder) At(hour, minute int) *ScheduleBuilder {
+	if b.check("hour", hour, 0, 23, "") && b.check("minute", minute, 0, 59, "") {
+		b.expr.hours = newFieldSet([]int{hour})
+		b.expr.minutes = newFieldSet([]int{minute})
+	}
+	return b
+}
+
+// OnWeekdays only fires on the given days of the week.
+func (b *ScheduleBuilder) OnWeekdays(days ...time.Weekday) *ScheduleBuilder {
+	values := make([]int, 0, len(days))
+	for _, day := range days {
+		if !b.check("day-of-week", int(day), 0, 6, "") {
+			return b
+		}
+		values = append(values, int(day))
+	}
+	if b.require("day-of-week", values) {
+		b.expr.daysOfWeek, b.expr.anyDayOfWeek = newFieldSet(values), false
+	}
+	return b
+}
+
+// OnDaysOfMonth only fires on the given days of the month, from 1 to 31.
+// Months without a day are skipped for it.
+func (b *ScheduleBuilder) OnDaysOfMonth(days ...int) *ScheduleBuilder {
+	for _, day := range days {
+		if !b.check("day-of-month", day, 1, 31, "") {
+			return b
+		}
+	}
+	if b.require("day-of-month", days) {
+		b.expr.daysOfMonth, b.expr.anyDayOfMonth = newFieldSet(days), false
+	}
+	return b
+}
+
+// InMonths only fires in the given months.
+func (b *ScheduleBuilder) InMonths(months ...time.Month) *ScheduleBuilder {
+	values := make([]int, 0, len(months))
+	for _, month := range months {
+		if !b.check("month", int(month), 1, 12, "") {
+			return b
+		}
+		values = append(values, int(month))
+	}
+	if b.require("month", values) {
+		b.expr.months = newFieldSet(values)
+	}
+	return b
+}
+
+// Build returns the schedule, or the first error of the calls building it,
+// a *FieldError naming the field the bad value was for.
+func (b *ScheduleBuilder) Build() (*CronExpression, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	expr := b.expr
+	return &expr, nil
+}
+
+// check records an error for field unless value is within min-max, and
+// reports whether it is. A non-empty problem replaces the range error,
+// for values that are not themselves field values, such as steps.
+func (b *ScheduleBuilder) check(field string, value, min, max int, problem string) bool {
+	if b.err != nil {
+		return false
+	}
+	if value >= min && value <= max {
+		return true
+	}
+	err := fmt.Errorf("value %d out of range %d-%d", value, min, max)
+	if problem != "" {
+		err = fmt.Errorf("%s: %d", problem, value)
+	}
+	b.err = &FieldError{Field: field, Value: strconv.Itoa(value), Err: err}
+	return false
+}
+
+// require records an error for field if values is empty, and reports
+// whether it is not.
+func (b *ScheduleBuilder) require(field string, values []int) bool {
+	if b.err != nil {
+		return false
+	}
+	if len(values) == 0 {
+		b.err = &FieldError{Field: field, Err: errors.New("no values given")}
+		return false
+	}
+	return true
+}
+
+// stepValues returns every step-th value from min to max.
+func stepValues(min, max, step int) []int {
+	var values []int
+	for i := min; i <= max; i += step {
+		values = append(values, i)
+	}
+	return values
+}
@@ -0,0 +1,162 @@
+package cronjob
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// BusinessDayAdjustment controls what a BusinessDaySchedule does with fire
+// times falling on weekends or holidays.
+type BusinessDayAdjustment int
+
+const (
+	// BusinessDaySkip drops fire times on days that are not business days.
+	// This is the default.
+	BusinessDaySkip BusinessDayAdjustment = iota
+	// BusinessDayFollowing moves them to the next business day, at the
+	// same time of day, as the "following" convention of financial
+	// calendars does.
+	BusinessDayFollowing
+	// BusinessDayPreceding moves them to the previous business day, at the
+	// same time of day.
+	BusinessDayPreceding
+)
+
+// maxBusinessDayShift bounds how many days a fire time moves looking for a
+// business day, after which the schedule is treated as never firing again.
+const maxBusinessDayShift = 31
+
+// BusinessDaySchedule wraps a Schedule so it only fires on business days:
+// days that are not in Weekend and that Holidays does not exclude. A day is
+// a holiday if Holidays excludes its midnight, as DateCalendar does for
+// whole days. Fire times on other days are adjusted by Adjustment. When
+// several fire times move to the same time of the same business day, the
+// job runs once.
+type BusinessDaySchedule struct {
+	Schedule Schedule
+	// Holidays, if set, marks the days besides the weekend that are not
+	// business days.
+	Holidays   Calendar
+	Adjustment BusinessDayAdjustment
+	// Weekend lists the days of the week that are never business days. If
+	// it is nil, they are Saturday and Sunday.
+	Weekend []time.Weekday
+}
+
+var _ Schedule = (*BusinessDaySchedule)(nil)
+
+// BusinessDays returns a schedule firing on the business days of schedule,
+// with the days holidays excludes, which may be nil, and the weekend off.
+// Fire times on those days are adjusted by adjustment:
+//
+//	// Month-end batch on the last business day of the month.
+//	schedule, _ := cronjob.ParseCronExpression("0 18 L * *")
+//	holidays := cronjob.NewDateCalendar(newYear, christmas)
+//	scheduler.AddScheduledJob(cronjob.BusinessDays(schedule, holidays, cronjob.BusinessDayPreceding), closeBooks)
+func BusinessDays(schedule Schedule, holidays Calendar, adjustment BusinessDayAdjustment) *BusinessDaySchedule {
+	return &BusinessDaySchedule{Schedule: schedule, Holidays: holidays, Adjustment: adjustment}
+}
+
+// Next returns the first adjusted fire time after from, in from's
+// location, or the zero time if there is none.
+func (s *BusinessDaySchedule) Next(from time.Time) time.Time {
+	day, ok := s.businessDay(midnight(from), 1)
+	for i := 0; ok && i < maxExcludedRuns; i++ {
+		// Find the earliest of the fire times that move to day and come
+		// after from.
+		start, end := s.feeding(day)
+		var best time.Time
+		it := NewIterator(s.Schedule, start.Add(-time.Nanosecond))
+		t := it.Next()
+		for ; !t.IsZero() && t.Before(end); t = it.Next() {
+			y, m, d := day.Date()
+			hour, minute, second := t.In(day.Location()).Clock()
+			moved := time.Date(y, m, d, hour, minute, second, t.Nanosecond(), day.Location())
+			if moved.After(from) && (best.IsZero() || moved.Before(best)) {
+				best = moved
+			}
+		}
+		if !best.IsZero() || t.IsZero() {
+			return best
+		}
+		// Go straight to the business day the next fire time moves to.
+		direction := 1
+		if s.Adjustment == BusinessDayPreceding {
+			direction = -1
+		}
+		day, ok = s.businessDay(midnight(t.In(from.Location())), direction)
+	}
+	return time.Time{}
+}
+
+// feeding returns the span of fire times that run on the business day
+// starting at day: the day itself, and the days before or after it that
+// are not business days if they move to it.
+func (s *BusinessDaySchedule) feeding(day time.Time) (start, end time.Time) {
+	start, end = day, addDays(day, 1)
+	switch s.Adjustment {
+	case BusinessDayFollowing:
+		for i := 0; i < maxBusinessDayShift && !s.isBusinessDay(addDays(start, -1)); i++ {
+			start = addDays(start, -1)
+		}
+	case BusinessDayPreceding:
+		for i := 0; i < maxBusinessDayShift && !s.isBusinessDay(end); i++ {
+			end = addDays(end, 1)
+		}
+	}
+	return start, end
+}
+
+// businessDay returns the first business day from day on, stepping by
+// direction days, and false if there is none within maxBusinessDayShift.
+func (s *BusinessDaySchedule) businessDay(day time.Time, direction int) (time.Time, bool) {
+	for i := 0; i <= maxBusinessDayShift; i++ {
+		if s.isBusinessDay(day) {
+			return day, true
+		}
+		day = addDays(day, direction)
+	}
+	return time.Time{}, false
+}
+
+// isBusinessDay reports whether the day starting at day is a business day.
+func (s *BusinessDaySchedule) isBusinessDay(day time.Time) bool {
+	weekend := s.Weekend
+	if weekend == nil {
+		weekend = []time.Weekday{time.Saturday, time.Sunday}
+	}
+	if slices.Contains(weekend, day.Weekday()) {
+		return false
+	}
+	return s.Holidays == nil || !s.Holidays.Excludes(day)
+}
+
+// midnight returns the start of t's day, in t's location.
+func midnight(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// addDays returns the midnight n days after the one starting at day.
+func addDays(day time.Time, n int) time.Time {
+	y, m, d := day.Date()
+	return time.Date(y, m, d+n, 0, 0, 0, 0, day.Location())
+}
+
+// String describes the schedule, e.g. "0 9 * * * on business days
+// (following)".
+func (s *BusinessDaySchedule) String() string {
+	description := "schedule"
+	if stringer, ok := s.Schedule.(fmt.Stringer); ok {
+		description = stringer.String()
+	}
+	description += " on business days"
+	switch s.Adjustment {
+	case BusinessDayFollowing:
+		description += " (following)"
+	case BusinessDayPreceding:
+		description += " (preceding)"
+	}
+	return description
+}
@@ -0,0 +1,181 @@
+package cronjob
+
+import "time"
+
+// maxExcludedRuns caps how many consecutive excluded fire times are skipped
+// looking for the next allowed one, after which the job is treated as never
+// firing again.
+const maxExcludedRuns = 100000
+
+// Calendar marks blackout periods, such as holidays or maintenance windows,
+// in which jobs must not fire. Fire times in a blackout of a custom Calendar
+// are skipped one at a time, at most 100000 in a row; DateCalendar and
+// WeeklyCalendar skip whole blackouts at once.
+type Calendar interface {
+	// Excludes reports whether a run due at t must be skipped. t is in the
+	// job's location.
+	Excludes(t time.Time) bool
+}
+
+// WithExcludedCalendar skips the job's fire times that cal excludes. The
+// option can be repeated; a fire time excluded by any calendar is skipped,
+// and an @reboot job does not run if the scheduler starts in a blackout.
+// Manual runs are not affected.
+func WithExcludedCalendar(cal Calendar) JobOption {
+	return func(j *Job) {
+		j.calendars = append(j.calendars, cal)
+	}
+}
+
+// blackoutCalendar is a Calendar that knows where its blackouts end.
+type blackoutCalendar interface {
+	Calendar
+	// blackoutEnd returns the end of the blackout containing t, or the
+	// zero time if the calendar excludes every time after t.
+	blackoutEnd(t time.Time) time.Time
+}
+
+// excluded reports whether any of the job's calendars excludes t.
+func (j *Job) excluded(t time.Time) bool {
+	for _, cal := range j.calendars {
+		if cal.Excludes(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipExcluded returns the first time from t on, which is excluded, that
+// may be allowed: the end of the longest blackout containing t among the
+// calendars that know it, or t itself. The zero time means the job will
+// never fire again.
+func (j *Job) skipExcluded(t time.Time) time.Time {
+	end := t
+	for _, cal := range j.calendars {
+		if bc, ok := cal.(blackoutCalendar); ok && bc.Excludes(t) {
+			blackoutEnd := bc.blackoutEnd(t)
+			if blackoutEnd.IsZero() {
+				return time.Time{}
+			}
+			if blackoutEnd.After(end) {
+				end = blackoutEnd
+			}
+		}
+	}
+	return end
+}
+
+// DateCalendar excludes whole calendar days, such as public holidays.
+type DateCalendar struct {
+	dates map[civilDate]struct{}
+}
+
+var _ Calendar = (*DateCalendar)(nil)
+
+type civilDate struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// NewDateCalendar returns a calendar excluding the calendar day of each of
+// dates. Days are compared by year, month and day in the time's own
+// location, so a date excludes that day in every job's location.
+func NewDateCalendar(dates ...time.Time) *DateCalendar {
+	cal := &DateCalendar{dates: make(map[civilDate]struct{}, len(dates))}
+	for _, date := range dates {
+		cal.Add(date)
+	}
+	return cal
+}
+
+// Add excludes the calendar day of date. It must not be called while the
+// calendar is in use by a running scheduler.
+func (c *DateCalendar) Add(date time.Time) {
+	y, m, d := date.Date()
+	c.dates[civilDate{y, m, d}] = struct{}{}
+}
+
+// Excludes reports whether t falls on one of the calendar's days.
+func (c *DateCalendar) Excludes(t time.Time) bool {
+	y, m, d := t.Date()
+	_, ok := c.dates[civilDate{y, m, d}]
+	return ok
+}
+
+// blackoutEnd returns the midnight ending the run of excluded days that
+// contains t.
+func (c *DateCalendar) blackoutEnd(t time.Time) time.Time {
+	y, m, d := t.Date()
+	for i := 0; i <= len(c.dates); i++ {
+		d++
+		next := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+		if !c.Excludes(next) {
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+// WeeklyWindow is a period repeating every week: it starts Start after
+// midnight on Day and ends End after that midnight. End may be more than
+// 24 hours, for windows spanning several days.
+type WeeklyWindow struct {
+	Day        time.Weekday
+	Start, End time.Duration
+}
+
+// WeeklyCalendar excludes recurring weekly windows, such as a maintenance
+// window from Saturday 22:00 to Sunday 02:00:
+//
+//	cronjob.WeeklyCalendar{{Day: time.Saturday, Start: 22 * time.Hour, End: 26 * time.Hour}}
+type WeeklyCalendar []WeeklyWindow
+
+var _ Calendar = WeeklyCalendar(nil)
+
+const week = 7 * 24 * time.Hour
+
+// Excludes reports whether t falls in any of the windows, from their start
+// inclusive to their end exclusive.
+func (c WeeklyCalendar) Excludes(t time.Time) bool {
+	return c.remaining(t) > 0
+}
+
+// remaining returns how long the longest window containing t lasts after t,
+// or zero if no window contains it.
+func (c WeeklyCalendar) remaining(t time.Time) time.Duration {
+	hour, minute, second := t.Clock()
+	offset := time.Duration(t.Weekday())*24*time.Hour +
+		time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute +
+		time.Duration(second)*time.Second + time.Duration(t.Nanosecond())
+	var remaining time.Duration
+	for _, w := range c {
+		start := time.Duration(w.Day)*24*time.Hour + w.Start
+		end := time.Duration(w.Day)*24*time.Hour + w.End
+		// A window running past Saturday midnight continues into the
+		// start of the week.
+		for _, at := range []time.Duration{offset, offset + week} {
+			if at >= start && at < end && end-at > remaining {
+				remaining = end - at
+			}
+		}
+	}
+	return remaining
+}
+
+// blackoutEnd returns the end of the chain of overlapping windows that
+// contains t.
+func (c WeeklyCalendar) blackoutEnd(t time.Time) time.Time {
+	end := t
+	for i := 0; i <= len(c); i++ {
+		remaining := c.remaining(end)
+		if remaining == 0 {
+			return end
+		}
+		end = end.Add(remaining)
+		if end.Sub(t) >= week {
+			return time.Time{}
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,32 @@
+package cronjob
+
+import "time"
+
+// RunResult describes a finished run of a job, as passed to the callback of
+// WithOnComplete.
+type RunResult struct {
+	JobID string
+	// Scheduled is the fire time the run was for, or zero for a manual run
+	// or one queued by the QueueOne or QueueAll policy.
+	Scheduled time.Time
+	// Start is when the run actually started, which is later than
+	// Scheduled if it waited for a worker, a slot or its jitter.
+	Start    time.Time
+	Duration time.Duration
+	Outcome  RunOutcome
+	// Err is the run's error, or nil if it succeeded.
+	Err error
+	// Attempt is the number of the run's last attempt, which is above 1 if
+	// it was retried.
+	Attempt int
+}
+
+// WithOnComplete calls fn after every finished run of the job, scheduled or
+// manual, with the run's result, for feeding external job-monitoring
+// services. fn is called on the run's goroutine, after the run's events
+// are sent, and should not block.
+func WithOnComplete(fn func(RunResult)) JobOption {
+	return func(j *Job) {
+		j.onComplete = fn
+	}
+}
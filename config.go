@@ -0,0 +1,278 @@
+package cronjob
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is a declarative list of jobs, read from a file by LoadConfig and
+// applied to a scheduler with ApplyConfig or WatchConfig.
+//
+// In YAML:
+//
+//	jobs:
+//	  - name: daily-report
+//	    cron: "0 6 * * *"
+//	    timeout: 5m
+//	    retries: 2
+//	    retry_delay: 30s
+//	    overlap: skip
+//	  - name: cleanup
+//	    task: purge
+//	    cron: "@hourly"
+//	    enabled: false
+type Config struct {
+	Jobs []JobConfig `json:"jobs" yaml:"jobs"`
+}
+
+// JobConfig describes a job of a Config.
+type JobConfig struct {
+	// Name is the job's ID.
+	Name string `json:"name" yaml:"name"`
+	// Task is the name the job's task was registered under with
+	// RegisterTask. It defaults to Name.
+	Task string `json:"task,omitempty" yaml:"task,omitempty"`
+	// Cron is the job's cron expression.
+	Cron string `json:"cron" yaml:"cron"`
+	// Timeout limits each attempt, as a duration such as "30s". Empty means
+	// no timeout.
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// Retries is the number of retries of a failed attempt, RetryDelay
+	// apart.
+	Retries    int    `json:"retries,omitempty" yaml:"retries,omitempty"`
+	RetryDelay string `json:"retry_delay,omitempty" yaml:"retry_delay,omitempty"`
+	// Overlap is the job's OverlapPolicy: "allow" (the default), "skip",
+	// "queue" or "queue_all".
+	Overlap string `json:"overlap,omitempty" yaml:"overlap,omitempty"`
+	// Enabled defaults to true. A disabled job is not scheduled.
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+}
+
+// configuredJob is the resolved form of a JobConfig, compared between
+// reloads to find the jobs that changed.
+type configuredJob struct {
+	task       string
+	cron       string
+	timeout    time.Duration
+	retries    int
+	retryDelay time.Duration
+	overlap    OverlapPolicy
+}
+
+// LoadConfig reads a Config from a YAML file, or from a JSON file if the
+// path ends in .json.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseConfig(path, data)
+}
+
+// parseConfig decodes the contents of the config file at path.
+func parseConfig(path string, data []byte) (*Config, error) {
+	config, err := decodeConfig(data, strings.EqualFold(filepath.Ext(path), ".json"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return config, nil
+}
+
+func decodeConfig(data []byte, isJSON bool) (*Config, error) {
+	var config Config
+	if isJSON {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&config); err != nil {
+			return nil, err
+		}
+		return &config, nil
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// resolve checks the job's fields against tasks, the registered task
+// functions, and its cron expression with validate.
+func (jc JobConfig) resolve(tasks TaskRegistry, validate func(expr string) error) (configuredJob, error) {
+	job := configuredJob{task: jc.Task, cron: jc.Cron, retries: jc.Retries}
+	if jc.Name == "" {
+		return job, errors.New("job name must not be empty")
+	}
+	if job.task == "" {
+		job.task = jc.Name
+	}
+	if _, ok := tasks[job.task]; !ok {
+		return job, fmt.Errorf("job %s: %w: %s", jc.Name, ErrTaskNotFound, job.task)
+	}
+	if err := validate(jc.Cron); err != nil {
+		return job, fmt.Errorf("job %s: %w", jc.Name, err)
+	}
+	var err error
+	if jc.Timeout != "" {
+		if job.timeout, err = time.ParseDuration(jc.Timeout); err != nil {
+			return job, fmt.Errorf("job %s: invalid timeout: %w", jc.Name, err)
+		}
+	}
+	if jc.RetryDelay != "" {
+		if job.retryDelay, err = time.ParseDuration(jc.RetryDelay); err != nil {
+			return job, fmt.Errorf("job %s: invalid retry delay: %w", jc.Name, err)
+		}
+	}
+	if jc.Retries < 0 {
+		return job, fmt.Errorf("job %s: retries must not be negative", jc.Name)
+	}
+	switch jc.Overlap {
+	case "", "allow":
+		job.overlap = AllowConcurrent
+	case "skip":
+		job.overlap = SkipIfRunning
+	case "queue":
+		job.overlap = QueueOne
+	case "queue_all":
+		job.overlap = QueueAll
+	default:
+		return job, fmt.Errorf("job %s: unknown overlap policy: %s", jc.Name, jc.Overlap)
+	}
+	return job, nil
+}
+
+// options returns the job options of the configured job, after the ones
+// its task was registered with.
+func (j configuredJob) options(registered []JobOption) []JobOption {
+	opts := append([]JobOption{withTask(j.task)}, registered...)
+	if j.timeout > 0 {
+		opts = append(opts, WithTimeout(j.timeout))
+	}
+	if j.retries > 0 {
+		opts = append(opts, WithRetry(RetryPolicy{MaxAttempts: j.retries + 1, Delay: j.retryDelay}))
+	}
+	return append(opts, WithOverlapPolicy(j.overlap))
+}
+
+// ApplyConfig makes the scheduler's configured jobs match config: jobs new
+// to it are added, changed ones are replaced, and ones removed from it or
+// disabled are removed. Jobs added in code are left alone, and a configured
+// job with the ID of one fails with ErrDuplicateJobID. Every job's task must
+// be registered with RegisterTask. If any job of config is invalid, nothing
+// is changed.
+func (c *CronScheduler) ApplyConfig(config *Config) error {
+	c.mutex.Lock()
+	tasks, taskOptions := c.tasks, c.taskOptions
+	c.mutex.Unlock()
+
+	wanted := make(map[string]configuredJob)
+	var errs []error
+	for _, jc := range config.Jobs {
+		job, err := jc.resolve(tasks, c.Validate)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, ok := wanted[jc.Name]; ok {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrDuplicateJobID, jc.Name))
+			continue
+		}
+		if jc.Enabled == nil || *jc.Enabled {
+			wanted[jc.Name] = job
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	c.configMutex.Lock()
+	defer c.configMutex.Unlock()
+	for id := range c.configJobs {
+		if _, ok := wanted[id]; ok {
+			continue
+		}
+		if err := c.RemoveJob(id); err != nil && !errors.Is(err, ErrJobNotFound) {
+			errs = append(errs, err)
+		}
+		delete(c.configJobs, id)
+	}
+	for _, jc := range config.Jobs {
+		job, ok := wanted[jc.Name]
+		current, exists := c.configJobs[jc.Name]
+		if !ok || exists && current == job {
+			continue
+		}
+		// Changed jobs are replaced in place, so they are never missing.
+		if _, err := c.addNamedJob(jc.Name, job.cron, tasks[job.task], job.options(taskOptions[job.task]), exists, nil); err != nil {
+			errs = append(errs, fmt.Errorf("job %s: %w", jc.Name, err))
+			continue
+		}
+		if c.configJobs == nil {
+			c.configJobs = make(map[string]configuredJob)
+		}
+		c.configJobs[jc.Name] = job
+	}
+	return errors.Join(errs...)
+}
+
+// WatchConfig loads the config file at path and applies it, then checks the
+// file every interval and applies it again whenever it changes, until ctx
+// is done. The first load's error is returned; errors of later reloads are
+// reported to the OnError handler, and leave the jobs as they were. Replace
+// the file atomically, by renaming a new file over it, so a reload never
+// sees it half written.
+func (c *CronScheduler) WatchConfig(ctx context.Context, path string, interval time.Duration) error {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		err = c.applyConfigData(path, data)
+	}
+	if err != nil {
+		return err
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			next, err := os.ReadFile(path)
+			if err == nil && bytes.Equal(next, data) {
+				continue
+			}
+			if err == nil {
+				data = next
+				err = c.applyConfigData(path, data)
+			}
+			if err != nil {
+				c.mutex.Lock()
+				onError := c.onError
+				c.mutex.Unlock()
+				if onError != nil {
+					onError("", fmt.Errorf("reloading config: %w", err))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *CronScheduler) applyConfigData(path string, data []byte) error {
+	config, err := parseConfig(path, data)
+	if err != nil {
+		return err
+	}
+	return c.ApplyConfig(config)
+}
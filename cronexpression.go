@@ -2,9 +2,15 @@
 package cronjob
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -12,11 +18,93 @@ import (
 
 // CronExpression represents a cron expression.
 type CronExpression struct {
-	Minutes    []int
-	Hours      []int
-	DayOfMonth []int
-	Month      []int
-	DayOfWeek  []int
+	// The allowed values of each field, read with the accessors of the same
+	// names. years is nil if the expression has no year field or it is "*".
+	seconds     fieldSet
+	minutes     fieldSet
+	hours       fieldSet
+	daysOfMonth fieldSet
+	months      fieldSet
+	daysOfWeek  fieldSet
+	years       []int
+
+	// DayMatching selects how DayOfMonth and DayOfWeek combine when both
+	// are restricted.
+	DayMatching DayMatching
+	// DSTPolicy selects what happens to fire times in an hour skipped by a
+	// DST change.
+	DSTPolicy DSTPolicy
+	// Location, if set, is the time zone the expression is evaluated in,
+	// whatever the location of the times passed to Next or of the job. It
+	// is set by a "CRON_TZ=" or "TZ=" prefix.
+	Location *time.Location
+
+	// anyDayOfMonth and anyDayOfWeek are set when the field is "*" or "?".
+	anyDayOfMonth bool
+	anyDayOfWeek  bool
+	// lastDaysOfMonth holds the offsets of "L" (0) and "L-n" (n) in the
+	// day-of-month field, and lastWeekdays the weekdays of "nL" in the
+	// day-of-week field.
+	lastDaysOfMonth []int
+	lastWeekdays    []int
+	// nearestWeekdays holds the days of "nW" in the day-of-month field,
+	// and lastWeekdayOfMonth is set by "LW".
+	nearestWeekdays    []int
+	lastWeekdayOfMonth bool
+	// nthWeekdays holds the "weekday#n" parts of the day-of-week field.
+	nthWeekdays []nthWeekday
+
+	// reboot is set for "@reboot", which never matches a time and instead
+	// fires once when the scheduler starts.
+	reboot bool
+	// interval is set for "@every <duration>" expressions, which fire at a
+	// fixed interval instead of matching calendar fields.
+	interval time.Duration
+	// hashed is set when fields used "H", so their values depend on the
+	// key the expression was parsed with.
+	hashed bool
+}
+
+// Seconds returns the allowed seconds, in increasing order. It is [0] for
+// five-field expressions.
+func (expr *CronExpression) Seconds() []int { return expr.seconds.values() }
+
+// Minutes returns the allowed minutes, in increasing order.
+func (expr *CronExpression) Minutes() []int { return expr.minutes.values() }
+
+// Hours returns the allowed hours, in increasing order.
+func (expr *CronExpression) Hours() []int { return expr.hours.values() }
+
+// DayOfMonth returns the days of the month the day-of-month field lists, in
+// increasing order, not counting "L", "W" and the like.
+func (expr *CronExpression) DayOfMonth() []int { return expr.daysOfMonth.values() }
+
+// Month returns the allowed months, in increasing order.
+func (expr *CronExpression) Month() []int { return expr.months.values() }
+
+// DayOfWeek returns the weekdays the day-of-week field lists, in increasing
+// order from 0 for Sunday, not counting "L" and "#".
+func (expr *CronExpression) DayOfWeek() []int { return expr.daysOfWeek.values() }
+
+// Years returns the allowed years, in increasing order, or nil if the
+// expression has no year field or it is "*".
+func (expr *CronExpression) Years() []int { return slices.Clone(expr.years) }
+
+// nthWeekday is the nth occurrence of a weekday in a month, as in "Mon#2".
+type nthWeekday struct {
+	weekday int
+	n       int
+}
+
+// macros maps the predefined schedule macros to their cron equivalents.
+var macros = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
 }
 
 var monthNameToNumber = map[string]int{
@@ -44,156 +132,882 @@ var dayNameToNumber = map[string]int{
 	"Sat": 6,
 }
 
+// quartzDayNameToNumber numbers the days of the week as Quartz does.
+var quartzDayNameToNumber = map[string]int{
+	"Sun": 1,
+	"Mon": 2,
+	"Tue": 3,
+	"Wed": 4,
+	"Thu": 5,
+	"Fri": 6,
+	"Sat": 7,
+}
+
+// DayMatching controls how the day-of-month and day-of-week fields combine
+// when neither is "*" or "?".
+type DayMatching int
+
+const (
+	// DayOr fires on days matching either field, as standard cron does, so
+	// "0 0 1,15 * Mon" fires on the 1st, the 15th and every Monday. This is
+	// the default.
+	DayOr DayMatching = iota
+	// DayAnd fires only on days matching both fields, so "0 0 1-7 * Mon"
+	// fires on the first Monday of the month.
+	DayAnd
+)
+
+// ParseMode controls which cron expression layouts the parser accepts.
+type ParseMode int
+
+const (
+	// ParseAuto accepts both standard 5-field expressions, with seconds
+	// defaulting to 0, and 6-field expressions with a leading seconds field,
+	// optionally followed by a year field.
+	ParseAuto ParseMode = iota
+	// ParseStandard accepts only standard 5-field crontab expressions.
+	ParseStandard
+	// ParseWithSeconds accepts only 6-field expressions with a leading
+	// seconds field, optionally followed by a year field.
+	ParseWithSeconds
+)
+
+// Strictness controls whether the parser rejects expressions that are
+// valid but probably not what their author meant.
+type Strictness int
+
+const (
+	// Lenient accepts every expression the parser can make sense of. This
+	// is the default.
+	Lenient Strictness = iota
+	// Strict also rejects ranges whose start is after their end, such as
+	// "10-5" or "Fri-Mon", which Lenient wraps around the end of the field;
+	// steps larger than their range, such as "*/90" in the minute field,
+	// which leave only the range's start; and ranges mixing names and
+	// numbers, such as "Mon-5". Sunday may still end a day-of-week range,
+	// as in "Fri-Sun".
+	Strict
+)
+
+// WeekdayNumbering controls how the day-of-week field numbers the days.
+// Day names such as "Mon" read the same in every numbering, and String
+// always writes the standard one.
+type WeekdayNumbering int
+
+const (
+	// CronWeekdays numbers the days as standard cron does: 0 is Sunday, 1
+	// Monday and so on to 6 for Saturday, and 7 is Sunday again, so "5-7"
+	// runs Friday to Sunday. This is the default.
+	CronWeekdays WeekdayNumbering = iota
+	// QuartzWeekdays numbers the days as Quartz does, from 1 for Sunday to
+	// 7 for Saturday, so "2-6" runs Monday to Friday and "6L" is the last
+	// Friday of the month, for expressions carried over from Java
+	// schedulers.
+	QuartzWeekdays
+)
+
+// ParseOption configures how ParseCronExpression and Validate read an
+// expression.
+type ParseOption func(*parseOptions)
+
+type parseOptions struct {
+	mode ParseMode
+	// key is hashed to pick the values of "H" fields.
+	key        string
+	strictness Strictness
+	weekdays   WeekdayNumbering
+}
+
+// WithStrictness sets how strictly the parser reads fields, Lenient by
+// default.
+func WithStrictness(strictness Strictness) ParseOption {
+	return func(o *parseOptions) {
+		o.strictness = strictness
+	}
+}
+
+// WithWeekdayNumbering sets how the day-of-week field numbers the days,
+// CronWeekdays by default.
+func WithWeekdayNumbering(numbering WeekdayNumbering) ParseOption {
+	return func(o *parseOptions) {
+		o.weekdays = numbering
+	}
+}
+
+// WithSeconds makes the parser expect a leading seconds field: 6 fields,
+// optionally followed by a year field. Without it, expressions have the 5
+// fields of standard crontab.
+func WithSeconds() ParseOption {
+	return func(o *parseOptions) {
+		o.mode = ParseWithSeconds
+	}
+}
+
+// WithHashKey resolves the expression's "H" fields from key, as Jenkins
+// does: "H H(0-7) * * *" runs daily at a minute and hour between midnight
+// and 07:59 picked by hashing key, and "H/15 * * * *" every quarter hour
+// from a hashed offset. Scheduled jobs use their ID as the key, so jobs
+// sharing an expression spread over the hour or day without manual
+// staggering but each keep a stable time. "H" picks days of the month from
+// 1-28 so they exist in every month, and is not allowed in the year field.
+// Without a key, "H" fields hash the empty string.
+func WithHashKey(key string) ParseOption {
+	return func(o *parseOptions) {
+		o.key = key
+	}
+}
+
 // ParseCronExpression parses a cron expression and returns a CronExpression object.
-func ParseCronExpression(expr string) (*CronExpression, error) {
+// By default it takes the 5 fields of standard crontab, minute first; with
+// WithSeconds it takes 6 fields with a leading seconds field, optionally
+// followed by a year field (1970-2099). An expression with the wrong number
+// of fields for the mode is rejected rather than guessed at, so a crontab
+// line is never read with its minute as seconds. Also accepted are "?" for
+// "no specific value" in the day-of-month and day-of-week fields, and the
+// macros @yearly, @annually, @monthly, @weekly, @daily, @midnight, @hourly,
+// @reboot and "@every <duration>", where the duration is parsed with
+// time.ParseDuration (e.g. "@every 5m30s").
+func ParseCronExpression(expr string, opts ...ParseOption) (*CronExpression, error) {
+	o := parseOptions{mode: ParseStandard}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return parseExpression(expr, o)
+}
+
+// ParseCronExpressionMode parses a cron expression using the field layout
+// selected by mode. Macros are accepted in every mode.
+func ParseCronExpressionMode(expr string, mode ParseMode) (*CronExpression, error) {
+	return parseExpression(expr, parseOptions{mode: mode})
+}
+
+func parseExpression(expr string, o parseOptions) (*CronExpression, error) {
+	if prefix, rest, ok := cutTimeZone(expr); ok {
+		tz, spec := rest, ""
+		if i := strings.IndexAny(rest, " \t"); i >= 0 {
+			tz, spec = rest[:i], rest[i:]
+		}
+		loc, err := time.LoadLocation(tz)
+		if err != nil || tz == "" {
+			return nil, fmt.Errorf("invalid cron expression: %s: unknown time zone in %s", expr, prefix)
+		}
+		cronExpr, err := parseExpression(spec, o)
+		if err != nil {
+			var fieldErr *FieldError
+			if errors.As(err, &fieldErr) && fieldErr.Position > 0 {
+				fieldErr.Position += utf8.RuneCountInString(expr[:len(expr)-len(spec)])
+			}
+			return nil, err
+		}
+		cronExpr.Location = loc
+		return cronExpr, nil
+	}
+	if macro := strings.ToLower(strings.TrimSpace(expr)); strings.HasPrefix(macro, "@") {
+		if macro == "@reboot" {
+			return &CronExpression{reboot: true}, nil
+		}
+		if strings.HasPrefix(macro, "@every ") {
+			return parseEvery(strings.TrimSpace(strings.TrimPrefix(macro, "@every ")))
+		}
+		if equivalent, ok := macros[macro]; ok {
+			return ParseCronExpressionMode(equivalent, ParseWithSeconds)
+		}
+		return nil, fmt.Errorf("unknown cron macro: %s", expr)
+	}
+
 	fields := strings.Fields(expr)
-	if len(fields) != 5 {
-		return nil, fmt.Errorf("invalid cron expression: %s", expr)
+	positions := fieldPositions(expr)
+	first := 0
+	switch {
+	case len(fields) == 5 && o.mode != ParseWithSeconds:
+		fields = append([]string{"0"}, fields...)
+		positions = append([]int{0}, positions...)
+		first = 1
+	case (len(fields) == 6 || len(fields) == 7) && o.mode != ParseStandard:
+	case len(fields) == 6 || len(fields) == 7:
+		return nil, fmt.Errorf("invalid cron expression: %s: expected 5 fields, got %d: a leading seconds field must be enabled with WithSeconds", expr, len(fields))
+	default:
+		return nil, fmt.Errorf("invalid cron expression: %s: expected %s fields, got %d", expr, fieldCounts[o.mode], len(fields))
+	}
+
+	// Errors point at the token as written, before "H" tokens expand into
+	// lists of values; origins maps the tokens of a field expanded that way
+	// back to the tokens they came from.
+	original := slices.Clone(fields)
+	origins := make([][]int, len(fields))
+	fieldError := func(i int, err error) error {
+		e := &FieldError{Field: fieldNames[i], Value: original[i], Index: i + 1 - first, Position: positions[i], Err: err}
+		var tokenErr *tokenError
+		if !errors.As(err, &tokenErr) {
+			return e
+		}
+		e.Err = tokenErr.err
+		index := tokenErr.index
+		if origins[i] != nil {
+			index = origins[i][index]
+		}
+		tokens := strings.Split(original[i], ",")
+		e.Token = tokens[index]
+		if e.Position > 0 && index > 0 {
+			e.Position += utf8.RuneCountInString(strings.Join(tokens[:index], ",")) + 1
+		}
+		return e
+	}
+
+	hashed := false
+	for i, name := range fieldNames[:len(fields)] {
+		if !strings.Contains(fields[i], "H") {
+			continue
+		}
+		tokens := strings.Split(fields[i], ",")
+		for j, token := range tokens {
+			expanded, ok, err := expandHash(token, name, o.key, o.weekdays)
+			if err != nil {
+				return nil, fieldError(i, &tokenError{index: j, err: err})
+			}
+			tokens[j] = expanded
+			hashed = hashed || ok
+			for range strings.Count(expanded, ",") + 1 {
+				origins[i] = append(origins[i], j)
+			}
+		}
+		fields[i] = strings.Join(tokens, ",")
+	}
+
+	strict := o.strictness == Strict
+	seconds, err := parseField(fields[0], 0, 59, nil, strict)
+	if err != nil {
+		return nil, fieldError(0, err)
 	}
 
-	minutes, err := parseField(fields[0], 0, 59, nil)
+	minutes, err := parseField(fields[1], 0, 59, nil, strict)
 	if err != nil {
-		return nil, err
+		return nil, fieldError(1, err)
 	}
 
-	hours, err := parseField(fields[1], 0, 23, nil)
+	hours, err := parseField(fields[2], 0, 23, nil, strict)
 	if err != nil {
-		return nil, err
+		return nil, fieldError(2, err)
 	}
 
-	dayOfMonth, err := parseField(fields[2], 1, 31, nil)
+	// "?" is Quartz's "no specific value", which for matching is "*".
+	anyDayOfMonth := fields[3] == "*" || fields[3] == "?"
+	anyDayOfWeek := fields[5] == "*" || fields[5] == "?"
+	if anyDayOfMonth {
+		fields[3] = "*"
+	}
+	if anyDayOfWeek {
+		fields[5] = "*"
+	}
+
+	cronExpr := &CronExpression{
+		anyDayOfMonth: anyDayOfMonth,
+		anyDayOfWeek:  anyDayOfWeek,
+		hashed:        hashed,
+	}
+	daysOfMonth, err := parseDayOfMonth(fields[3], cronExpr, strict)
 	if err != nil {
-		return nil, err
+		return nil, fieldError(3, err)
 	}
 
-	month, err := parseField(fields[3], 1, 12, monthNameToNumber)
+	month, err := parseField(fields[4], 1, 12, monthNameToNumber, strict)
 	if err != nil {
-		return nil, err
+		return nil, fieldError(4, err)
 	}
 
-	dayOfWeek, err := parseField(fields[4], 0, 6, dayNameToNumber)
+	daysOfWeek, err := parseDayOfWeek(fields[5], cronExpr, strict, o.weekdays)
 	if err != nil {
-		return nil, err
+		return nil, fieldError(5, err)
+	}
+
+	if len(fields) == 7 && fields[6] != "*" {
+		years, err := parseField(fields[6], 1970, 2099, nil, strict)
+		if err != nil {
+			return nil, fieldError(6, err)
+		}
+		cronExpr.years = slices.Compact(slices.Sorted(slices.Values(years)))
+	}
+
+	cronExpr.seconds = newFieldSet(seconds)
+	cronExpr.minutes = newFieldSet(minutes)
+	cronExpr.hours = newFieldSet(hours)
+	cronExpr.daysOfMonth = newFieldSet(daysOfMonth)
+	cronExpr.months = newFieldSet(month)
+	cronExpr.daysOfWeek = newFieldSet(daysOfWeek)
+	return cronExpr, nil
+}
+
+// fieldPositions returns the character position, from 1, of each field of
+// expr as split by strings.Fields.
+func fieldPositions(expr string) []int {
+	var positions []int
+	inField := false
+	n := 0
+	for _, r := range expr {
+		n++
+		space := unicode.IsSpace(r)
+		if !space && !inField {
+			positions = append(positions, n)
+		}
+		inField = !space
 	}
+	return positions
+}
+
+// fieldNames names the fields of a 7-field expression, for errors.
+var fieldNames = []string{"second", "minute", "hour", "day-of-month", "month", "day-of-week", "year"}
+
+// fieldCounts describes the field counts each ParseMode accepts.
+var fieldCounts = map[ParseMode]string{
+	ParseAuto:        "5, 6 or 7",
+	ParseStandard:    "5",
+	ParseWithSeconds: "6 or 7",
+}
+
+// FieldError is returned by the parser when one field of a cron expression
+// is invalid.
+type FieldError struct {
+	// Field names the field, e.g. "minute" or "day-of-week".
+	Field string
+	// Value is the field's text in the expression.
+	Value string
+	// Index is the field's number in the expression as written, from 1, so
+	// the minute field is field 1 of a five-field expression and field 2
+	// with a seconds field. It is zero if the error is not from parsing an
+	// expression.
+	Index int
+	// Token is the comma-separated part of the field that is invalid, if
+	// the error is about one.
+	Token string
+	// Position is the character position of Token, or of the field if
+	// Token is empty, in the expression, from 1. It is zero if unknown.
+	Position int
+	// Err describes what is wrong with it.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	msg := e.Field + " field"
+	if e.Token != "" {
+		msg += fmt.Sprintf(", token %q", e.Token)
+	}
+	if e.Position > 0 {
+		msg += fmt.Sprintf(" at position %d", e.Position)
+	}
+	return fmt.Sprintf("%s: %v", msg, e.Err)
+}
 
-	return &CronExpression{
-		Minutes:    minutes,
-		Hours:      hours,
-		DayOfMonth: dayOfMonth,
-		Month:      month,
-		DayOfWeek:  dayOfWeek,
-	}, nil
+func (e *FieldError) Unwrap() error {
+	return e.Err
 }
 
-func parseField(field string, min, max int, nameToNumber map[string]int) ([]int, error) {
-	if field == "*" {
-		var values []int
-		for i := min; i <= max; i++ {
-			values = append(values, i)
+// Validate reports whether expr is a valid cron expression, as accepted by
+// ParseCronExpression with opts. Errors about a single field are a
+// *FieldError, so applications can point users at the faulty field and
+// token, e.g. `minute field, token "75" at position 1: value 75 out of
+// range 0-59`.
+func Validate(expr string, opts ...ParseOption) error {
+	_, err := ParseCronExpression(expr, opts...)
+	return err
+}
+
+// cutTimeZone reports whether expr starts with a "CRON_TZ=" or "TZ="
+// prefix, as in "CRON_TZ=America/New_York 0 9 * * Mon", returning the
+// prefix and the text after it.
+func cutTimeZone(expr string) (prefix, rest string, ok bool) {
+	expr = strings.TrimSpace(expr)
+	for _, prefix := range []string{"CRON_TZ=", "TZ="} {
+		if rest, ok := strings.CutPrefix(expr, prefix); ok {
+			return strings.TrimSuffix(prefix, "="), rest, true
 		}
-		return values, nil
 	}
+	return "", "", false
+}
 
-	if strings.Contains(field, "/") {
-		return parseStepField(field, min, max, nameToNumber)
+// String returns a canonical cron string for the expression, which
+// ParseCronExpressionMode parses back to an equivalent expression in
+// ParseAuto mode. Fields
+// spanning their whole range are written "*", evenly stepped fields "*/n",
+// and runs of consecutive values ranges. Seconds are left out when they are
+// only 0 and there is no year field. A Location is written as a "CRON_TZ="
+// prefix.
+func (expr *CronExpression) String() string {
+	if expr.Location != nil {
+		local := *expr
+		local.Location = nil
+		return "CRON_TZ=" + expr.Location.String() + " " + local.String()
+	}
+	if expr.reboot {
+		return "@reboot"
+	}
+	if expr.interval > 0 {
+		return "@every " + expr.interval.String()
 	}
 
-	var values []int
-	parts := strings.Split(field, ",")
-	for _, part := range parts {
-		if strings.Contains(part, "-") {
-			rangeValues, err := parseRange(part, min, max, nameToNumber)
-			if err != nil {
-				return nil, err
+	dayOfMonth := "*"
+	if !expr.anyDayOfMonth {
+		parts := formatValues(expr.daysOfMonth.values(), 1, 31, false)
+		for _, offset := range expr.lastDaysOfMonth {
+			if offset == 0 {
+				parts = append(parts, "L")
+			} else {
+				parts = append(parts, fmt.Sprintf("L-%d", offset))
 			}
-			values = append(values, rangeValues...)
-		} else {
-			num, err := parseValue(part, min, max, nameToNumber)
-			if err != nil {
-				return nil, err
-			}
-			values = append(values, num)
 		}
+		for _, day := range expr.nearestWeekdays {
+			parts = append(parts, fmt.Sprintf("%dW", day))
+		}
+		if expr.lastWeekdayOfMonth {
+			parts = append(parts, "LW")
+		}
+		dayOfMonth = strings.Join(parts, ",")
 	}
-	return values, nil
+
+	dayOfWeek := "*"
+	if !expr.anyDayOfWeek {
+		parts := formatValues(expr.daysOfWeek.values(), 0, 6, false)
+		for _, weekday := range expr.lastWeekdays {
+			parts = append(parts, fmt.Sprintf("%dL", weekday))
+		}
+		for _, nth := range expr.nthWeekdays {
+			parts = append(parts, fmt.Sprintf("%d#%d", nth.weekday, nth.n))
+		}
+		dayOfWeek = strings.Join(parts, ",")
+	}
+
+	fields := []string{
+		formatField(expr.seconds.values(), 0, 59),
+		formatField(expr.minutes.values(), 0, 59),
+		formatField(expr.hours.values(), 0, 23),
+		dayOfMonth,
+		formatField(expr.months.values(), 1, 12),
+		dayOfWeek,
+	}
+	if expr.years != nil {
+		fields = append(fields, formatField(expr.years, 1970, 2099))
+	} else if expr.seconds == 1 {
+		fields = fields[1:]
+	}
+	return strings.Join(fields, " ")
 }
 
-func parseValue(part string, min, max int, nameToNumber map[string]int) (int, error) {
+// Normalize returns a copy of the expression in canonical form, with the
+// values of every field sorted and deduplicated and a year field spanning
+// every year dropped, so equivalent expressions written differently, such
+// as "0,30 9-17 * * MON-FRI" and "*/30 9-17 * * 1-5", normalize to the same
+// value. A day field listing every day is normalized to "*" if the other
+// day field is unrestricted too or DayMatching is DayAnd; under DayOr,
+// with the other field restricted, the two differ.
+func (expr *CronExpression) Normalize() *CronExpression {
+	n := *expr
+	n.years = normalizeValues(expr.years)
+	if len(n.years) == 2099-1970+1 {
+		n.years = nil
+	}
+	n.lastDaysOfMonth = normalizeValues(expr.lastDaysOfMonth)
+	n.lastWeekdays = normalizeValues(expr.lastWeekdays)
+	n.nearestWeekdays = normalizeValues(expr.nearestWeekdays)
+	n.nthWeekdays = slices.Clone(expr.nthWeekdays)
+	slices.SortFunc(n.nthWeekdays, func(a, b nthWeekday) int {
+		if a.weekday != b.weekday {
+			return a.weekday - b.weekday
+		}
+		return a.n - b.n
+	})
+	n.nthWeekdays = slices.Compact(n.nthWeekdays)
+	if len(n.nthWeekdays) == 0 {
+		n.nthWeekdays = nil
+	}
+	// A day field listing its whole range matches like "*", unless days
+	// match either field and the other field is restricted.
+	allDaysOfMonth := n.anyDayOfMonth || n.daysOfMonth.len() == 31 && n.lastDaysOfMonth == nil &&
+		n.nearestWeekdays == nil && !n.lastWeekdayOfMonth
+	allDaysOfWeek := n.anyDayOfWeek || n.daysOfWeek.len() == 7 && n.lastWeekdays == nil && n.nthWeekdays == nil
+	if n.DayMatching != DayOr || allDaysOfWeek {
+		n.anyDayOfMonth = allDaysOfMonth
+	}
+	if n.DayMatching != DayOr || allDaysOfMonth {
+		n.anyDayOfWeek = allDaysOfWeek
+	}
+	// Any "H" fields are resolved to the values they hashed to.
+	n.hashed = false
+	return &n
+}
 
-	caser := cases.Title(language.Und)
+// Equal reports whether the expression and other fire at the same times:
+// they match the same values once normalized, and have the same day
+// matching, DST policy and Location. It lets callers tell whether a changed
+// configuration actually changed a schedule.
+func (expr *CronExpression) Equal(other *CronExpression) bool {
+	if expr == nil || other == nil {
+		return expr == other
+	}
+	a, b := expr.Normalize(), other.Normalize()
+	return a.seconds == b.seconds &&
+		a.minutes == b.minutes &&
+		a.hours == b.hours &&
+		a.daysOfMonth == b.daysOfMonth &&
+		a.months == b.months &&
+		a.daysOfWeek == b.daysOfWeek &&
+		slices.Equal(a.years, b.years) &&
+		a.DayMatching == b.DayMatching &&
+		a.DSTPolicy == b.DSTPolicy &&
+		locationName(a.Location) == locationName(b.Location) &&
+		a.anyDayOfMonth == b.anyDayOfMonth &&
+		a.anyDayOfWeek == b.anyDayOfWeek &&
+		slices.Equal(a.lastDaysOfMonth, b.lastDaysOfMonth) &&
+		slices.Equal(a.lastWeekdays, b.lastWeekdays) &&
+		slices.Equal(a.nearestWeekdays, b.nearestWeekdays) &&
+		a.lastWeekdayOfMonth == b.lastWeekdayOfMonth &&
+		slices.Equal(a.nthWeekdays, b.nthWeekdays) &&
+		a.reboot == b.reboot &&
+		a.interval == b.interval
+}
 
-	// Check if the part is a named value
-	if num, ok := nameToNumber[caser.String(strings.ToLower(part))]; ok {
-		return num, nil
+// locationName returns the name of loc, or "" if it is nil.
+func locationName(loc *time.Location) string {
+	if loc == nil {
+		return ""
 	}
+	return loc.String()
+}
 
-	// Try parsing as integer
-	num, err := strconv.Atoi(part)
-	if err != nil {
-		return 0, fmt.Errorf("invalid value: %s", part)
+// normalizeValues returns a sorted copy of values without duplicates, or
+// nil if there are none.
+func normalizeValues(values []int) []int {
+	if len(values) == 0 {
+		return nil
 	}
-	if num < min || num > max {
-		return 0, fmt.Errorf("value out of range: %d", num)
+	return slices.Compact(slices.Sorted(slices.Values(values)))
+}
+
+// formatField formats a field's values, writing "*" for the whole range.
+func formatField(values []int, min, max int) string {
+	return strings.Join(formatValues(values, min, max, true), ",")
+}
+
+// formatValues returns the comma-separated parts describing values: runs
+// of three or more values are written "a-b", or "a-b/n" if stepped by n.
+// If star is set, the whole range is "*" and two or more values stepped
+// from min "*/n", matching how the parser expands them.
+func formatValues(values []int, min, max int, star bool) []string {
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	sorted = slices.Compact(sorted)
+	if len(sorted) == 0 {
+		return nil
 	}
-	return num, nil
+
+	if star && len(sorted) > 1 && sorted[0] == min {
+		step := sorted[1] - sorted[0]
+		stepped := sorted[len(sorted)-1]+step > max
+		for i := 1; i < len(sorted) && stepped; i++ {
+			stepped = sorted[i]-sorted[i-1] == step
+		}
+		if stepped && step == 1 {
+			return []string{"*"}
+		}
+		if stepped {
+			return []string{fmt.Sprintf("*/%d", step)}
+		}
+	}
+
+	var parts []string
+	for i := 0; i < len(sorted); {
+		// sorted[i:j+1] is the longest evenly stepped run from i.
+		j, step := i, 0
+		if i+1 < len(sorted) {
+			step = sorted[i+1] - sorted[i]
+			for j+1 < len(sorted) && sorted[j+1]-sorted[j] == step {
+				j++
+			}
+		}
+		switch {
+		case j-i < 2:
+			parts = append(parts, strconv.Itoa(sorted[i]))
+			j = i
+		case step == 1:
+			parts = append(parts, fmt.Sprintf("%d-%d", sorted[i], sorted[j]))
+		default:
+			parts = append(parts, fmt.Sprintf("%d-%d/%d", sorted[i], sorted[j], step))
+		}
+		i = j + 1
+	}
+	return parts
 }
 
-func parseStepField(field string, min, max int, nameToNumber map[string]int) ([]int, error) {
-	parts := strings.Split(field, "/")
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid step field: %s", field)
+// Next returns the first time after from at which the expression fires,
+// evaluated in from's location, or the zero time if it never fires again.
+// "@reboot" expressions never fire, and "@every" expressions fire one
+// interval after from.
+func (expr *CronExpression) Next(from time.Time) time.Time {
+	if expr.Location == nil {
+		return nextRunTime(expr, from)
+	}
+	next := nextRunTime(expr, from.In(expr.Location))
+	if next.IsZero() {
+		return next
 	}
+	return next.In(from.Location())
+}
 
-	baseValues, err := parseField(parts[0], min, max, nameToNumber)
+func parseEvery(value string) (*CronExpression, error) {
+	interval, err := time.ParseDuration(value)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("invalid @every interval: %s", value)
 	}
-
-	step, err := strconv.Atoi(parts[1])
-	if err != nil || step <= 0 {
-		return nil, fmt.Errorf("invalid step: %s", parts[1])
+	if interval <= 0 {
+		return nil, fmt.Errorf("@every interval must be positive: %s", value)
 	}
+	return &CronExpression{interval: interval}, nil
+}
 
+// parseDayOfMonth parses the day-of-month field, recording the "L", "L-n",
+// "nW" and "LW" parts in expr and returning the plain days.
+func parseDayOfMonth(field string, expr *CronExpression, strict bool) ([]int, error) {
+	if !strings.ContainsAny(field, "LlWw") {
+		return parseField(field, 1, 31, nil, strict)
+	}
 	var values []int
-	for _, val := range baseValues {
-		if (val-min)%step == 0 {
-			values = append(values, val)
+	for i, part := range strings.Split(field, ",") {
+		upper := strings.ToUpper(part)
+		switch {
+		case upper == "LW":
+			expr.lastWeekdayOfMonth = true
+		case len(upper) > 1 && strings.HasSuffix(upper, "W"):
+			day, err := parseValue(upper[:len(upper)-1], 1, 31, nil)
+			if err != nil {
+				return nil, &tokenError{index: i, err: fmt.Errorf("invalid nearest weekday: %s", part)}
+			}
+			expr.nearestWeekdays = append(expr.nearestWeekdays, day)
+		case upper == "L":
+			expr.lastDaysOfMonth = append(expr.lastDaysOfMonth, 0)
+		case strings.HasPrefix(upper, "L-"):
+			offset, err := strconv.Atoi(upper[2:])
+			if err != nil || offset < 0 || offset > 30 {
+				return nil, &tokenError{index: i, err: fmt.Errorf("invalid last day offset: %s", part)}
+			}
+			expr.lastDaysOfMonth = append(expr.lastDaysOfMonth, offset)
+		default:
+			days, err := parsePart(part, 1, 31, nil, strict)
+			if err != nil {
+				return nil, &tokenError{index: i, err: err}
+			}
+			values = append(values, days...)
 		}
 	}
 	return values, nil
 }
 
-func parseRange(part string, min, max int, nameToNumber map[string]int) ([]int, error) {
-	rangeParts := strings.Split(part, "-")
-	if len(rangeParts) != 2 {
-		return nil, fmt.Errorf("invalid range: %s", part)
+// parseDayOfWeek parses the day-of-week field, recording the "nL" and
+// "weekday#n" parts in expr and returning the plain weekdays, numbered from
+// 0 for Sunday whatever the numbering of the field. A lone "L" is Saturday,
+// the last day of the week, as in Quartz.
+func parseDayOfWeek(field string, expr *CronExpression, strict bool, numbering WeekdayNumbering) ([]int, error) {
+	lo, hi, names := 0, 7, dayNameToNumber
+	if numbering == QuartzWeekdays {
+		lo, names = 1, quartzDayNameToNumber
+	}
+	if !strings.ContainsAny(field, "Ll#") {
+		weekdays, err := parseField(field, lo, hi, names, strict)
+		return normalizeWeekdays(weekdays, numbering), err
+	}
+	var values []int
+	for i, part := range strings.Split(field, ",") {
+		upper := strings.ToUpper(part)
+		switch {
+		case strings.Contains(part, "#"):
+			weekdayPart, nPart, _ := strings.Cut(part, "#")
+			weekday, err := parseValue(weekdayPart, lo, hi, names)
+			if err != nil {
+				return nil, &tokenError{index: i, err: fmt.Errorf("invalid nth weekday: %s", part)}
+			}
+			n, err := strconv.Atoi(nPart)
+			if err != nil || n < 1 || n > 5 {
+				return nil, &tokenError{index: i, err: fmt.Errorf("invalid nth weekday: %s", part)}
+			}
+			expr.nthWeekdays = append(expr.nthWeekdays, nthWeekday{weekday: toWeekday(weekday, numbering), n: n})
+		case upper == "L":
+			values = append(values, 6)
+		case len(upper) > 1 && strings.HasSuffix(upper, "L"):
+			weekday, err := parseValue(part[:len(part)-1], lo, hi, names)
+			if err != nil {
+				return nil, &tokenError{index: i, err: fmt.Errorf("invalid last weekday: %s", part)}
+			}
+			expr.lastWeekdays = append(expr.lastWeekdays, toWeekday(weekday, numbering))
+		default:
+			weekdays, err := parsePart(part, lo, hi, names, strict)
+			if err != nil {
+				return nil, &tokenError{index: i, err: err}
+			}
+			values = append(values, normalizeWeekdays(weekdays, numbering)...)
+		}
 	}
+	return normalizeWeekdays(values, CronWeekdays), nil
+}
 
-	start, err := parseValue(rangeParts[0], min, max, nameToNumber)
-	if err != nil {
-		return nil, fmt.Errorf("invalid range start: %s", rangeParts[0])
+// toWeekday converts a day of the week in the given numbering to the
+// time.Weekday numbering the matcher uses, from 0 for Sunday to 6 for
+// Saturday. Standard cron's 7 for Sunday becomes 0.
+func toWeekday(day int, numbering WeekdayNumbering) int {
+	if numbering == QuartzWeekdays {
+		return day - 1
 	}
+	return day % 7
+}
 
-	end, err := parseValue(rangeParts[1], min, max, nameToNumber)
-	if err != nil {
-		return nil, fmt.Errorf("invalid range end: %s", rangeParts[1])
+// normalizeWeekdays converts days of the week in the given numbering with
+// toWeekday and drops the duplicates this creates, such as 0 and 7 for
+// Sunday.
+func normalizeWeekdays(weekdays []int, numbering WeekdayNumbering) []int {
+	var values []int
+	for _, day := range weekdays {
+		if weekday := toWeekday(day, numbering); !contains(values, weekday) {
+			values = append(values, weekday)
+		}
+	}
+	return values
+}
+
+// tokenError is an error about one comma-separated token of a field, by
+// its index in the field.
+type tokenError struct {
+	index int
+	err   error
+}
+
+func (e *tokenError) Error() string { return e.err.Error() }
+
+func (e *tokenError) Unwrap() error { return e.err }
+
+// parseField parses each comma-separated token of field with parsePart,
+// reporting an invalid token with a *tokenError.
+func parseField(field string, min, max int, nameToNumber map[string]int, strict bool) ([]int, error) {
+	var values []int
+	for i, part := range strings.Split(field, ",") {
+		partValues, err := parsePart(part, min, max, nameToNumber, strict)
+		if err != nil {
+			return nil, &tokenError{index: i, err: err}
+		}
+		values = append(values, partValues...)
+	}
+	return values, nil
+}
+
+// parsePart parses one token of a field: "*", a value, or a range, each
+// optionally followed by "/step". Steps count from the start of the range,
+// which for a single value runs to max, so "5/15" in the minute field is
+// 5, 20, 35 and 50. Ranges whose start is after their end, like "Fri-Mon",
+// wrap around, unless strict, which rejects them as Strict describes.
+func parsePart(part string, min, max int, nameToNumber map[string]int, strict bool) ([]int, error) {
+	if part == "" {
+		return nil, errors.New("empty value")
+	}
+	base, stepText, hasStep := strings.Cut(part, "/")
+	step := 1
+	if hasStep {
+		if strings.Contains(stepText, "/") {
+			return nil, fmt.Errorf("invalid step field: %s", part)
+		}
+		var err error
+		if step, err = strconv.Atoi(stepText); err != nil || step <= 0 {
+			return nil, fmt.Errorf("invalid step: %s", stepText)
+		}
 	}
 
-	if start > end {
-		// For ranges like Fri-Mon, which should wrap around
-		var values []int
-		for i := start; i <= max; i++ {
-			values = append(values, i)
+	var start, end int
+	switch {
+	case base == "*":
+		start, end = min, max
+	case strings.Contains(base, "-"):
+		var err error
+		if start, end, err = parseRange(base, min, max, nameToNumber, strict); err != nil {
+			return nil, err
 		}
-		for i := min; i <= end; i++ {
-			values = append(values, i)
+	default:
+		value, err := parseValue(base, min, max, nameToNumber)
+		if err != nil {
+			return nil, err
+		}
+		start, end = value, value
+		if hasStep {
+			end = max
 		}
-		return values, nil
 	}
 
+	size := max - min + 1
+	span := end - start
+	if span < 0 {
+		span += size
+	}
+	if strict && hasStep && step > span {
+		return nil, fmt.Errorf("step %d is larger than the range of %s", step, part)
+	}
+	// A step past the span only keeps the start, and capping it keeps the
+	// loop from overflowing.
+	if step > size {
+		step = size
+	}
 	var values []int
-	for i := start; i <= end; i++ {
-		values = append(values, i)
+	for offset := 0; offset <= span; offset += step {
+		value := start + offset
+		if value > max {
+			value -= size
+		}
+		values = append(values, value)
 	}
 	return values, nil
 }
+
+func parseValue(part string, min, max int, nameToNumber map[string]int) (int, error) {
+	if part == "" {
+		return 0, errors.New("empty value")
+	}
+
+	caser := cases.Title(language.Und)
+
+	// Check if the part is a named value
+	if num, ok := nameToNumber[caser.String(strings.ToLower(part))]; ok {
+		return num, nil
+	}
+
+	// Try parsing as integer
+	num, err := strconv.Atoi(part)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value: %s", part)
+	}
+	if num < min || num > max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", num, min, max)
+	}
+	return num, nil
+}
+
+// parseRange parses the bounds of a range such as "1-5" or "Mon-Fri". In a
+// day-of-week range, a Sunday end given as 0 or "Sun" is read as 7.
+func parseRange(part string, min, max int, nameToNumber map[string]int, strict bool) (start, end int, err error) {
+	startText, endText, _ := strings.Cut(part, "-")
+	if strings.Contains(endText, "-") {
+		return 0, 0, fmt.Errorf("invalid range: %s", part)
+	}
+
+	if start, err = parseValue(startText, min, max, nameToNumber); err != nil {
+		return 0, 0, fmt.Errorf("invalid range start: %w", err)
+	}
+	if end, err = parseValue(endText, min, max, nameToNumber); err != nil {
+		return 0, 0, fmt.Errorf("invalid range end: %w", err)
+	}
+	if max == 7 && end == 0 && start > 0 {
+		end = 7
+	}
+	if strict {
+		_, startErr := strconv.Atoi(startText)
+		_, endErr := strconv.Atoi(endText)
+		if (startErr == nil) != (endErr == nil) {
+			return 0, 0, fmt.Errorf("range mixes names and numbers: %s", part)
+		}
+		if start > end {
+			return 0, 0, fmt.Errorf("range start is after its end: %s", part)
+		}
+	}
+	return start, end, nil
+}
@@ -1,10 +1,29 @@
 package cronjob
 
 import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"sync"
 	"testing"
 	"time"
+	"unicode/utf8"
 )
 
 // TestParseCronExpression tests the parsing of valid and invalid cron expressions.
@@ -44,12 +63,12 @@ func TestParseCronExpression(t *testing.T) {
 func TestSchedulerAddJob(t *testing.T) {
 	scheduler := NewCronScheduler()
 
-	err := scheduler.AddJob("* * * * *", func() {})
+	_, err := scheduler.AddJob("* * * * *", func() {})
 	if err != nil {
 		t.Errorf("Failed to add valid job: %v", err)
 	}
 
-	err = scheduler.AddJob("invalid cron", func() {})
+	_, err = scheduler.AddJob("invalid cron", func() {})
 	if err == nil {
 		t.Errorf("Expected error when adding job with invalid cron expression")
 	}
@@ -59,17 +78,221 @@ func TestSchedulerAddJob(t *testing.T) {
 func TestSchedulerRemoveJob(t *testing.T) {
 	scheduler := NewCronScheduler()
 
-	_ = scheduler.AddJob("* * * * *", func() {})
-	_ = scheduler.AddJob("*/5 * * * *", func() {})
+	_, _ = scheduler.AddJob("* * * * *", func() {})
+	job, _ := scheduler.AddJob("*/5 * * * *", func() {})
+	id := job.ID
 
-	err := scheduler.RemoveJob(1)
+	err := scheduler.RemoveJob(id)
 	if err != nil {
 		t.Errorf("Failed to remove job: %v", err)
 	}
 
-	err = scheduler.RemoveJob(5) // Invalid index
-	if err == nil {
-		t.Errorf("Expected error when removing job with invalid index")
+	err = scheduler.RemoveJob(id) // Already removed
+	if !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound when removing unknown job, got %v", err)
+	}
+
+	if len(scheduler.jobs) != 1 {
+		t.Errorf("Expected 1 remaining job, got %d", len(scheduler.jobs))
+	}
+}
+
+// TestSchedulerNamedJobs tests adding, looking up and removing jobs by name.
+func TestSchedulerNamedJobs(t *testing.T) {
+	scheduler := NewCronScheduler()
+
+	if _, err := scheduler.AddNamedJob("report", "0 6 * * *", func() {}); err != nil {
+		t.Fatalf("Failed to add named job: %v", err)
+	}
+	if _, err := scheduler.AddNamedJob("report", "0 7 * * *", func() {}); !errors.Is(err, ErrDuplicateJobID) {
+		t.Errorf("Expected ErrDuplicateJobID, got %v", err)
+	}
+
+	job, err := scheduler.GetJob("report")
+	if err != nil {
+		t.Fatalf("Failed to get job: %v", err)
+	}
+	if job.ID != "report" {
+		t.Errorf("Expected job ID 'report', got %q", job.ID)
+	}
+
+	if _, err := scheduler.GetJob("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+
+	if err := scheduler.RemoveJob("report"); err != nil {
+		t.Errorf("Failed to remove named job: %v", err)
+	}
+}
+
+// TestUpsertJob tests that UpsertJob replaces a job with the same ID instead
+// of adding a second one.
+func TestUpsertJob(t *testing.T) {
+	scheduler := NewCronScheduler(WithStore(NewFileStore(filepath.Join(t.TempDir(), "jobs.json"))))
+	events := make(chan JobEvent, 16)
+	defer scheduler.Subscribe(events)()
+
+	if _, err := scheduler.UpsertJob("report", "0 6 * * *", func() {}); err != nil {
+		t.Fatalf("Failed to upsert new job: %v", err)
+	}
+	ran := make(chan struct{}, 1)
+	job, err := scheduler.UpsertJob("report", "0 7 * * *", func() { ran <- struct{}{} })
+	if err != nil {
+		t.Fatalf("Failed to upsert existing job: %v", err)
+	}
+	if got, _ := scheduler.GetJob("report"); job.ID != "report" || got != job {
+		t.Errorf("Expected the replacement job to be returned, got %+v", job)
+	}
+
+	if len(scheduler.jobs) != 1 {
+		t.Fatalf("Expected 1 job, got %v", scheduler.ListJobs())
+	}
+	if info, _ := scheduler.JobInfo("report"); info.Expression != "0 7 * * *" {
+		t.Errorf("Expected the replacement's expression, got %q", info.Expression)
+	}
+	if e := <-events; e.Type != EventRemoved || e.JobID != "report" {
+		t.Errorf("Expected the replaced job's removal event, got %v", e)
+	}
+	_ = scheduler.RunNowAndWait(context.Background(), "report")
+	select {
+	case <-ran:
+	default:
+		t.Errorf("Expected the replacement's task to run")
+	}
+
+	records, _ := scheduler.store.Load()
+	if len(records) != 1 || records[0].Expression != "0 7 * * *" {
+		t.Errorf("Expected the store to hold the replacement, got %+v", records)
+	}
+}
+
+// TestUpdateSchedule tests changing a job's expression in place.
+func TestUpdateSchedule(t *testing.T) {
+	scheduler := NewCronScheduler()
+	var mu sync.Mutex
+	runs := 0
+	job, _ := scheduler.AddJob("@yearly", func() {
+		mu.Lock()
+		runs++
+		mu.Unlock()
+	})
+	id := job.ID
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	_ = scheduler.RunNowAndWait(context.Background(), id)
+	_ = scheduler.PauseJob(id)
+	if err := scheduler.UpdateSchedule(id, "@every 20ms"); err != nil {
+		t.Fatalf("Failed to update the schedule: %v", err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	info, _ := scheduler.JobInfo(id)
+	if !info.Paused || info.RunCount != 1 || info.Expression != "@every 20ms" {
+		t.Errorf("Expected a paused job with 1 run and the new expression, got %+v", info)
+	}
+
+	_ = scheduler.ResumeJob(id)
+	time.Sleep(70 * time.Millisecond)
+	mu.Lock()
+	if runs < 3 {
+		t.Errorf("Expected the new schedule to fire, got %d runs", runs)
+	}
+	mu.Unlock()
+
+	if err := scheduler.UpdateSchedule(id, "not a schedule"); err == nil {
+		t.Errorf("Expected an invalid expression to fail")
+	}
+	if err := scheduler.UpdateSchedule("missing", "@daily"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+}
+
+// TestRunOnStart tests that WithRunOnStart jobs run once when the scheduler
+// starts, or when added to a running one, unless paused.
+func TestRunOnStart(t *testing.T) {
+	scheduler := NewCronScheduler()
+	ran := make(chan string, 10)
+	task := func(name string) func() {
+		return func() { ran <- name }
+	}
+	_, _ = scheduler.AddNamedJob("warmer", "@yearly", task("warmer"), WithRunOnStart())
+	_, _ = scheduler.AddNamedJob("paused", "@yearly", task("paused"), WithRunOnStart())
+	_, _ = scheduler.AddNamedJob("plain", "@yearly", task("plain"))
+	_ = scheduler.PauseJob("paused")
+
+	scheduler.Start()
+	defer scheduler.Stop()
+	_, _ = scheduler.AddNamedJob("late", "@yearly", task("late"), WithRunOnStart())
+
+	var got []string
+	timeout := time.After(time.Second)
+	for len(got) < 2 {
+		select {
+		case name := <-ran:
+			got = append(got, name)
+		case <-timeout:
+			t.Fatalf("Expected warmer and late to run, got %v", got)
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, []string{"late", "warmer"}) || len(ran) != 0 {
+		t.Errorf("Expected only warmer and late to run, got %v and %d more", got, len(ran))
+	}
+	if info, _ := scheduler.JobInfo("warmer"); info.NextRun.IsZero() {
+		t.Errorf("Expected warmer to stay on its schedule")
+	}
+}
+
+// TestDelayedStart tests StartAt, WithInitialDelay and cancelling a pending
+// start with Stop.
+func TestDelayedStart(t *testing.T) {
+	countRuns := func(scheduler *CronScheduler) func() int {
+		var mu sync.Mutex
+		runs := 0
+		_, _ = scheduler.AddJob("@every 10ms", func() {
+			mu.Lock()
+			runs++
+			mu.Unlock()
+		})
+		return func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return runs
+		}
+	}
+
+	for _, delayed := range []bool{false, true} {
+		var scheduler *CronScheduler
+		if delayed {
+			scheduler = NewCronScheduler(WithInitialDelay(80 * time.Millisecond))
+		} else {
+			scheduler = NewCronScheduler()
+		}
+		runs := countRuns(scheduler)
+		if delayed {
+			scheduler.Start()
+		} else {
+			scheduler.StartAt(time.Now().Add(80 * time.Millisecond))
+		}
+		time.Sleep(50 * time.Millisecond)
+		if n := runs(); n != 0 {
+			t.Errorf("Delayed %v: expected no runs before the start time, got %d", delayed, n)
+		}
+		time.Sleep(100 * time.Millisecond)
+		if n := runs(); n == 0 {
+			t.Errorf("Delayed %v: expected runs after the start time", delayed)
+		}
+		scheduler.Stop()
+	}
+
+	scheduler := NewCronScheduler()
+	runs := countRuns(scheduler)
+	scheduler.StartAt(time.Now().Add(30 * time.Millisecond))
+	scheduler.Stop()
+	time.Sleep(70 * time.Millisecond)
+	if n := runs(); n != 0 {
+		t.Errorf("Expected Stop to cancel the pending start, got %d runs", n)
 	}
 }
 
@@ -85,7 +308,7 @@ func TestSchedulerExecution(t *testing.T) {
 	minute := (now.Minute() + 1) % 60
 	cronExpr := fmt.Sprintf("%d %d %d %d %d", minute, now.Hour(), now.Day(), int(now.Month()), now.Weekday())
 
-	err := scheduler.AddJob(cronExpr, func() {
+	_, err := scheduler.AddJob(cronExpr, func() {
 		wg.Done()
 	})
 	if err != nil {
@@ -161,7 +384,7 @@ func TestCronScheduler_Concurrency(t *testing.T) {
 	cronExpr := fmt.Sprintf("%d %d * * *", minute, now.Hour())
 
 	for i := 0; i < 5; i++ {
-		err := scheduler.AddJob(cronExpr, task)
+		_, err := scheduler.AddJob(cronExpr, task)
 		if err != nil {
 			t.Fatalf("Failed to add job: %v", err)
 		}
@@ -179,3 +402,4969 @@ func TestCronScheduler_Concurrency(t *testing.T) {
 	}
 	mu.Unlock()
 }
+
+// TestAddJobContextCancellation tests that task contexts are cancelled on Stop and on removal.
+func TestAddJobContextCancellation(t *testing.T) {
+	cases := []struct {
+		name   string
+		cancel func(s *CronScheduler, id string)
+	}{
+		{"stop", func(s *CronScheduler, id string) { s.Stop() }},
+		{"remove", func(s *CronScheduler, id string) { _ = s.RemoveJob(id) }},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheduler := NewCronScheduler()
+			started := make(chan struct{})
+			done := make(chan struct{})
+			job, err := scheduler.AddJobContext("* * * * *", func(ctx context.Context) {
+				close(started)
+				<-ctx.Done()
+				close(done)
+			})
+			if err != nil {
+				t.Fatalf("Failed to add job: %v", err)
+			}
+
+			scheduler.Start()
+			defer scheduler.Stop()
+
+			scheduler.mutex.Lock()
+			schedulerCtx := scheduler.ctx
+			scheduler.mutex.Unlock()
+			go scheduler.runJob(schedulerCtx, job, time.Time{})
+			<-started
+
+			tc.cancel(scheduler, job.ID)
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Errorf("Task context was not cancelled on %s", tc.name)
+			}
+		})
+	}
+}
+
+// TestSchedulerLocation tests that jobs are evaluated in the scheduler's or the job's location.
+func TestSchedulerLocation(t *testing.T) {
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	if err != nil {
+		t.Skipf("Time zone data not available: %v", err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("Time zone data not available: %v", err)
+	}
+
+	scheduler := NewCronSchedulerWithLocation(lisbon)
+	lisbonJob, _ := scheduler.AddJob("0 9 * * Mon", func() {})
+	lisbonID := lisbonJob.ID
+	tokyoJob, _ := scheduler.AddJob("0 9 * * Mon", func() {}, WithLocation(tokyo))
+	tokyoID := tokyoJob.ID
+
+	tests := []struct {
+		id   string
+		from time.Time
+		want time.Time
+	}{
+		// Winter: Lisbon is UTC+0.
+		{lisbonID, time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)},
+		// Summer: Lisbon is UTC+1.
+		{lisbonID, time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, time.July, 1, 8, 0, 0, 0, time.UTC)},
+		// Across the spring-forward transition on 2024-03-31.
+		{lisbonID, time.Date(2024, time.March, 30, 0, 0, 0, 0, time.UTC), time.Date(2024, time.April, 1, 8, 0, 0, 0, time.UTC)},
+		{tokyoID, time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, time.January, 8, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		job, _ := scheduler.GetJob(test.id)
+		got := nextRunTime(job.cron(), test.from.In(job.location))
+		if !got.Equal(test.want) {
+			t.Errorf("Job %s from %v: expected %v, got %v", test.id, test.from, test.want, got.UTC())
+		}
+		if !isTimeMatching(job.cron(), test.want.In(job.location)) {
+			t.Errorf("Job %s: expected %v to match", test.id, test.want)
+		}
+	}
+}
+
+// TestParseCronExpressionMode tests 5-field, 6-field and 7-field parsing in each mode.
+func TestParseCronExpressionMode(t *testing.T) {
+	tests := []struct {
+		expr       string
+		mode       ParseMode
+		shouldPass bool
+	}{
+		{"* * * * *", ParseAuto, true},
+		{"30 * * * * *", ParseAuto, true},
+		{"* * * * *", ParseStandard, true},
+		{"30 * * * * *", ParseStandard, false},
+		{"* * * * *", ParseWithSeconds, false},
+		{"30 * * * * *", ParseWithSeconds, true},
+		{"60 * * * * *", ParseWithSeconds, false}, // Invalid second
+		{"0 0 0 1 1 * 2026", ParseAuto, true},     // Year field
+		{"0 0 0 1 1 * 2026", ParseStandard, false},
+		{"0 0 0 1 1 * 2026", ParseWithSeconds, true},
+		{"0 0 0 1 1 * 1969", ParseAuto, false}, // Invalid year
+		{"* * * * * * * *", ParseAuto, false},  // Too many fields
+	}
+
+	for _, test := range tests {
+		_, err := ParseCronExpressionMode(test.expr, test.mode)
+		if test.shouldPass && err != nil {
+			t.Errorf("Expected expression '%s' to pass in mode %d, but got error: %v", test.expr, test.mode, err)
+		}
+		if !test.shouldPass && err == nil {
+			t.Errorf("Expected expression '%s' to fail in mode %d, but it passed", test.expr, test.mode)
+		}
+	}
+
+	expr, _ := ParseCronExpression("15 14 * * *")
+	if len(expr.Seconds()) != 1 || expr.Seconds()[0] != 0 {
+		t.Errorf("Expected seconds to default to [0] for 5-field expression, got %v", expr.Seconds())
+	}
+
+	scheduler := NewCronScheduler(WithParseMode(ParseStandard))
+	if _, err := scheduler.AddJob("30 * * * * *", func() {}); err == nil {
+		t.Errorf("Expected scheduler in ParseStandard mode to reject a 6-field expression")
+	}
+}
+
+// TestParseCronExpressionSeconds tests that ParseCronExpression only reads a
+// seconds field with WithSeconds, and rejects the field counts of the other
+// layout instead of guessing.
+func TestParseCronExpressionSeconds(t *testing.T) {
+	expr, err := ParseCronExpression("30 * * * *")
+	if err != nil || !reflect.DeepEqual(expr.Seconds(), []int{0}) || !reflect.DeepEqual(expr.Minutes(), []int{30}) {
+		t.Errorf("Expected a 5-field expression to fire at minute 30, got %v, %v", expr, err)
+	}
+	expr, err = ParseCronExpression("30 * * * * *", WithSeconds())
+	if err != nil || !reflect.DeepEqual(expr.Seconds(), []int{30}) {
+		t.Errorf("Expected WithSeconds to read a leading seconds field, got %v, %v", expr, err)
+	}
+
+	_, err = ParseCronExpression("30 * * * * *")
+	if err == nil || !strings.Contains(err.Error(), "WithSeconds") {
+		t.Errorf("Expected a 6-field expression without WithSeconds to be rejected with a hint, got %v", err)
+	}
+	if _, err := ParseCronExpression("0 0 0 1 1 * 2026"); err == nil {
+		t.Errorf("Expected a 7-field expression without WithSeconds to be rejected")
+	}
+	if _, err := ParseCronExpression("30 * * * *", WithSeconds()); err == nil {
+		t.Errorf("Expected a 5-field expression with WithSeconds to be rejected")
+	}
+	if _, err := ParseCronExpression("@hourly", WithSeconds()); err != nil {
+		t.Errorf("Expected macros to parse with WithSeconds, got %v", err)
+	}
+}
+
+// TestHashedFields tests that "H" fields resolve deterministically from the
+// key within their range.
+func TestHashedFields(t *testing.T) {
+	parse := func(expr, key string) *CronExpression {
+		t.Helper()
+		e, err := ParseCronExpression(expr, WithHashKey(key))
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", expr, err)
+		}
+		return e
+	}
+
+	a, b := parse("H H(0-7) * * *", "backup"), parse("H H(0-7) * * *", "backup")
+	if !reflect.DeepEqual(a.Minutes(), b.Minutes()) || !reflect.DeepEqual(a.Hours(), b.Hours()) {
+		t.Errorf("Expected the same key to give the same schedule, got %v and %v", a, b)
+	}
+	if len(a.Minutes()) != 1 || len(a.Hours()) != 1 || a.Hours()[0] > 7 {
+		t.Errorf("Expected one minute and one hour in 0-7, got %v %v", a.Minutes(), a.Hours())
+	}
+	minutes := map[int]bool{}
+	for i := 0; i < 20; i++ {
+		minutes[parse("H * * * *", fmt.Sprintf("job-%d", i)).Minutes()[0]] = true
+	}
+	if len(minutes) < 5 {
+		t.Errorf("Expected keys to spread over the hour, got minutes %v", minutes)
+	}
+
+	stepped := parse("H/15 * * * *", "report")
+	if len(stepped.Minutes()) != 4 || stepped.Minutes()[0] >= 15 || stepped.Minutes()[1]-stepped.Minutes()[0] != 15 {
+		t.Errorf("Expected every 15 minutes from an offset below 15, got %v", stepped.Minutes())
+	}
+	ranged := parse("0 H(9-17)/4 * * *", "report")
+	for _, hour := range ranged.Hours() {
+		if hour < 9 || hour > 17 {
+			t.Errorf("Expected hours in 9-17, got %v", ranged.Hours())
+		}
+	}
+	if day := parse("0 0 H * *", "report").DayOfMonth()[0]; day < 1 || day > 28 {
+		t.Errorf("Expected a hashed day in 1-28, got %d", day)
+	}
+
+	for _, expr := range []string{"H(5-1) * * * *", "H(0-99) * * * *", "H/0 * * * *", "Hx * * * *"} {
+		if _, err := ParseCronExpression(expr, WithHashKey("report")); err == nil {
+			t.Errorf("Expected %q to fail", expr)
+		}
+	}
+	if _, err := ParseCronExpression("0 0 0 1 1 * H", WithHashKey("report"), WithSeconds()); err == nil {
+		t.Errorf("Expected H in the year field to fail")
+	}
+
+	scheduler := NewCronScheduler()
+	_, _ = scheduler.AddNamedJob("backup", "H H(0-7) * * *", func() {})
+	job, _ := scheduler.GetJob("backup")
+	if !reflect.DeepEqual(job.cron().Minutes(), a.Minutes()) || !reflect.DeepEqual(job.cron().Hours(), a.Hours()) {
+		t.Errorf("Expected the job to hash from its ID, got %v", job.Schedule())
+	}
+	job, _ = scheduler.AddJob("H * * * *", func() {})
+	id := job.ID
+	if want := parse("H * * * *", id).Minutes(); !reflect.DeepEqual(job.cron().Minutes(), want) {
+		t.Errorf("Expected job %s to hash from its generated ID to %v, got %v", id, want, job.cron().Minutes())
+	}
+}
+
+// TestNextRunTimeSeconds tests next-run computation at second resolution.
+func TestNextRunTimeSeconds(t *testing.T) {
+	expr, err := ParseCronExpression("10,40 * * * * *", WithSeconds())
+	if err != nil {
+		t.Fatalf("Failed to parse cron expression: %v", err)
+	}
+
+	from := time.Date(2024, time.May, 1, 12, 0, 10, 500, time.UTC)
+	want := time.Date(2024, time.May, 1, 12, 0, 40, 0, time.UTC)
+	if got := nextRunTime(expr, from); !got.Equal(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	from = time.Date(2024, time.May, 1, 12, 0, 45, 0, time.UTC)
+	want = time.Date(2024, time.May, 1, 12, 1, 10, 0, time.UTC)
+	if got := nextRunTime(expr, from); !got.Equal(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+// TestSchedulerExecutionSeconds tests that a 6-field job fires at second resolution.
+func TestSchedulerExecutionSeconds(t *testing.T) {
+	scheduler := NewCronScheduler()
+
+	done := make(chan struct{}, 1)
+	_, err := scheduler.AddJob("* * * * * *", func() {
+		select {
+		case done <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		t.Fatalf("Failed to add job: %v", err)
+	}
+
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Errorf("Every-second job did not execute in expected time")
+	}
+}
+
+// TestParseCronMacros tests the predefined schedule macros.
+func TestParseCronMacros(t *testing.T) {
+	tests := []struct {
+		macro      string
+		equivalent string
+	}{
+		{"@yearly", "0 0 1 1 *"},
+		{"@annually", "0 0 1 1 *"},
+		{"@monthly", "0 0 1 * *"},
+		{"@weekly", "0 0 * * 0"},
+		{"@daily", "0 0 * * *"},
+		{"@midnight", "0 0 * * *"},
+		{"@hourly", "0 * * * *"},
+		{"@Daily", "0 0 * * *"},
+	}
+
+	for _, test := range tests {
+		got, err := ParseCronExpression(test.macro)
+		if err != nil {
+			t.Errorf("Failed to parse macro %s: %v", test.macro, err)
+			continue
+		}
+		want, _ := ParseCronExpression(test.equivalent)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("Expected %s to equal %s, got %v", test.macro, test.equivalent, got)
+		}
+	}
+
+	if _, err := ParseCronExpression("@fortnightly"); err == nil {
+		t.Errorf("Expected unknown macro to fail")
+	}
+}
+
+// TestRebootJob tests that @reboot jobs run once when the scheduler starts.
+func TestRebootJob(t *testing.T) {
+	scheduler := NewCronScheduler()
+
+	done := make(chan struct{})
+	_, err := scheduler.AddJob("@reboot", func() { close(done) })
+	if err != nil {
+		t.Fatalf("Failed to add @reboot job: %v", err)
+	}
+
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Errorf("@reboot job did not run on Start()")
+	}
+}
+
+// TestEveryInterval tests parsing and execution of @every expressions.
+func TestEveryInterval(t *testing.T) {
+	expr, err := ParseCronExpression("@every 5m30s")
+	if err != nil {
+		t.Fatalf("Failed to parse @every expression: %v", err)
+	}
+	from := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+	if got, want := nextRunTime(expr, from), from.Add(5*time.Minute+30*time.Second); !got.Equal(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	for _, invalid := range []string{"@every", "@every soon", "@every -1m", "@every 0s"} {
+		if _, err := ParseCronExpression(invalid); err == nil {
+			t.Errorf("Expected expression '%s' to fail, but it passed", invalid)
+		}
+	}
+
+	scheduler := NewCronScheduler()
+	var mu sync.Mutex
+	var count int
+	_, err = scheduler.AddJob("@every 100ms", func() {
+		mu.Lock()
+		count++
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatalf("Failed to add job: %v", err)
+	}
+
+	scheduler.Start()
+	time.Sleep(550 * time.Millisecond)
+	scheduler.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if count < 3 || count > 6 {
+		t.Errorf("Expected about 5 runs of a 100ms job in 550ms, got %d", count)
+	}
+}
+
+// TestNextRunTimeFieldArithmetic tests next-run computation for sparse and edge-case schedules.
+func TestNextRunTimeFieldArithmetic(t *testing.T) {
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	if err != nil {
+		t.Skipf("Time zone data not available: %v", err)
+	}
+
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"@yearly", time.Date(2024, time.March, 5, 10, 0, 0, 0, time.UTC), time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"59 23 31 12 *", time.Date(2024, time.December, 31, 23, 59, 0, 0, time.UTC), time.Date(2025, time.December, 31, 23, 59, 0, 0, time.UTC)},
+		{"*/20 * * * * *", time.Date(2024, time.May, 1, 12, 59, 45, 0, time.UTC), time.Date(2024, time.May, 1, 13, 0, 0, 0, time.UTC)},
+		{"0 9 * * Mon-Fri", time.Date(2024, time.May, 3, 9, 0, 0, 0, time.UTC), time.Date(2024, time.May, 6, 9, 0, 0, 0, time.UTC)},
+		// Hourly jobs keep firing across the spring-forward gap.
+		{"0 * * * *", time.Date(2024, time.March, 31, 0, 30, 0, 0, lisbon), time.Date(2024, time.March, 31, 1, 0, 0, 0, time.UTC)},
+		// Feb 31 never exists.
+		{"0 0 31 2 *", time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), time.Time{}},
+	}
+
+	for _, test := range tests {
+		expr, err := ParseCronExpressionMode(test.expr, ParseAuto)
+		if err != nil {
+			t.Fatalf("Failed to parse cron expression %s: %v", test.expr, err)
+		}
+		got := nextRunTime(expr, test.from)
+		if !got.Equal(test.want) {
+			t.Errorf("%s from %v: expected %v, got %v", test.expr, test.from, test.want, got)
+		}
+		if !got.IsZero() && !isTimeMatching(expr, got) {
+			t.Errorf("%s: next run %v does not match the expression", test.expr, got)
+		}
+	}
+}
+
+// TestDSTPolicy tests fire times in the hours skipped and repeated by DST changes.
+func TestDSTPolicy(t *testing.T) {
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	if err != nil {
+		t.Skipf("Time zone data not available: %v", err)
+	}
+
+	// Lisbon skips from 01:00 to 02:00 on 2024-03-31 and repeats 01:00-02:00 on 2024-10-27.
+	tests := []struct {
+		name   string
+		expr   string
+		policy DSTPolicy
+		from   time.Time
+		want   []time.Time
+	}{
+		{"skipped hour fires once, shifted", "30 1 * * *", DSTFireOnce, time.Date(2024, time.March, 30, 12, 0, 0, 0, lisbon), []time.Time{
+			time.Date(2024, time.March, 31, 1, 30, 0, 0, time.UTC), // 02:30 WEST
+			time.Date(2024, time.April, 1, 1, 30, 0, 0, lisbon),
+		}},
+		{"skipped hour with skip policy", "30 1 * * *", DSTSkip, time.Date(2024, time.March, 30, 12, 0, 0, 0, lisbon), []time.Time{
+			time.Date(2024, time.April, 1, 1, 30, 0, 0, lisbon),
+		}},
+		{"several runs in the skipped hour", "0,30 1 * * *", DSTFireOnce, time.Date(2024, time.March, 31, 0, 0, 0, 0, lisbon), []time.Time{
+			time.Date(2024, time.March, 31, 1, 0, 0, 0, time.UTC), // 02:00 WEST
+			time.Date(2024, time.March, 31, 1, 30, 0, 0, time.UTC),
+			time.Date(2024, time.April, 1, 1, 0, 0, 0, lisbon),
+		}},
+		{"hourly jobs keep their cadence", "0 * * * *", DSTFireOnce, time.Date(2024, time.March, 31, 0, 30, 0, 0, lisbon), []time.Time{
+			time.Date(2024, time.March, 31, 1, 0, 0, 0, time.UTC),
+			time.Date(2024, time.March, 31, 2, 0, 0, 0, time.UTC),
+		}},
+		{"repeated hour fires once", "30 1 * * *", DSTFireOnce, time.Date(2024, time.October, 26, 12, 0, 0, 0, lisbon), []time.Time{
+			time.Date(2024, time.October, 27, 0, 30, 0, 0, time.UTC), // first 01:30, WEST
+			time.Date(2024, time.October, 28, 1, 30, 0, 0, lisbon),
+		}},
+		{"hourly jobs run in both occurrences", "30 * * * *", DSTFireOnce, time.Date(2024, time.October, 27, 0, 0, 0, 0, lisbon), []time.Time{
+			time.Date(2024, time.October, 26, 23, 30, 0, 0, time.UTC),
+			time.Date(2024, time.October, 27, 0, 30, 0, 0, time.UTC),
+			time.Date(2024, time.October, 27, 1, 30, 0, 0, time.UTC),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseCronExpression(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expr.DSTPolicy = tt.policy
+			next := tt.from
+			for i, want := range tt.want {
+				next = nextRunTime(expr, next)
+				if !next.Equal(want) {
+					t.Fatalf("Run %d: expected %v, got %v", i, want.In(lisbon), next)
+				}
+			}
+		})
+	}
+
+	scheduler := NewCronSchedulerWithLocation(lisbon, WithDSTPolicy(DSTSkip))
+	_, _ = scheduler.AddNamedJob("early", "30 1 * * *", func() {})
+	runs := scheduler.Simulate(time.Date(2024, time.March, 31, 0, 0, 0, 0, lisbon), time.Date(2024, time.March, 31, 23, 0, 0, 0, lisbon))
+	if len(runs) != 0 {
+		t.Errorf("Expected WithDSTPolicy(DSTSkip) to skip the run, got %v", runs)
+	}
+}
+
+// TestSchedulerQueueOrdering tests that jobs added to a running scheduler are picked up and removed jobs stop firing.
+func TestSchedulerQueueOrdering(t *testing.T) {
+	scheduler := NewCronScheduler()
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	var mu sync.Mutex
+	runs := map[string]int{}
+	record := func(name string) func() {
+		return func() {
+			mu.Lock()
+			runs[name]++
+			mu.Unlock()
+		}
+	}
+
+	// Added after Start, so the loop must be woken from its idle wait.
+	fastJob, _ := scheduler.AddJob("@every 50ms", record("fast"))
+	fastID := fastJob.ID
+	_, _ = scheduler.AddJob("@every 1h", record("slow"))
+
+	time.Sleep(300 * time.Millisecond)
+	if err := scheduler.RemoveJob(fastID); err != nil {
+		t.Fatalf("Failed to remove job: %v", err)
+	}
+	// Let a run dispatched just before removal finish.
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	afterRemove := runs["fast"]
+	mu.Unlock()
+	time.Sleep(200 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if afterRemove < 3 {
+		t.Errorf("Expected the 50ms job to run several times, got %d", afterRemove)
+	}
+	if runs["fast"] != afterRemove {
+		t.Errorf("Expected no runs after removal, got %d more", runs["fast"]-afterRemove)
+	}
+	if runs["slow"] != 0 {
+		t.Errorf("Expected the hourly job not to run, got %d", runs["slow"])
+	}
+}
+
+// TestOnError tests that errors returned by tasks reach the OnError handler.
+func TestOnError(t *testing.T) {
+	scheduler := NewCronScheduler()
+
+	type failure struct {
+		jobID string
+		err   error
+	}
+	failures := make(chan failure, 10)
+	scheduler.OnError(func(jobID string, err error) {
+		failures <- failure{jobID, err}
+	})
+
+	errBoom := errors.New("boom")
+	job, err := scheduler.AddJobWithError("@every 50ms", func() error { return errBoom })
+	if err != nil {
+		t.Fatalf("Failed to add job: %v", err)
+	}
+	_, _ = scheduler.AddJobWithError("@every 50ms", func() error { return nil })
+
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	select {
+	case f := <-failures:
+		if f.jobID != job.ID || !errors.Is(f.err, errBoom) {
+			t.Errorf("Expected failure (%s, %v), got (%s, %v)", job.ID, errBoom, f.jobID, f.err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("OnError handler was not called")
+	}
+}
+
+// TestPanicHandler tests that task panics are recovered and passed to the panic handler.
+func TestPanicHandler(t *testing.T) {
+	scheduler := NewCronScheduler()
+
+	type panicked struct {
+		jobID     string
+		recovered any
+		stack     []byte
+	}
+	panics := make(chan panicked, 10)
+	scheduler.SetPanicHandler(func(jobID string, recovered any, stack []byte) {
+		panics <- panicked{jobID, recovered, stack}
+	})
+
+	job, _ := scheduler.AddJob("@every 50ms", func() { panic("intentional panic for testing") })
+	id := job.ID
+
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	select {
+	case p := <-panics:
+		if p.jobID != id || p.recovered != "intentional panic for testing" {
+			t.Errorf("Unexpected panic report: %s %v", p.jobID, p.recovered)
+		}
+		if len(p.stack) == 0 {
+			t.Errorf("Expected a stack trace")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Panic handler was not called")
+	}
+}
+
+// TestAddTypedJob tests that typed jobs deliver the values of successful
+// runs to their callback or channel.
+func TestAddTypedJob(t *testing.T) {
+	scheduler := NewCronScheduler()
+
+	type report struct{ path string }
+	var gotID string
+	var got report
+	job, err := AddTypedJob(scheduler, "@daily", func(ctx context.Context) (report, error) {
+		return report{path: "/tmp/report.csv"}, nil
+	}, func(jobID string, r report) {
+		gotID, got = jobID, r
+	})
+	if err != nil {
+		t.Fatalf("Failed to add typed job: %v", err)
+	}
+	if err := scheduler.RunNowAndWait(context.Background(), job.ID); err != nil {
+		t.Fatalf("Unexpected run error: %v", err)
+	}
+	if gotID != job.ID || got.path != "/tmp/report.csv" {
+		t.Errorf("Expected result /tmp/report.csv from %s, got %q from %s", job.ID, got.path, gotID)
+	}
+
+	results := make(chan int, 1)
+	errBoom := errors.New("boom")
+	fail := false
+	count, _ := AddTypedJob(scheduler, "@daily", func(ctx context.Context) (int, error) {
+		if fail {
+			return 0, errBoom
+		}
+		return 42, nil
+	}, ResultChan(results))
+	_ = scheduler.RunNowAndWait(context.Background(), count.ID)
+	if v := <-results; v != 42 {
+		t.Errorf("Expected 42 on the result channel, got %d", v)
+	}
+	fail = true
+	if err := scheduler.RunNowAndWait(context.Background(), count.ID); !errors.Is(err, errBoom) {
+		t.Errorf("Expected the task error, got %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no result for a failed run")
+	}
+
+	if _, err := AddTypedJob(scheduler, "bad", func(context.Context) (int, error) { return 0, nil }, nil); err == nil {
+		t.Errorf("Expected an invalid expression to fail")
+	}
+}
+
+// TestLogger tests the structured records written for every run and panic.
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	scheduler := NewCronScheduler(WithLogger(logger), WithLogLevels(slog.LevelDebug, slog.LevelWarn))
+
+	okJob, _ := scheduler.AddJob("@yearly", func() {})
+	okID := okJob.ID
+	errBoom := errors.New("boom")
+	fail, _ := scheduler.AddJobWithError("@yearly", func() error { return errBoom })
+	panicJob, _ := scheduler.AddJob("@yearly", func() { panic("oops") })
+	panicID := panicJob.ID
+	for _, id := range []string{okID, fail.ID, panicID} {
+		_ = scheduler.RunNowAndWait(context.Background(), id)
+	}
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Invalid log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	want := []struct{ msg, level, job, outcome string }{
+		{"job run", "DEBUG", okID, "success"},
+		{"job run", "WARN", fail.ID, "failure"},
+		{"task panicked", "ERROR", panicID, ""},
+		{"job run", "WARN", panicID, "panic"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("Expected %d records, got %d: %s", len(want), len(records), buf.String())
+	}
+	for i, w := range want {
+		r := records[i]
+		if r["msg"] != w.msg || r["level"] != w.level || r["job"] != w.job {
+			t.Errorf("Record %d: expected %s %s for %s, got %v", i, w.level, w.msg, w.job, r)
+		}
+		if w.outcome != "" && r["outcome"] != w.outcome {
+			t.Errorf("Record %d: expected outcome %s, got %v", i, w.outcome, r["outcome"])
+		}
+	}
+	if records[1]["error"] != "boom" || records[0]["duration"] == nil || records[0]["start"] == nil {
+		t.Errorf("Unexpected run record fields: %v, %v", records[0], records[1])
+	}
+	if _, ok := records[0]["scheduled_time"]; ok {
+		t.Errorf("Expected no scheduled_time for a manual run")
+	}
+	if stack, _ := records[2]["stack"].(string); stack == "" {
+		t.Errorf("Expected the panic record to carry a stack trace")
+	}
+}
+
+// TestOverlapPolicies tests how each overlap policy treats a run that is due while another is in progress.
+func TestOverlapPolicies(t *testing.T) {
+	tests := []struct {
+		policy      OverlapPolicy
+		wantRunning int
+		wantQueued  int
+	}{
+		{AllowConcurrent, 3, 0},
+		{SkipIfRunning, 1, 0},
+		{QueueOne, 1, 1},
+		{QueueAll, 1, 2},
+	}
+
+	for _, test := range tests {
+		scheduler := NewCronScheduler()
+		job, _ := scheduler.AddJob("* * * * *", func() {}, WithOverlapPolicy(test.policy))
+
+		scheduler.mutex.Lock()
+		for i := 0; i < 3; i++ {
+			scheduler.tryStart(job, nil)
+		}
+		if job.running != test.wantRunning || len(job.queued) != test.wantQueued {
+			t.Errorf("Policy %d: expected running=%d queued=%d, got running=%d queued=%d",
+				test.policy, test.wantRunning, test.wantQueued, job.running, len(job.queued))
+		}
+		scheduler.mutex.Unlock()
+	}
+}
+
+// TestSkipIfRunning tests that a slow job with SkipIfRunning never overlaps itself.
+func TestSkipIfRunning(t *testing.T) {
+	scheduler := NewCronScheduler()
+
+	var mu sync.Mutex
+	var active, maxActive, runs int
+	_, err := scheduler.AddJob("@every 20ms", func() {
+		mu.Lock()
+		active++
+		runs++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+		time.Sleep(100 * time.Millisecond)
+		mu.Lock()
+		active--
+		mu.Unlock()
+	}, WithOverlapPolicy(SkipIfRunning))
+	if err != nil {
+		t.Fatalf("Failed to add job: %v", err)
+	}
+
+	scheduler.Start()
+	time.Sleep(350 * time.Millisecond)
+	scheduler.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if maxActive != 1 {
+		t.Errorf("Expected at most 1 concurrent run, got %d", maxActive)
+	}
+	if runs < 2 {
+		t.Errorf("Expected the job to run more than once, got %d", runs)
+	}
+}
+
+// TestStopAndWait tests that StopAndWait waits for running tasks and reports the ones that outlive the context.
+func TestStopAndWait(t *testing.T) {
+	t.Run("finished", func(t *testing.T) {
+		scheduler := NewCronScheduler()
+		started := make(chan struct{}, 1)
+		var finished bool
+		var mu sync.Mutex
+		_, _ = scheduler.AddJobContext("@every 20ms", func(ctx context.Context) {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			time.Sleep(100 * time.Millisecond)
+			mu.Lock()
+			finished = ctx.Err() == nil
+			mu.Unlock()
+		}, WithOverlapPolicy(SkipIfRunning))
+
+		scheduler.Start()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := scheduler.StopAndWait(ctx); err != nil {
+			t.Fatalf("Expected StopAndWait to succeed, got %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if !finished {
+			t.Errorf("Expected the running task to finish with a live context")
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		scheduler := NewCronScheduler()
+		started := make(chan struct{})
+		cancelled := make(chan struct{})
+		job, _ := scheduler.AddJobContext("@reboot", func(ctx context.Context) {
+			close(started)
+			<-ctx.Done()
+			close(cancelled)
+		})
+
+		scheduler.Start()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := scheduler.StopAndWait(ctx)
+		var stillRunning *StillRunningError
+		if !errors.As(err, &stillRunning) {
+			t.Fatalf("Expected *StillRunningError, got %v", err)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected error to wrap context.DeadlineExceeded, got %v", err)
+		}
+		if len(stillRunning.JobIDs) != 1 || stillRunning.JobIDs[0] != job.ID {
+			t.Errorf("Expected [%s] still running, got %v", job.ID, stillRunning.JobIDs)
+		}
+
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Errorf("Expected the remaining task's context to be cancelled")
+		}
+	})
+}
+
+// TestListJobInfo tests the structured job snapshots.
+func TestListJobInfo(t *testing.T) {
+	scheduler := NewCronScheduler()
+
+	errBoom := errors.New("boom")
+	failing, _ := scheduler.AddJobWithError("@every 30ms", func() error { return errBoom })
+	dailyJob, _ := scheduler.AddJob("0 6 * * *", func() {})
+	dailyID := dailyJob.ID
+
+	infos := scheduler.ListJobInfo()
+	if len(infos) != 2 || infos[0].ID != failing.ID || infos[1].ID != dailyID {
+		t.Fatalf("Unexpected job infos: %+v", infos)
+	}
+	if infos[1].Expression != "0 6 * * *" {
+		t.Errorf("Expected expression '0 6 * * *', got %q", infos[1].Expression)
+	}
+	if infos[1].NextRun.IsZero() || infos[1].RunCount != 0 || !infos[1].LastRun.IsZero() {
+		t.Errorf("Unexpected state for a job that has not run: %+v", infos[1])
+	}
+
+	scheduler.Start()
+	time.Sleep(150 * time.Millisecond)
+	scheduler.Stop()
+
+	info := scheduler.ListJobInfo()[0]
+	if info.RunCount < 2 {
+		t.Errorf("Expected at least 2 runs, got %d", info.RunCount)
+	}
+	if !errors.Is(info.LastError, errBoom) {
+		t.Errorf("Expected LastError %v, got %v", errBoom, info.LastError)
+	}
+	if info.LastRun.IsZero() {
+		t.Errorf("Expected LastRun to be set")
+	}
+}
+
+// TestLastRunNextRun tests the per-job last and next run accessors.
+func TestLastRunNextRun(t *testing.T) {
+	scheduler := NewCronScheduler()
+	errBoom := errors.New("boom")
+	fail := false
+	job, _ := scheduler.AddJobWithError("0 6 * * *", func() error {
+		if fail {
+			return errBoom
+		}
+		return nil
+	})
+
+	if last, _ := scheduler.LastRun(job.ID); !last.IsZero() {
+		t.Errorf("Expected no last run, got %v", last)
+	}
+	if next := scheduler.NextRun(job.ID); next.IsZero() || next.Hour() != 6 {
+		t.Errorf("Expected the next 06:00, got %v", next)
+	}
+
+	before := time.Now()
+	_ = scheduler.RunNowAndWait(context.Background(), job.ID)
+	if last, outcome := scheduler.LastRun(job.ID); last.Before(before) || outcome != OutcomeSuccess {
+		t.Errorf("Expected a successful run after %v, got %v %v", before, last, outcome)
+	}
+	fail = true
+	_ = scheduler.RunNowAndWait(context.Background(), job.ID)
+	if _, outcome := scheduler.LastRun(job.ID); outcome != OutcomeFailure {
+		t.Errorf("Expected a failed run, got %v", outcome)
+	}
+
+	_ = scheduler.PauseJob(job.ID)
+	if next := scheduler.NextRun(job.ID); !next.IsZero() {
+		t.Errorf("Expected no next run for a paused job, got %v", next)
+	}
+	if last, _ := scheduler.LastRun("missing"); !last.IsZero() || !scheduler.NextRun("missing").IsZero() {
+		t.Errorf("Expected zero times for a missing job")
+	}
+}
+
+// TestStuckJobs tests that runs over the stuck threshold are reported, and
+// cancelled with WithCancelStuck.
+func TestStuckJobs(t *testing.T) {
+	scheduler := NewCronScheduler()
+	events := make(chan JobEvent, 16)
+	defer scheduler.Subscribe(events)()
+
+	release := make(chan struct{})
+	hungJob, _ := scheduler.AddJob("@yearly", func() { <-release }, WithStuckThreshold(20*time.Millisecond))
+	hungID := hungJob.ID
+	var cause error
+	cancelled, _ := scheduler.AddJobContext("@yearly", func(ctx context.Context) {
+		<-ctx.Done()
+		cause = context.Cause(ctx)
+	}, WithStuckThreshold(20*time.Millisecond), WithCancelStuck())
+	quickJob, _ := scheduler.AddJob("@yearly", func() {}, WithStuckThreshold(20*time.Millisecond))
+	quickID := quickJob.ID
+
+	done := make(chan error, 1)
+	go func() { done <- scheduler.RunNowAndWait(context.Background(), hungID) }()
+	if err := scheduler.RunNowAndWait(context.Background(), cancelled.ID); !errors.Is(err, ErrJobStuck) {
+		t.Errorf("Expected the cancelled run to fail with ErrJobStuck, got %v", err)
+	}
+	if !errors.Is(cause, ErrJobStuck) {
+		t.Errorf("Expected the task's context cause to be ErrJobStuck, got %v", cause)
+	}
+	_ = scheduler.RunNowAndWait(context.Background(), quickID)
+	time.Sleep(40 * time.Millisecond)
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("Expected the uncancelled stuck run to succeed, got %v", err)
+	}
+
+	stuck := map[string]bool{}
+	for len(events) > 0 {
+		if e := <-events; e.Type == EventStuck {
+			stuck[e.JobID] = true
+		}
+	}
+	if !stuck[hungID] || !stuck[cancelled.ID] || stuck[quickID] {
+		t.Errorf("Expected stuck events for %s and %s only, got %v", hungID, cancelled.ID, stuck)
+	}
+}
+
+// TestMissedDeadline tests that scheduled runs starting later than the
+// lateness tolerance are reported.
+func TestMissedDeadline(t *testing.T) {
+	scheduler := NewCronScheduler(WithMaxConcurrentJobs(1), WithLatenessTolerance(30*time.Millisecond))
+	events := make(chan JobEvent, 64)
+	defer scheduler.Subscribe(events)()
+
+	blockerJob, _ := scheduler.AddJob("@yearly", func() { time.Sleep(100 * time.Millisecond) })
+	blockerID := blockerJob.ID
+	lateJob, _ := scheduler.AddJob("@every 20ms", func() {}, WithOverlapPolicy(SkipIfRunning))
+	lateID := lateJob.ID
+	scheduler.Start()
+	_ = scheduler.RunNow(blockerID)
+	time.Sleep(150 * time.Millisecond)
+	scheduler.Stop()
+
+	var late *JobEvent
+	for len(events) > 0 {
+		if e := <-events; e.Type == EventMissedDeadline && late == nil {
+			late = &e
+		}
+	}
+	if late == nil {
+		t.Fatalf("Expected an EventMissedDeadline while the only slot was taken")
+	}
+	if late.JobID != lateID || late.Late <= 30*time.Millisecond {
+		t.Errorf("Expected %s to be more than 30ms late, got %s %v late", lateID, late.JobID, late.Late)
+	}
+}
+
+// TestHealth tests the health summary and its HTTP handler.
+func TestHealth(t *testing.T) {
+	scheduler := NewCronScheduler(WithOverdueTolerance(10 * time.Millisecond))
+	job, _ := scheduler.AddJob("@every 1h", func() {})
+	id := job.ID
+	handler := scheduler.HealthHandler()
+
+	check := func(wantCode int) HealthStatus {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		if rec.Code != wantCode {
+			t.Errorf("Expected status %d, got %d", wantCode, rec.Code)
+		}
+		var health HealthStatus
+		if err := json.Unmarshal(rec.Body.Bytes(), &health); err != nil {
+			t.Fatalf("Invalid health body %q: %v", rec.Body.String(), err)
+		}
+		return health
+	}
+
+	if health := check(http.StatusServiceUnavailable); health.Running || health.Healthy {
+		t.Errorf("Expected a stopped scheduler to be unhealthy, got %+v", health)
+	}
+
+	scheduler.Start()
+	defer scheduler.Stop()
+	health := check(http.StatusOK)
+	if !health.Healthy || !health.Running || health.Jobs != 1 || health.LastTick.IsZero() {
+		t.Errorf("Expected a healthy running scheduler, got %+v", health)
+	}
+
+	// Simulate a stuck loop: the queued run is long past due.
+	scheduler.mutex.Lock()
+	job.next = time.Now().Add(-time.Second)
+	scheduler.mutex.Unlock()
+	if health := scheduler.Health(); health.Healthy || !reflect.DeepEqual(health.Overdue, []string{id}) {
+		t.Errorf("Expected %s to be overdue, got %+v", id, health)
+	}
+}
+
+// TestJobInfoNextRunCached tests that snapshots report a cached next run
+// time instead of recomputing it, and no stale one after Stop.
+func TestJobInfoNextRunCached(t *testing.T) {
+	scheduler := NewCronScheduler()
+	job, _ := scheduler.AddJob("@every 1h", func() {})
+	id := job.ID
+
+	first, _ := scheduler.JobInfo(id)
+	time.Sleep(5 * time.Millisecond)
+	second, _ := scheduler.JobInfo(id)
+	if !first.NextRun.Equal(second.NextRun) {
+		t.Errorf("Expected a cached NextRun, got %v then %v", first.NextRun, second.NextRun)
+	}
+
+	fastJob, _ := scheduler.AddJob("@every 20ms", func() {})
+	fastID := fastJob.ID
+	scheduler.Start()
+	time.Sleep(70 * time.Millisecond)
+	scheduler.Stop()
+	time.Sleep(30 * time.Millisecond)
+
+	info, _ := scheduler.JobInfo(fastID)
+	if !info.NextRun.After(time.Now()) {
+		t.Errorf("Expected a future NextRun after Stop, got %v", info.NextRun)
+	}
+}
+
+// TestRunNow tests triggering jobs outside their schedule.
+func TestRunNow(t *testing.T) {
+	scheduler := NewCronScheduler()
+
+	errBoom := errors.New("boom")
+	failing, _ := scheduler.AddJobWithError("@yearly", func() error { return errBoom })
+	if err := scheduler.RunNowAndWait(context.Background(), failing.ID); !errors.Is(err, errBoom) {
+		t.Errorf("Expected RunNowAndWait to return the task error, got %v", err)
+	}
+
+	if err := scheduler.RunNow("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	runs := 0
+	slow := func() {
+		mu.Lock()
+		runs++
+		mu.Unlock()
+		<-release
+	}
+	skipJob, _ := scheduler.AddJob("@yearly", slow, WithOverlapPolicy(SkipIfRunning))
+	skipID := skipJob.ID
+	if err := scheduler.RunNow(skipID); err != nil {
+		t.Fatalf("Expected first RunNow to start, got %v", err)
+	}
+	if err := scheduler.RunNow(skipID); !errors.Is(err, ErrJobSkipped) {
+		t.Errorf("Expected ErrJobSkipped for an overlapping run, got %v", err)
+	}
+
+	queueJob, _ := scheduler.AddJob("@yearly", slow, WithOverlapPolicy(QueueOne))
+	queueID := queueJob.ID
+	scheduler.Start()
+	defer scheduler.Stop()
+	if err := scheduler.RunNow(queueID); err != nil {
+		t.Fatalf("Expected first RunNow to start, got %v", err)
+	}
+	queued := make(chan error, 1)
+	go func() { queued <- scheduler.RunNowAndWait(context.Background(), queueID) }()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	select {
+	case err := <-queued:
+		if err != nil {
+			t.Errorf("Expected queued run to succeed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Queued run did not finish")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if runs != 3 {
+		t.Errorf("Expected 3 runs (1 skip-policy, 2 queue-policy), got %d", runs)
+	}
+}
+
+// TestRetryPolicy tests retry delays and that failing tasks are retried before reporting.
+func TestRetryPolicy(t *testing.T) {
+	exponential := RetryPolicy{Backoff: ExponentialBackoff, Delay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+	for attempt, want := range map[int]time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 3: 40 * time.Millisecond, 4: 50 * time.Millisecond, 10: 50 * time.Millisecond} {
+		if got := exponential.delay(attempt); got != want {
+			t.Errorf("Exponential delay for attempt %d: expected %v, got %v", attempt, want, got)
+		}
+	}
+	fixed := RetryPolicy{Delay: 10 * time.Millisecond, Jitter: 5 * time.Millisecond}
+	for attempt := 1; attempt < 5; attempt++ {
+		if got := fixed.delay(attempt); got < 10*time.Millisecond || got >= 15*time.Millisecond {
+			t.Errorf("Fixed delay with jitter for attempt %d out of range: %v", attempt, got)
+		}
+	}
+
+	scheduler := NewCronScheduler()
+	var reported []error
+	var mu sync.Mutex
+	scheduler.OnError(func(jobID string, err error) {
+		mu.Lock()
+		reported = append(reported, err)
+		mu.Unlock()
+	})
+
+	errFlaky := errors.New("flaky")
+	newFlaky := func(failures int) (func() error, *int) {
+		attempts := 0
+		return func() error {
+			attempts++
+			if attempts <= failures {
+				return errFlaky
+			}
+			return nil
+		}, &attempts
+	}
+
+	task, attempts := newFlaky(2)
+	job, _ := scheduler.AddJobWithError("@yearly", task, WithRetry(RetryPolicy{MaxAttempts: 3, Delay: time.Millisecond}))
+	if err := scheduler.RunNowAndWait(context.Background(), job.ID); err != nil {
+		t.Errorf("Expected the run to succeed on the third attempt, got %v", err)
+	}
+	if *attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", *attempts)
+	}
+
+	task, attempts = newFlaky(5)
+	job, _ = scheduler.AddJobWithError("@yearly", task, WithRetry(RetryPolicy{MaxAttempts: 2, Delay: time.Millisecond}))
+	if err := scheduler.RunNowAndWait(context.Background(), job.ID); !errors.Is(err, errFlaky) {
+		t.Errorf("Expected the run to fail after retries, got %v", err)
+	}
+	if *attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", *attempts)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 1 {
+		t.Errorf("Expected only the final failure to be reported, got %d reports", len(reported))
+	}
+}
+
+// TestJobTimeout tests that attempts exceeding the job timeout fail with ErrJobTimeout.
+func TestJobTimeout(t *testing.T) {
+	scheduler := NewCronScheduler()
+
+	var deadlineSet bool
+	job, _ := scheduler.AddJobContext("@yearly", func(ctx context.Context) {
+		_, deadlineSet = ctx.Deadline()
+		<-ctx.Done()
+	}, WithTimeout(20*time.Millisecond))
+	id := job.ID
+	if err := scheduler.RunNowAndWait(context.Background(), id); !errors.Is(err, ErrJobTimeout) {
+		t.Errorf("Expected ErrJobTimeout, got %v", err)
+	}
+	if !deadlineSet {
+		t.Errorf("Expected the task context to have a deadline")
+	}
+
+	// A task that ignores its context still fails once it overruns.
+	job, _ = scheduler.AddJob("@yearly", func() { time.Sleep(40 * time.Millisecond) }, WithTimeout(10*time.Millisecond))
+	id = job.ID
+	if err := scheduler.RunNowAndWait(context.Background(), id); !errors.Is(err, ErrJobTimeout) {
+		t.Errorf("Expected ErrJobTimeout for a task ignoring its context, got %v", err)
+	}
+
+	job, _ = scheduler.AddJob("@yearly", func() {}, WithTimeout(time.Second))
+	id = job.ID
+	if err := scheduler.RunNowAndWait(context.Background(), id); err != nil {
+		t.Errorf("Expected a fast task to succeed, got %v", err)
+	}
+
+	// A timed-out run drops the run queued behind it.
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	job, _ = scheduler.AddJobContext("@yearly", func(ctx context.Context) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		<-release
+	}, WithTimeout(20*time.Millisecond), WithOverlapPolicy(QueueOne), WithDropQueuedOnTimeout())
+	id = job.ID
+	first := make(chan error, 1)
+	go func() { first <- scheduler.RunNowAndWait(context.Background(), id) }()
+	<-started
+	queued := make(chan error, 1)
+	go func() { queued <- scheduler.RunNowAndWait(context.Background(), id) }()
+	time.Sleep(30 * time.Millisecond)
+	close(release)
+	if err := <-first; !errors.Is(err, ErrJobTimeout) {
+		t.Errorf("Expected the first run to time out, got %v", err)
+	}
+	if err := <-queued; !errors.Is(err, ErrJobSkipped) {
+		t.Errorf("Expected the queued run to be dropped, got %v", err)
+	}
+}
+
+// TestNextRuns tests CronExpression.Next and previewing upcoming runs of a job.
+func TestNextRuns(t *testing.T) {
+	expr, _ := ParseCronExpression("0 9 * * Mon")
+	from := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	if got, want := expr.Next(from), time.Date(2024, time.May, 6, 9, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Expected Next %v, got %v", want, got)
+	}
+
+	scheduler := NewCronSchedulerWithLocation(time.UTC)
+	job, _ := scheduler.AddJob("*/15 * * * *", func() {})
+	id := job.ID
+	runs, err := scheduler.NextRuns(id, 5)
+	if err != nil {
+		t.Fatalf("Failed to get next runs: %v", err)
+	}
+	if len(runs) != 5 {
+		t.Fatalf("Expected 5 runs, got %d", len(runs))
+	}
+	for i, run := range runs {
+		if run.Minute()%15 != 0 || run.Second() != 0 {
+			t.Errorf("Run %d at %v does not match */15", i, run)
+		}
+		if i > 0 && run.Sub(runs[i-1]) != 15*time.Minute {
+			t.Errorf("Expected runs 15 minutes apart, got %v", run.Sub(runs[i-1]))
+		}
+	}
+
+	rebootJob, _ := scheduler.AddJob("@reboot", func() {})
+	rebootID := rebootJob.ID
+	if runs, _ := scheduler.NextRuns(rebootID, 3); len(runs) != 0 {
+		t.Errorf("Expected no upcoming runs for @reboot, got %v", runs)
+	}
+	for _, n := range []int{0, -1} {
+		if runs, err := scheduler.NextRuns(id, n); runs != nil || err != nil {
+			t.Errorf("Expected no runs for n=%d, got %v, %v", n, runs, err)
+		}
+	}
+
+	if _, err := scheduler.NextRuns("missing", 3); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+}
+
+// TestHistory tests the per-job ring buffer of finished runs.
+func TestHistory(t *testing.T) {
+	scheduler := NewCronScheduler(WithHistorySize(3))
+	scheduler.SetPanicHandler(func(string, any, []byte) {})
+
+	results := []error{nil, errors.New("boom"), nil, nil}
+	run := 0
+	job, _ := scheduler.AddJobWithError("@yearly", func() error {
+		run++
+		switch run {
+		case 5:
+			panic("intentional panic for testing")
+		case 6:
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		}
+		return results[run-1]
+	}, WithTimeout(10*time.Millisecond))
+
+	for i := 0; i < 6; i++ {
+		_ = scheduler.RunNowAndWait(context.Background(), job.ID)
+	}
+
+	history, err := scheduler.History(job.ID)
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	want := []RunOutcome{OutcomeSuccess, OutcomePanic, OutcomeTimeout}
+	if len(history) != len(want) {
+		t.Fatalf("Expected %d records, got %d", len(want), len(history))
+	}
+	for i, record := range history {
+		if record.Outcome != want[i] {
+			t.Errorf("Record %d: expected outcome %s, got %s (%v)", i, want[i], record.Outcome, record.Err)
+		}
+		if record.End.Before(record.Start) || record.Duration != record.End.Sub(record.Start) {
+			t.Errorf("Record %d has inconsistent timing: %+v", i, record)
+		}
+		if i > 0 && record.Start.Before(history[i-1].Start) {
+			t.Errorf("Expected records oldest first")
+		}
+	}
+
+	if _, err := scheduler.History("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+}
+
+// TestFileStore tests saving, loading and deleting job records in a JSON file.
+func TestFileStore(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "jobs.json"))
+
+	if records, err := store.Load(); err != nil || len(records) != 0 {
+		t.Fatalf("Expected an empty store, got %v, %v", records, err)
+	}
+	_ = store.Save(JobRecord{Name: "b", Expression: "0 * * * *"})
+	_ = store.Save(JobRecord{Name: "a", Expression: "@daily", Metadata: map[string]string{"owner": "ops"}})
+	_ = store.Save(JobRecord{Name: "b", Expression: "30 * * * *"})
+
+	records, err := store.Load()
+	if err != nil {
+		t.Fatalf("Failed to load records: %v", err)
+	}
+	if len(records) != 2 || records[0].Name != "a" || records[1].Expression != "30 * * * *" || records[0].Metadata["owner"] != "ops" {
+		t.Errorf("Unexpected records: %+v", records)
+	}
+
+	if err := store.Delete("a"); err != nil {
+		t.Fatalf("Failed to delete record: %v", err)
+	}
+	if err := store.Delete("missing"); err != nil {
+		t.Errorf("Expected deleting a missing record to succeed, got %v", err)
+	}
+	if records, _ := store.Load(); len(records) != 1 || records[0].Name != "b" {
+		t.Errorf("Unexpected records after delete: %+v", records)
+	}
+}
+
+// TestSchedulerStoreRehydration tests that named jobs survive a scheduler restart through the store.
+func TestSchedulerStoreRehydration(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "jobs.json"))
+
+	first := NewCronScheduler(WithStore(store))
+	if _, err := first.AddNamedJob("report", "0 6 * * *", func() {}, WithMetadata(map[string]string{"team": "billing"})); err != nil {
+		t.Fatalf("Failed to add named job: %v", err)
+	}
+	_, _ = first.AddNamedJob("cleanup", "@hourly", func() {})
+	_, _ = first.AddNamedJob("orphan", "@daily", func() {})
+	_ = first.RemoveJob("cleanup")
+
+	ran := make(chan struct{}, 1)
+	counting := &countingStore{JobStore: store}
+	second := NewCronScheduler(WithStore(counting))
+	second.RegisterTask("report", func(ctx context.Context) error {
+		ran <- struct{}{}
+		return nil
+	})
+	second.RegisterTask("cleanup", func(ctx context.Context) error { return nil })
+	second.Start()
+	defer second.Stop()
+
+	infos := second.ListJobInfo()
+	if len(infos) != 1 || infos[0].ID != "report" || infos[0].Expression != "0 6 * * *" || infos[0].Metadata["team"] != "billing" {
+		t.Fatalf("Expected only the registered, non-removed job to be restored, got %+v", infos)
+	}
+	if loads := counting.count(); loads != 1 {
+		t.Errorf("Expected the store to be loaded once on Start, got %d loads", loads)
+	}
+	if err := second.RunNowAndWait(context.Background(), "report"); err != nil {
+		t.Fatalf("Failed to run restored job: %v", err)
+	}
+	<-ran
+}
+
+// countingStore is a JobStore counting its loads.
+type countingStore struct {
+	JobStore
+	mu    sync.Mutex
+	loads int
+}
+
+func (s *countingStore) Load() ([]JobRecord, error) {
+	s.mu.Lock()
+	s.loads++
+	s.mu.Unlock()
+	return s.JobStore.Load()
+}
+
+func (s *countingStore) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loads
+}
+
+// TestAtLeastOnce tests that WithAtLeastOnce records runs in the store while
+// they are in progress, and that runs a previous process left unfinished
+// are rerun or reported on Start.
+func TestAtLeastOnce(t *testing.T) {
+	pending := func(store JobStore, name string) []time.Time {
+		record, _, err := findRecord(store, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return record.Pending
+	}
+	waitPending := func(store JobStore, name string, want int) []time.Time {
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			got := pending(store, name)
+			if len(got) == want || time.Now().After(deadline) {
+				return got
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	t.Run("record", func(t *testing.T) {
+		store := NewFileStore(filepath.Join(t.TempDir(), "jobs.json"))
+		scheduler := NewCronScheduler(WithStore(store))
+		started, release := make(chan time.Time, 1), make(chan struct{})
+		var once sync.Once
+		_, err := scheduler.AddNamedJob("billing", "* * * * * *", func() {
+			once.Do(func() {
+				started <- time.Now()
+				<-release
+			})
+		}, WithAtLeastOnce(RerunInterrupted), WithOverlapPolicy(SkipIfRunning))
+		if err != nil {
+			t.Fatal(err)
+		}
+		scheduler.Start()
+		defer scheduler.Stop()
+		select {
+		case <-started:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected the job to run")
+		}
+		if got := pending(store, "billing"); len(got) != 1 || got[0].Nanosecond() != 0 {
+			t.Errorf("Expected the run in progress to be recorded, got %v", got)
+		}
+		close(release)
+		if got := waitPending(store, "billing", 0); len(got) != 0 {
+			t.Errorf("Expected the finished run to be cleared, got %v", got)
+		}
+	})
+
+	interrupted := []time.Time{
+		time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC),
+	}
+	t.Run("rerun", func(t *testing.T) {
+		store := NewFileStore(filepath.Join(t.TempDir(), "jobs.json"))
+		if err := store.Save(JobRecord{Name: "billing", Expression: "0 0 1 1 *", Pending: interrupted}); err != nil {
+			t.Fatal(err)
+		}
+		var mu sync.Mutex
+		var reruns []time.Time
+		scheduler := NewCronScheduler(WithStore(store))
+		scheduler.RegisterTask("billing", func(ctx context.Context) error { return nil },
+			WithAtLeastOnce(RerunInterrupted),
+			WithOnComplete(func(result RunResult) {
+				mu.Lock()
+				reruns = append(reruns, result.Scheduled)
+				mu.Unlock()
+			}))
+		scheduler.Start()
+		defer scheduler.Stop()
+		waitPending(store, "billing", 0)
+		mu.Lock()
+		defer mu.Unlock()
+		if !reflect.DeepEqual(reruns, interrupted) {
+			t.Errorf("Expected the interrupted runs to be rerun in order, got %v", reruns)
+		}
+	})
+
+	t.Run("skipped", func(t *testing.T) {
+		store := NewFileStore(filepath.Join(t.TempDir(), "jobs.json"))
+		if err := store.Save(JobRecord{Name: "billing", Expression: "0 0 1 1 *", Pending: interrupted[:1]}); err != nil {
+			t.Fatal(err)
+		}
+		var mu sync.Mutex
+		var reruns []time.Time
+		add := func(scheduler *CronScheduler, task func(), opts ...JobOption) {
+			opts = append(opts, WithAtLeastOnce(RerunInterrupted), WithOverlapPolicy(SkipIfRunning),
+				WithOnComplete(func(result RunResult) {
+					mu.Lock()
+					if !result.Scheduled.IsZero() {
+						reruns = append(reruns, result.Scheduled)
+					}
+					mu.Unlock()
+				}))
+			if _, err := scheduler.AddNamedJob("billing", "0 0 1 1 *", task, opts...); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// The run on start is still going when the rerun comes, so the
+		// rerun is dropped and must stay recorded.
+		first := NewCronScheduler(WithStore(store))
+		events := make(chan JobEvent, 10)
+		first.Subscribe(events)
+		release := make(chan struct{})
+		add(first, func() { <-release }, WithRunOnStart())
+		first.Start()
+		deadline := time.After(2 * time.Second)
+	wait:
+		for {
+			select {
+			case e := <-events:
+				if e.Type == EventSkipped {
+					break wait
+				}
+			case <-deadline:
+				t.Fatal("Expected the rerun to be dropped")
+			}
+		}
+		close(release)
+		first.Stop()
+		if got := pending(store, "billing"); !reflect.DeepEqual(got, interrupted[:1]) {
+			t.Errorf("Expected the dropped rerun to stay recorded, got %v", got)
+		}
+
+		second := NewCronScheduler(WithStore(store), WithLatenessTolerance(time.Second))
+		events = make(chan JobEvent, 10)
+		second.Subscribe(events)
+		add(second, func() {})
+		second.Start()
+		defer second.Stop()
+		waitPending(store, "billing", 0)
+		mu.Lock()
+		if !reflect.DeepEqual(reruns, interrupted[:1]) {
+			t.Errorf("Expected the run to be rerun on the next start, got %v", reruns)
+		}
+		mu.Unlock()
+		for len(events) > 0 {
+			if e := <-events; e.Type == EventMissedDeadline {
+				t.Errorf("Expected no missed deadline for a rerun, got %+v", e)
+			}
+		}
+	})
+
+	t.Run("discard", func(t *testing.T) {
+		store := NewFileStore(filepath.Join(t.TempDir(), "jobs.json"))
+		if err := store.Save(JobRecord{Name: "billing", Expression: "0 0 1 1 *", Pending: interrupted[:1]}); err != nil {
+			t.Fatal(err)
+		}
+		scheduler := NewCronScheduler(WithStore(store))
+		errs := make(chan error, 2)
+		scheduler.OnError(func(jobID string, err error) { errs <- err })
+		ran := false
+		if _, err := scheduler.AddNamedJob("billing", "0 0 1 1 *", func() { ran = true }, WithAtLeastOnce(DiscardInterrupted)); err != nil {
+			t.Fatal(err)
+		}
+		scheduler.Start()
+		defer scheduler.Stop()
+		select {
+		case err := <-errs:
+			if !errors.Is(err, ErrRunInterrupted) {
+				t.Errorf("Expected ErrRunInterrupted, got %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected the interrupted run to be reported")
+		}
+		if got := waitPending(store, "billing", 0); len(got) != 0 || ran {
+			t.Errorf("Expected the interrupted run to be cleared without running, got %v (ran %v)", got, ran)
+		}
+	})
+}
+
+// TestAddRegisteredJob tests that a registered task can be scheduled by name, with its options.
+func TestAddRegisteredJob(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "jobs.json"))
+	scheduler := NewCronScheduler(WithStore(store))
+	scheduler.RegisterTask("report", func(ctx context.Context) error { return nil }, WithMetadata(map[string]string{"team": "billing"}))
+
+	if _, err := scheduler.AddRegisteredJob("missing", "@daily"); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound, got %v", err)
+	}
+	if _, err := scheduler.AddRegisteredJob("report", "0 6 * * *"); err != nil {
+		t.Fatalf("Failed to add registered job: %v", err)
+	}
+	if _, err := scheduler.AddRegisteredJob("report", "0 7 * * *"); !errors.Is(err, ErrDuplicateJobID) {
+		t.Errorf("Expected ErrDuplicateJobID, got %v", err)
+	}
+
+	info, err := scheduler.JobInfo("report")
+	if err != nil || info.Expression != "0 6 * * *" || info.Metadata["team"] != "billing" {
+		t.Errorf("Unexpected job info: %+v, %v", info, err)
+	}
+	if records, _ := store.Load(); len(records) != 1 || records[0].Name != "report" {
+		t.Errorf("Expected the job to be saved, got %+v", records)
+	}
+}
+
+// TestWithExprFromEnv tests that a job's expression can be read from an environment variable.
+func TestWithExprFromEnv(t *testing.T) {
+	scheduler := NewCronScheduler()
+
+	t.Setenv("REPORT_CRON", "")
+	job, err := scheduler.AddJob("", func() {}, WithExprFromEnv("REPORT_CRON", "0 0 6 * * *"))
+	if err != nil {
+		t.Fatalf("Failed to add job: %v", err)
+	}
+	if job.Expression() != "0 0 6 * * *" {
+		t.Errorf("Expected the fallback expression, got %q", job.Expression())
+	}
+
+	t.Setenv("REPORT_CRON", "*/5 * * * *")
+	job, err = scheduler.AddJob("@daily", func() {}, WithExprFromEnv("REPORT_CRON", "0 0 6 * * *"))
+	if err != nil {
+		t.Fatalf("Failed to add job: %v", err)
+	}
+	if job.Expression() != "*/5 * * * *" {
+		t.Errorf("Expected the expression from the environment, got %q", job.Expression())
+	}
+
+	t.Setenv("REPORT_CRON", "61 * * * *")
+	_, err = scheduler.AddJob("", func() {}, WithExprFromEnv("REPORT_CRON", "0 0 6 * * *"))
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || !strings.HasPrefix(err.Error(), "REPORT_CRON: ") {
+		t.Errorf("Expected a field error naming the variable, got %v", err)
+	}
+}
+
+// TestLoadConfig tests that job configurations are read from YAML and JSON files.
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "jobs.yaml")
+	_ = os.WriteFile(yamlPath, []byte(`
+jobs:
+  - name: report
+    cron: "0 6 * * *"
+    timeout: 5m
+    retries: 2
+    retry_delay: 30s
+    overlap: skip
+  - name: cleanup
+    task: purge
+    cron: "@hourly"
+    enabled: false
+`), 0o644)
+	jsonPath := filepath.Join(dir, "jobs.json")
+	_ = os.WriteFile(jsonPath, []byte(`{"jobs": [{"name": "report", "cron": "0 6 * * *", "timeout": "5m", "retries": 2, "retry_delay": "30s", "overlap": "skip"}, {"name": "cleanup", "task": "purge", "cron": "@hourly", "enabled": false}]}`), 0o644)
+
+	for _, path := range []string{yamlPath, jsonPath} {
+		config, err := LoadConfig(path)
+		if err != nil {
+			t.Fatalf("Failed to load %s: %v", path, err)
+		}
+		if len(config.Jobs) != 2 {
+			t.Fatalf("Expected 2 jobs in %s, got %+v", path, config.Jobs)
+		}
+		report, cleanup := config.Jobs[0], config.Jobs[1]
+		if report.Name != "report" || report.Cron != "0 6 * * *" || report.Timeout != "5m" || report.Retries != 2 || report.RetryDelay != "30s" || report.Overlap != "skip" || report.Enabled != nil {
+			t.Errorf("Unexpected job in %s: %+v", path, report)
+		}
+		if cleanup.Task != "purge" || cleanup.Enabled == nil || *cleanup.Enabled {
+			t.Errorf("Unexpected job in %s: %+v", path, cleanup)
+		}
+	}
+
+	unknown := filepath.Join(dir, "unknown.yaml")
+	_ = os.WriteFile(unknown, []byte("jobs:\n  - name: report\n    schedule: \"@daily\"\n"), 0o644)
+	if _, err := LoadConfig(unknown); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+}
+
+// TestApplyConfig tests that applying a config adds, replaces and removes configured jobs.
+func TestApplyConfig(t *testing.T) {
+	scheduler := NewCronScheduler()
+	scheduler.RegisterTask("report", func(ctx context.Context) error { return nil })
+	scheduler.RegisterTask("purge", func(ctx context.Context) error { return nil })
+	_, _ = scheduler.AddNamedJob("manual", "@daily", func() {})
+	disabled := false
+
+	expressions := func() map[string]string {
+		got := make(map[string]string)
+		for _, info := range scheduler.ListJobInfo() {
+			got[info.ID] = info.Expression
+		}
+		return got
+	}
+
+	err := scheduler.ApplyConfig(&Config{Jobs: []JobConfig{
+		{Name: "report", Cron: "0 6 * * *", Timeout: "1m"},
+		{Name: "cleanup", Task: "purge", Cron: "@hourly"},
+		{Name: "old", Task: "purge", Cron: "@weekly"},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to apply config: %v", err)
+	}
+	job, _ := scheduler.GetJob("report")
+	if job.timeout != time.Minute {
+		t.Errorf("Expected the configured timeout, got %v", job.timeout)
+	}
+	if got := expressions(); !reflect.DeepEqual(got, map[string]string{"manual": "@daily", "report": "0 6 * * *", "cleanup": "@hourly", "old": "@weekly"}) {
+		t.Errorf("Unexpected jobs after first config: %v", got)
+	}
+
+	err = scheduler.ApplyConfig(&Config{Jobs: []JobConfig{
+		{Name: "report", Cron: "0 6 * * *", Timeout: "1m"},
+		{Name: "cleanup", Task: "purge", Cron: "*/5 * * * *"},
+		{Name: "old", Task: "purge", Cron: "@weekly", Enabled: &disabled},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to reapply config: %v", err)
+	}
+	if kept, _ := scheduler.GetJob("report"); kept != job {
+		t.Error("Expected an unchanged job to be kept")
+	}
+	if got := expressions(); !reflect.DeepEqual(got, map[string]string{"manual": "@daily", "report": "0 6 * * *", "cleanup": "*/5 * * * *"}) {
+		t.Errorf("Unexpected jobs after second config: %v", got)
+	}
+
+	invalid := []JobConfig{
+		{Name: "report", Cron: "61 * * * *"},
+		{Name: "report", Task: "missing", Cron: "@daily"},
+		{Name: "report", Cron: "@daily", Timeout: "soon"},
+		{Name: "report", Cron: "@daily", Overlap: "sometimes"},
+		{Cron: "@daily"},
+	}
+	for _, jc := range invalid {
+		if err := scheduler.ApplyConfig(&Config{Jobs: []JobConfig{jc}}); err == nil {
+			t.Errorf("Expected an error for %+v", jc)
+		}
+	}
+	if err := scheduler.ApplyConfig(&Config{Jobs: []JobConfig{{Name: "a", Task: "purge", Cron: "@daily"}, {Name: "a", Task: "purge", Cron: "@hourly"}}}); !errors.Is(err, ErrDuplicateJobID) {
+		t.Errorf("Expected ErrDuplicateJobID for a repeated name, got %v", err)
+	}
+	if err := scheduler.ApplyConfig(&Config{Jobs: []JobConfig{{Name: "manual", Task: "purge", Cron: "@daily"}}}); !errors.Is(err, ErrDuplicateJobID) {
+		t.Errorf("Expected ErrDuplicateJobID for a job added in code, got %v", err)
+	}
+
+	if err := scheduler.ApplyConfig(&Config{}); err != nil {
+		t.Fatalf("Failed to apply empty config: %v", err)
+	}
+	if got := expressions(); !reflect.DeepEqual(got, map[string]string{"manual": "@daily"}) {
+		t.Errorf("Expected only the job added in code to remain, got %v", got)
+	}
+}
+
+// TestWatchConfig tests that changes to a watched config file are applied.
+func TestWatchConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.yaml")
+	_ = os.WriteFile(path, []byte("jobs:\n  - name: report\n    cron: \"@daily\"\n"), 0o644)
+
+	scheduler := NewCronScheduler()
+	scheduler.RegisterTask("report", func(ctx context.Context) error { return nil })
+	errs := make(chan error, 10)
+	scheduler.OnError(func(jobID string, err error) { errs <- err })
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := scheduler.WatchConfig(ctx, filepath.Join(t.TempDir(), "missing.yaml"), time.Millisecond); err == nil {
+		t.Error("Expected an error for a missing config file")
+	}
+	if err := scheduler.WatchConfig(ctx, path, 10*time.Millisecond); err != nil {
+		t.Fatalf("Failed to watch config: %v", err)
+	}
+	if info, err := scheduler.JobInfo("report"); err != nil || info.Expression != "@daily" {
+		t.Fatalf("Expected the initial config to be applied, got %+v, %v", info, err)
+	}
+
+	_ = os.WriteFile(path, []byte("jobs: [oops"), 0o644)
+	select {
+	case <-errs:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected an invalid config to be reported")
+	}
+
+	_ = os.WriteFile(path, []byte("jobs:\n  - name: report\n    cron: \"@hourly\"\n"), 0o644)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if info, err := scheduler.JobInfo("report"); err == nil && info.Expression == "@hourly" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the changed config to be applied")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestCatchUpPolicy tests that persisted jobs replay the runs they missed while the process was down.
+func TestCatchUpPolicy(t *testing.T) {
+	tests := []struct {
+		policy CatchUpPolicy
+		want   int
+	}{
+		{IgnoreMissed, 0},
+		{RunOnceOnStartupIfMissed, 1},
+		{RunAllMissed, 3},
+	}
+	for _, tt := range tests {
+		store := NewFileStore(filepath.Join(t.TempDir(), "jobs.json"))
+		lastSuccess := time.Now().Add(-3*time.Hour - 30*time.Minute)
+		_ = store.Save(JobRecord{Name: "report", Expression: "@every 1h", LastSuccess: lastSuccess})
+
+		var mu sync.Mutex
+		runs := 0
+		scheduler := NewCronScheduler(WithStore(store))
+		scheduler.RegisterTask("report", func(ctx context.Context) error {
+			mu.Lock()
+			runs++
+			mu.Unlock()
+			return nil
+		}, WithCatchUp(tt.policy))
+		scheduler.Start()
+		time.Sleep(100 * time.Millisecond)
+		scheduler.Stop()
+
+		mu.Lock()
+		if runs != tt.want {
+			t.Errorf("Policy %d: expected %d catch-up runs, got %d", tt.policy, tt.want, runs)
+		}
+		mu.Unlock()
+
+		records, _ := store.Load()
+		if tt.want > 0 && (len(records) != 1 || !records[0].LastSuccess.After(lastSuccess)) {
+			t.Errorf("Policy %d: expected the last successful run to be saved, got %+v", tt.policy, records)
+		}
+	}
+}
+
+// fakeRedis emulates the scripts RedisLocker sends, ignoring expiry.
+type fakeRedis struct {
+	mu   sync.Mutex
+	keys map[string]string
+}
+
+func (r *fakeRedis) Do(ctx context.Context, args ...any) (any, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, token := args[3].(string), args[4].(string)
+	switch args[1] {
+	case redisLockScript:
+		if _, ok := r.keys[key]; ok {
+			return int64(0), nil
+		}
+		r.keys[key] = token
+		return int64(1), nil
+	case redisUnlockScript:
+		if r.keys[key] != token {
+			return int64(0), nil
+		}
+		delete(r.keys, key)
+		return int64(1), nil
+	}
+	return nil, fmt.Errorf("unexpected command %v", args)
+}
+
+// TestLocker tests that only the instance holding a job's lock runs it.
+func TestLocker(t *testing.T) {
+	redis := &fakeRedis{keys: make(map[string]string)}
+	first := NewCronScheduler(WithLocker(NewRedisLocker(redis, "cron:")))
+	second := NewCronScheduler(WithLocker(NewRedisLocker(redis, "cron:")))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	_, _ = first.AddNamedJob("report", "@yearly", func() {
+		close(started)
+		<-release
+	})
+	_, _ = second.AddNamedJob("report", "@yearly", func() {})
+	first.Start()
+	defer first.Stop()
+	second.Start()
+	defer second.Stop()
+
+	if err := first.RunNow("report"); err != nil {
+		t.Fatalf("Failed to trigger job: %v", err)
+	}
+	<-started
+	if err := second.RunNowAndWait(context.Background(), "report"); !errors.Is(err, ErrJobLocked) {
+		t.Errorf("Expected ErrJobLocked while the other instance runs the job, got %v", err)
+	}
+	close(release)
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if err := second.RunNowAndWait(context.Background(), "report"); err == nil {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("Expected the lock to be released after the run finished")
+}
+
+// TestLockerJitter tests that an occurrence run by one instance is not run
+// again by another firing it later, as a jittered one does.
+func TestLockerJitter(t *testing.T) {
+	redis := &fakeRedis{keys: make(map[string]string)}
+	var mu sync.Mutex
+	runs := make(map[time.Time]int)
+	onComplete := WithOnComplete(func(r RunResult) {
+		mu.Lock()
+		runs[r.Scheduled]++
+		mu.Unlock()
+	})
+	first := NewCronScheduler(WithLocker(NewRedisLocker(redis, "cron:")))
+	second := NewCronScheduler(WithLocker(NewRedisLocker(redis, "cron:")))
+	_, _ = first.AddNamedJob("sync", "* * * * * *", func() {}, onComplete)
+	_, _ = second.AddNamedJob("sync", "* * * * * *", func() {}, onComplete, WithJitter(500*time.Millisecond))
+	first.Start()
+	second.Start()
+	time.Sleep(2500 * time.Millisecond)
+	first.Stop()
+	second.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(runs) == 0 {
+		t.Fatal("Expected the job to run")
+	}
+	for tick, n := range runs {
+		if n != 1 {
+			t.Errorf("Expected the occurrence at %v to run once, got %d runs", tick, n)
+		}
+	}
+}
+
+// TestLockerEvery tests that instances queueing an "@every" job at different
+// times still run each interval once between them.
+func TestLockerEvery(t *testing.T) {
+	redis := &fakeRedis{keys: make(map[string]string)}
+	const interval = 200 * time.Millisecond
+	var mu sync.Mutex
+	runs := make(map[time.Time]int)
+	onComplete := WithOnComplete(func(r RunResult) {
+		mu.Lock()
+		runs[r.Scheduled.Truncate(interval)]++
+		mu.Unlock()
+	})
+	first := NewCronScheduler(WithLocker(NewRedisLocker(redis, "cron:")))
+	second := NewCronScheduler(WithLocker(NewRedisLocker(redis, "cron:")))
+	_, _ = first.AddNamedJob("sync", "@every 200ms", func() {}, onComplete)
+	_, _ = second.AddNamedJob("sync", "@every 200ms", func() {}, onComplete)
+	first.Start()
+	time.Sleep(70 * time.Millisecond)
+	second.Start()
+	time.Sleep(time.Second)
+	first.Stop()
+	second.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(runs) == 0 {
+		t.Fatal("Expected the job to run")
+	}
+	for slot, n := range runs {
+		if n != 1 {
+			t.Errorf("Expected the interval at %v to run once, got %d runs", slot, n)
+		}
+	}
+}
+
+// TestDependentJobs tests that a job runs only after its dependencies succeed in the same tick.
+func TestDependentJobs(t *testing.T) {
+	scheduler := NewCronScheduler()
+
+	var mu sync.Mutex
+	var order []string
+	record := func(id string) {
+		mu.Lock()
+		order = append(order, id)
+		mu.Unlock()
+	}
+	_, _ = scheduler.AddNamedJob("extract", "* * * * * *", func() {
+		time.Sleep(50 * time.Millisecond)
+		record("extract")
+	})
+	_, _ = scheduler.AddNamedJob("load", "* * * * * *", func() { record("load") }, WithDependsOn("extract"))
+	_, _ = scheduler.AddNamedJob("report", "* * * * * *", func() { record("report") }, WithDependsOn("load"))
+	_, _ = scheduler.AddNamedJob("broken", "* * * * * *", func() { panic("boom") })
+	_, _ = scheduler.AddNamedJob("never", "* * * * * *", func() { record("never") }, WithDependsOn("extract", "broken"))
+	scheduler.SetPanicHandler(func(string, any, []byte) {})
+
+	scheduler.Start()
+	time.Sleep(1500 * time.Millisecond)
+	scheduler.Stop()
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) < 3 || order[0] != "extract" || order[1] != "load" || order[2] != "report" {
+		t.Errorf("Expected extract, load, report in order, got %v", order)
+	}
+	for _, id := range order {
+		if id == "never" {
+			t.Errorf("Expected a job depending on a failing job not to run, got %v", order)
+		}
+	}
+}
+
+// TestJitteredDependentJob tests that a dependent job firing after its
+// dependency's run of the same tick has succeeded still runs.
+func TestJitteredDependentJob(t *testing.T) {
+	for _, opts := range [][]SchedulerOption{nil, {WithIsolatedScheduling()}} {
+		scheduler := NewCronScheduler(opts...)
+		var mu sync.Mutex
+		runs := make(map[string]int)
+		record := func(id string) func() {
+			return func() {
+				mu.Lock()
+				runs[id]++
+				mu.Unlock()
+			}
+		}
+		_, _ = scheduler.AddNamedJob("extract", "* * * * * *", record("extract"))
+		_, _ = scheduler.AddNamedJob("load", "* * * * * *", record("load"),
+			WithDependsOn("extract"), WithJitter(800*time.Millisecond))
+
+		scheduler.Start()
+		time.Sleep(2500 * time.Millisecond)
+		scheduler.Stop()
+
+		mu.Lock()
+		if runs["extract"] == 0 || runs["load"] == 0 {
+			t.Errorf("Expected both jobs to run, got %v", runs)
+		}
+		mu.Unlock()
+	}
+}
+
+// TestSkippedDependency tests that a dependent job skips the ticks whose
+// dependency run its overlap policy dropped, without counting them.
+func TestSkippedDependency(t *testing.T) {
+	scheduler := NewCronScheduler()
+	events := make(chan JobEvent, 100)
+	scheduler.Subscribe(events)
+
+	block := make(chan struct{})
+	var mu sync.Mutex
+	runs := 0
+	_, _ = scheduler.AddNamedJob("extract", "* * * * * *", func() { <-block }, WithOverlapPolicy(SkipIfRunning))
+	_, _ = scheduler.AddNamedJob("load", "* * * * * *", func() {
+		mu.Lock()
+		runs++
+		mu.Unlock()
+	}, WithDependsOn("extract"), WithMaxRuns(1))
+
+	scheduler.Start()
+	if err := scheduler.RunNow("extract"); err != nil {
+		t.Fatalf("Failed to trigger job: %v", err)
+	}
+	time.Sleep(1500 * time.Millisecond)
+	close(block)
+	time.Sleep(2 * time.Second)
+	scheduler.Stop()
+
+	mu.Lock()
+	if runs != 1 {
+		t.Errorf("Expected the dependent job to run once, got %d runs", runs)
+	}
+	mu.Unlock()
+	skipped := false
+	for len(events) > 0 {
+		if e := <-events; e.Type == EventSkipped && e.JobID == "load" {
+			skipped = true
+		}
+	}
+	if !skipped {
+		t.Error("Expected EventSkipped for the dependent job")
+	}
+}
+
+// TestSubscribe tests that subscribers receive job lifecycle events.
+func TestSubscribe(t *testing.T) {
+	scheduler := NewCronScheduler()
+	scheduler.SetPanicHandler(func(string, any, []byte) {})
+	events := make(chan JobEvent, 100)
+	unsubscribe := scheduler.Subscribe(events)
+
+	_, _ = scheduler.AddNamedJob("ok", "@yearly", func() {})
+	_, _ = scheduler.AddJobWithError("@yearly", func() error { return errors.New("boom") })
+	_, _ = scheduler.AddNamedJob("panics", "@yearly", func() { panic("boom") })
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	_ = scheduler.RunNowAndWait(context.Background(), "ok")
+	_ = scheduler.RunNowAndWait(context.Background(), "job-1")
+	_ = scheduler.RunNowAndWait(context.Background(), "panics")
+	_ = scheduler.RemoveJob("ok")
+	unsubscribe()
+	_ = scheduler.RemoveJob("panics")
+
+	got := make(map[string][]EventType)
+	for len(events) > 0 {
+		event := <-events
+		got[event.JobID] = append(got[event.JobID], event.Type)
+	}
+	want := map[string][]EventType{
+		"ok":     {EventScheduled, EventStarted, EventSucceeded, EventRemoved},
+		"job-1":  {EventScheduled, EventStarted, EventFailed},
+		"panics": {EventScheduled, EventStarted, EventPanicked},
+	}
+	for id, types := range want {
+		if fmt.Sprint(got[id]) != fmt.Sprint(types) {
+			t.Errorf("Job %s: expected events %v, got %v", id, types, got[id])
+		}
+	}
+}
+
+// TestJitter tests that scheduled runs start after a random delay below the job's jitter.
+func TestJitter(t *testing.T) {
+	job := &Job{jitter: 100 * time.Millisecond}
+	delays := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		job.drawDelay()
+		if job.delay < 0 || job.delay >= job.jitter {
+			t.Fatalf("Delay %v out of range [0, %v)", job.delay, job.jitter)
+		}
+		delays[job.delay] = true
+	}
+	if len(delays) < 2 {
+		t.Errorf("Expected random delays, got %v", delays)
+	}
+
+	scheduler := NewCronScheduler()
+	started := make(chan time.Time, 10)
+	_, _ = scheduler.AddJob("* * * * * *", func() { started <- time.Now() }, WithJitter(500*time.Millisecond))
+	scheduler.Start()
+	defer scheduler.Stop()
+	select {
+	case start := <-started:
+		if offset := start.Sub(start.Truncate(time.Second)); offset >= 600*time.Millisecond {
+			t.Errorf("Expected the run to start within the jitter, started %v after the second", offset)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Jittered job did not run")
+	}
+}
+
+// TestIsolatedScheduling tests that jobs with their own scheduling loops run, pause and stop independently.
+func TestIsolatedScheduling(t *testing.T) {
+	scheduler := NewCronScheduler(WithIsolatedScheduling())
+	var mu sync.Mutex
+	runs := map[string]int{}
+	record := func(name string) func() {
+		return func() {
+			mu.Lock()
+			runs[name]++
+			mu.Unlock()
+		}
+	}
+	_, _ = scheduler.AddNamedJob("fast", "@every 20ms", record("fast"))
+	_, _ = scheduler.AddNamedJob("slow", "@every 150ms", record("slow"))
+	_, _ = scheduler.AddNamedJob("paused", "@every 20ms", record("paused"))
+	_ = scheduler.PauseJob("paused")
+	scheduler.Start()
+	defer scheduler.Stop()
+	_, _ = scheduler.AddNamedJob("late", "@every 20ms", record("late"))
+
+	time.Sleep(200 * time.Millisecond)
+	_ = scheduler.RemoveJob("fast")
+	time.Sleep(20 * time.Millisecond) // let a run started before the removal finish
+	mu.Lock()
+	fast := runs["fast"]
+	if fast < 5 || runs["slow"] != 1 || runs["late"] < 4 || runs["paused"] != 0 {
+		t.Errorf("Unexpected runs: %v", runs)
+	}
+	mu.Unlock()
+
+	_ = scheduler.ResumeJob("paused")
+	time.Sleep(100 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if runs["fast"] != fast {
+		t.Errorf("Expected a removed job to stop, ran %d more times", runs["fast"]-fast)
+	}
+	if runs["paused"] == 0 {
+		t.Error("Expected a resumed job to run")
+	}
+}
+
+// TestClockJump tests that a drift between the wall and monotonic clocks is reported as a clock jump.
+func TestClockJump(t *testing.T) {
+	scheduler := NewCronScheduler()
+	events := make(chan JobEvent, 10)
+	defer scheduler.Subscribe(events)()
+
+	scheduler.checkClock(time.Minute+500*time.Millisecond, time.Minute)
+	scheduler.checkClock(time.Hour+time.Minute, time.Minute)
+	scheduler.checkClock(0, 10*time.Second)
+
+	want := []time.Duration{time.Hour, -10 * time.Second}
+	for _, jump := range want {
+		select {
+		case e := <-events:
+			if e.Type != EventClockJump || e.JobID != "" || e.Jump != jump {
+				t.Errorf("Expected a clock jump of %v, got %+v", jump, e)
+			}
+		default:
+			t.Fatalf("Expected a clock jump of %v", jump)
+		}
+	}
+	select {
+	case e := <-events:
+		t.Errorf("Unexpected event %+v", e)
+	default:
+	}
+	if EventClockJump.String() != "clock jump" {
+		t.Errorf("Unexpected name %q", EventClockJump.String())
+	}
+}
+
+// TestMaxConcurrentJobs tests that the scheduler runs at most n tasks at once.
+func TestMaxConcurrentJobs(t *testing.T) {
+	for _, policy := range []LimitPolicy{QueueWhenLimited, SkipWhenLimited} {
+		scheduler := NewCronScheduler(WithMaxConcurrentJobs(2), WithLimitPolicy(policy))
+
+		var mu sync.Mutex
+		running, peak, runs := 0, 0, 0
+		for i := 0; i < 5; i++ {
+			_, _ = scheduler.AddJob("@yearly", func() {
+				mu.Lock()
+				running++
+				runs++
+				peak = max(peak, running)
+				mu.Unlock()
+				time.Sleep(50 * time.Millisecond)
+				mu.Lock()
+				running--
+				mu.Unlock()
+			})
+		}
+		scheduler.Start()
+
+		var wg sync.WaitGroup
+		for _, id := range []string{"job-1", "job-2", "job-3", "job-4", "job-5"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = scheduler.RunNowAndWait(context.Background(), id)
+			}()
+		}
+		wg.Wait()
+		scheduler.Stop()
+
+		mu.Lock()
+		if peak > 2 {
+			t.Errorf("Policy %d: expected at most 2 tasks at once, got %d", policy, peak)
+		}
+		if policy == QueueWhenLimited && runs != 5 {
+			t.Errorf("Expected every queued run to happen, got %d", runs)
+		}
+		if policy == SkipWhenLimited && runs >= 5 {
+			t.Errorf("Expected runs over the limit to be skipped, got %d", runs)
+		}
+		mu.Unlock()
+	}
+}
+
+// TestMiddleware tests that scheduler and job middleware wrap every attempt
+// in order and see the run's details.
+func TestMiddleware(t *testing.T) {
+	scheduler := NewCronScheduler()
+
+	var mu sync.Mutex
+	var calls []string
+	var runs []RunInfo
+	record := func(name string) JobMiddleware {
+		return func(next JobHandler) JobHandler {
+			return func(ctx context.Context, run RunInfo) error {
+				mu.Lock()
+				calls = append(calls, name)
+				if name == "outer" {
+					runs = append(runs, run)
+				}
+				mu.Unlock()
+				return next(ctx, run)
+			}
+		}
+	}
+	scheduler.Use(record("outer"), record("inner"))
+
+	errBoom := errors.New("boom")
+	attempts := 0
+	job, _ := scheduler.AddJobWithError("@yearly", func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, "task")
+		attempts++
+		if attempts == 1 {
+			return errBoom
+		}
+		return nil
+	}, WithMiddleware(record("job")), WithRetry(RetryPolicy{MaxAttempts: 2}), WithGroup("reports"))
+
+	if err := scheduler.RunNowAndWait(context.Background(), job.ID); err != nil {
+		t.Fatalf("Expected the retried run to succeed, got %v", err)
+	}
+	want := []string{"outer", "inner", "job", "task", "outer", "inner", "job", "task"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("Expected calls %v, got %v", want, calls)
+	}
+	if len(runs) != 2 || runs[0].Attempt != 1 || runs[1].Attempt != 2 {
+		t.Fatalf("Expected attempts 1 and 2, got %+v", runs)
+	}
+	if runs[0].JobID != job.ID || runs[0].Expression != "@yearly" || runs[0].Group != "reports" || !runs[0].Scheduled.IsZero() {
+		t.Errorf("Unexpected run info for a manual run: %+v", runs[0])
+	}
+
+	recovered := make(chan any, 1)
+	recovery := func(next JobHandler) JobHandler {
+		return func(ctx context.Context, run RunInfo) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					recovered <- r
+					err = fmt.Errorf("recovered: %v", r)
+				}
+			}()
+			return next(ctx, run)
+		}
+	}
+	panicJob, _ := scheduler.AddJob("@yearly", func() { panic("oops") }, WithMiddleware(recovery))
+	panicID := panicJob.ID
+	err := scheduler.RunNowAndWait(context.Background(), panicID)
+	var panicErr *PanicError
+	if err == nil || errors.As(err, &panicErr) {
+		t.Errorf("Expected the middleware to turn the panic into an error, got %v", err)
+	}
+	if r := <-recovered; r != "oops" {
+		t.Errorf("Expected the middleware to recover oops, got %v", r)
+	}
+}
+
+// TestJobGroups tests group-wide pause, resume and removal, and group
+// concurrency limits.
+func TestJobGroups(t *testing.T) {
+	scheduler := NewCronScheduler()
+	billing := scheduler.Group("billing")
+	if scheduler.Group("billing") != billing {
+		t.Fatalf("Expected Group to return the same group for a name")
+	}
+
+	var mu sync.Mutex
+	running, peak, runs := 0, 0, 0
+	task := func() {
+		mu.Lock()
+		running++
+		runs++
+		peak = max(peak, running)
+		mu.Unlock()
+		time.Sleep(50 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+	}
+	var ids []string
+	for i := 0; i < 3; i++ {
+		job, _ := billing.AddJob("@yearly", task)
+		id := job.ID
+		ids = append(ids, id)
+	}
+	taggedJob, _ := scheduler.AddJob("@yearly", task, WithGroup("billing"))
+	taggedID := taggedJob.ID
+	ids = append(ids, taggedID)
+	otherJob, _ := scheduler.AddJob("@yearly", func() {})
+	otherID := otherJob.ID
+
+	if got := billing.Jobs(); !reflect.DeepEqual(got, ids) {
+		t.Errorf("Expected group jobs %v, got %v", ids, got)
+	}
+	if info, _ := scheduler.JobInfo(taggedID); info.Group != "billing" {
+		t.Errorf("Expected JobInfo.Group billing, got %q", info.Group)
+	}
+
+	billing.SetMaxConcurrent(1)
+	scheduler.Start()
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = scheduler.RunNowAndWait(context.Background(), id)
+		}()
+	}
+	wg.Wait()
+	mu.Lock()
+	if peak != 1 || runs != len(ids) {
+		t.Errorf("Expected %d runs one at a time, got %d with a peak of %d", len(ids), runs, peak)
+	}
+	mu.Unlock()
+
+	billing.Pause()
+	for _, info := range scheduler.ListJobInfo() {
+		if paused := info.ID != otherID; info.Paused != paused {
+			t.Errorf("Job %s: expected paused %v, got %v", info.ID, paused, info.Paused)
+		}
+	}
+	billing.Resume()
+	for _, info := range scheduler.ListJobInfo() {
+		if info.Paused || info.NextRun.IsZero() {
+			t.Errorf("Expected job %s to be resumed, got %+v", info.ID, info)
+		}
+	}
+
+	if err := billing.Remove(); err != nil {
+		t.Fatalf("Unexpected error removing the group: %v", err)
+	}
+	if len(billing.Jobs()) != 0 || len(scheduler.jobs) != 1 || scheduler.jobs[0].ID != otherID {
+		t.Errorf("Expected only %s to remain, got %v", otherID, scheduler.ListJobs())
+	}
+	scheduler.Stop()
+}
+
+// TestWorkerPool tests that a scheduler with workers runs every task on at most that many goroutines.
+func TestWorkerPool(t *testing.T) {
+	scheduler := NewCronScheduler(WithWorkers(3))
+
+	var mu sync.Mutex
+	running, peak, runs := 0, 0, 0
+	for i := 0; i < 20; i++ {
+		_, _ = scheduler.AddJob("@yearly", func() {
+			mu.Lock()
+			running++
+			runs++
+			peak = max(peak, running)
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+		})
+	}
+	scheduler.Start()
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = scheduler.RunNowAndWait(context.Background(), fmt.Sprintf("job-%d", i))
+		}()
+	}
+	wg.Wait()
+	_ = scheduler.StopAndWait(context.Background())
+
+	mu.Lock()
+	defer mu.Unlock()
+	if runs != 20 || peak > 3 {
+		t.Errorf("Expected 20 runs on at most 3 workers, got %d runs with %d at once", runs, peak)
+	}
+}
+
+// TestQuartzQuestionMark tests "?" in the day fields and the DayOr and
+// DayAnd semantics.
+func TestQuartzQuestionMark(t *testing.T) {
+	for _, expr := range []string{"0 0 12 ? * Mon", "0 0 12 * * ?"} {
+		if _, err := ParseCronExpression(expr, WithSeconds()); err != nil {
+			t.Errorf("Failed to parse %q: %v", expr, err)
+		}
+	}
+	if _, err := ParseCronExpression("0 0 ? * * *", WithSeconds()); err == nil {
+		t.Error("Expected \"?\" outside the day fields to be rejected")
+	}
+
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC) // a Tuesday
+	tests := []struct {
+		matching DayMatching
+		expr     string
+		want     time.Time
+	}{
+		{DayAnd, "0 0 1 * Mon", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{DayOr, "0 0 1 * Mon", time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)},
+		{DayOr, "0 0 ? * Mon", time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)},
+		{DayOr, "0 0 15 * *", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{DayOr, "0 0 1,15 * Mon", time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)},
+		{DayAnd, "0 0 1,15 * Mon", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{DayAnd, "0 0 1-7 * Fri", time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		expr, err := ParseCronExpression(tt.expr)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", tt.expr, err)
+		}
+		expr.DayMatching = tt.matching
+		if got := expr.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q with matching %d: expected %v, got %v", tt.expr, tt.matching, tt.want, got)
+		}
+	}
+
+	if expr, _ := ParseCronExpression("0 0 1,15 * Mon"); expr.DayMatching != DayOr {
+		t.Errorf("Expected expressions to default to DayOr, got %d", expr.DayMatching)
+	}
+	scheduler := NewCronScheduler(WithDayMatching(DayAnd))
+	job, err := scheduler.AddJob("0 0 0 1,15 * Mon", func() {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := job.Schedule().Next(from); !got.Equal(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the DayAnd scheduler to fire on Monday the 15th, got %v", got)
+	}
+}
+
+// TestLastDaySyntax tests "L", "L-n" and "nL" in the day fields.
+func TestLastDaySyntax(t *testing.T) {
+	from := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"0 0 L * *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 L-3 * *", time.Date(2024, 2, 26, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,L * *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 5L", time.Date(2024, 2, 23, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * FriL", time.Date(2024, 2, 23, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * 4 L", time.Date(2024, 4, 6, 0, 0, 0, 0, time.UTC)},
+		{"0 0 L 4 *", time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		expr, err := ParseCronExpression(tt.expr)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", tt.expr, err)
+		}
+		if got := expr.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.expr, tt.want, got)
+		}
+	}
+
+	for _, expr := range []string{"0 0 L-31 * *", "0 0 LL * *", "0 0 * * 9L"} {
+		if _, err := ParseCronExpression(expr); err == nil {
+			t.Errorf("Expected %q to be rejected", expr)
+		}
+	}
+}
+
+// TestNearestWeekdaySyntax tests "nW" and "LW" in the day-of-month field, including month boundaries.
+func TestNearestWeekdaySyntax(t *testing.T) {
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		// June 15, 2024 is a Saturday.
+		{"0 0 15W * *", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 6, 14, 0, 0, 0, 0, time.UTC)},
+		// September 15, 2024 is a Sunday.
+		{"0 0 15W * *", time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 9, 16, 0, 0, 0, 0, time.UTC)},
+		// June 1, 2024 is a Saturday: the nearest weekday stays in June.
+		{"0 0 1W * *", time.Date(2024, 5, 31, 12, 0, 0, 0, time.UTC), time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)},
+		// March 31, 2024 is a Sunday: the nearest weekday stays in March.
+		{"0 0 31W * *", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 29, 0, 0, 0, 0, time.UTC)},
+		// February has no 30th.
+		{"0 0 30W * *", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 29, 0, 0, 0, 0, time.UTC)},
+		// August 31, 2024 is a Saturday.
+		{"0 0 LW * *", time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 8, 30, 0, 0, 0, 0, time.UTC)},
+		{"0 0 LW * *", time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		expr, err := ParseCronExpression(tt.expr)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", tt.expr, err)
+		}
+		if got := expr.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%q from %v: expected %v, got %v", tt.expr, tt.from, tt.want, got)
+		}
+	}
+
+	for _, expr := range []string{"0 0 32W * *", "0 0 W * *", "0 0 1-5W * *"} {
+		if _, err := ParseCronExpression(expr); err == nil {
+			t.Errorf("Expected %q to be rejected", expr)
+		}
+	}
+}
+
+// TestNthWeekdaySyntax tests "weekday#n" in the day-of-week field.
+func TestNthWeekdaySyntax(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"0 10 * * Tue#2", time.Date(2024, 1, 9, 10, 0, 0, 0, time.UTC)},
+		{"0 0 * * 1#1", time.Date(2024, 2, 5, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * Fri#5", time.Date(2024, 3, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * Sun#1,Sat#1", time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		expr, err := ParseCronExpression(tt.expr)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", tt.expr, err)
+		}
+		if got := expr.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.expr, tt.want, got)
+		}
+	}
+
+	for _, expr := range []string{"0 0 * * Mon#0", "0 0 * * Mon#6", "0 0 * * 8#1", "0 0 * * Mon#"} {
+		if _, err := ParseCronExpression(expr); err == nil {
+			t.Errorf("Expected %q to be rejected", expr)
+		}
+	}
+}
+
+// TestSundayAsSeven tests that Sunday can be written as 0, 7 or Sun.
+func TestSundayAsSeven(t *testing.T) {
+	from := time.Date(2024, 1, 6, 12, 0, 0, 0, time.UTC) // a Saturday
+	sunday := time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC)
+	for _, expr := range []string{"0 0 * * 0", "0 0 * * 7", "0 0 * * Sun", "0 0 * * 7-1", "0 0 * * 7#1"} {
+		parsed, err := ParseCronExpression(expr)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", expr, err)
+		}
+		if got := parsed.Next(from); !got.Equal(sunday) {
+			t.Errorf("%q: expected %v, got %v", expr, sunday, got)
+		}
+	}
+	if expr, _ := ParseCronExpression("0 0 * * *"); len(expr.DayOfWeek()) != 7 {
+		t.Errorf("Expected \"*\" to list each weekday once, got %v", expr.DayOfWeek())
+	}
+	if _, err := ParseCronExpression("0 0 * * 8"); err == nil {
+		t.Error("Expected 8 to be rejected in the day-of-week field")
+	}
+}
+
+// TestYearField tests that next-run computation honors the year field.
+func TestYearField(t *testing.T) {
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"0 0 0 1 1 * 2026", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 0 1 * * 2024-2025", time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 0 1 1 * 2023,2035", time.Date(2035, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 0 1 1 * 2020-2023", time.Time{}},
+		{"0 0 0 29 2 * *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		expr, err := ParseCronExpression(tt.expr, WithSeconds())
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", tt.expr, err)
+		}
+		if got := expr.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.expr, tt.want, got)
+		}
+	}
+}
+
+// TestScheduleBuilder tests that built schedules match the expressions
+// they stand for and that bad values fail Build.
+func TestScheduleBuilder(t *testing.T) {
+	tests := []struct {
+		builder *ScheduleBuilder
+		want    string
+	}{
+		{NewSchedule(), "* * * * *"},
+		{NewSchedule().EveryDay(), "0 0 * * *"},
+		{NewSchedule().EveryDay().At(9, 30), "30 9 * * *"},
+		{NewSchedule().OnWeekdays(time.Monday).EveryNMinutes(15), "*/15 * * * 1"},
+		{NewSchedule().OnWeekdays(time.Friday, time.Monday).At(18, 0), "0 18 * * 1,5"},
+		{NewSchedule().EveryNHours(6).OnDaysOfMonth(15, 1).InMonths(time.January), "0 */6 1,15 1 *"},
+	}
+	for _, tt := range tests {
+		expr, err := tt.builder.Build()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.want, err)
+		}
+		if got := expr.String(); got != tt.want {
+			t.Errorf("Expected %q, got %q", tt.want, got)
+		}
+		parsed, _ := ParseCronExpression(tt.want)
+		from := time.Date(2024, 5, 1, 10, 7, 0, 0, time.UTC)
+		if got, want := expr.Next(from), parsed.Next(from); !got.Equal(want) {
+			t.Errorf("%s: expected next run %v, got %v", tt.want, want, got)
+		}
+	}
+
+	for _, builder := range []*ScheduleBuilder{
+		NewSchedule().At(24, 0),
+		NewSchedule().EveryNMinutes(0),
+		NewSchedule().OnWeekdays(time.Weekday(7)).At(9, 0),
+		NewSchedule().OnDaysOfMonth(),
+		NewSchedule().InMonths(13),
+	} {
+		var fieldErr *FieldError
+		if _, err := builder.Build(); !errors.As(err, &fieldErr) {
+			t.Errorf("Expected a field error, got %v", err)
+		}
+	}
+
+	schedule, _ := NewSchedule().EveryDay().At(9, 30).Build()
+	scheduler := NewCronScheduler()
+	job, err := scheduler.AddScheduledJob(schedule, func() {})
+	if err != nil {
+		t.Fatalf("Failed to add scheduled job: %v", err)
+	}
+	if job.Expression() != "30 9 * * *" || job.Schedule() == schedule {
+		t.Errorf("Expected the job to run on a copy of the schedule, got %q", job.Expression())
+	}
+}
+
+// TestIntervalSchedule tests that interval schedules fire at the multiples
+// of their interval since the epoch, shifted by their offset.
+func TestIntervalSchedule(t *testing.T) {
+	from := time.Date(2024, 5, 1, 10, 7, 0, 0, time.UTC)
+	tests := []struct {
+		schedule *IntervalSchedule
+		want     time.Time
+	}{
+		{Every(90 * time.Second), time.Date(2024, 5, 1, 10, 7, 30, 0, time.UTC)},
+		// 7 hours does not divide a day, so its runs are not at fixed hours.
+		{Every(7 * time.Hour), time.Date(2024, 5, 1, 17, 0, 0, 0, time.UTC)},
+		{Every(time.Minute), time.Date(2024, 5, 1, 10, 8, 0, 0, time.UTC)},
+		{EveryWithOffset(time.Hour, 15*time.Minute), time.Date(2024, 5, 1, 10, 15, 0, 0, time.UTC)},
+		{EveryWithOffset(time.Hour, -50*time.Minute), time.Date(2024, 5, 1, 10, 10, 0, 0, time.UTC)},
+		{EveryWithOffset(time.Hour, 75*time.Minute), time.Date(2024, 5, 1, 10, 15, 0, 0, time.UTC)},
+		{Every(0), time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := tt.schedule.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.schedule, tt.want, got)
+		}
+	}
+	if got := Every(time.Hour).Next(time.Unix(-1800, 0)); !got.Equal(time.Unix(0, 0)) {
+		t.Errorf("Expected the epoch after a time before it, got %v", got)
+	}
+
+	scheduler := NewCronScheduler()
+	job, err := scheduler.AddScheduledJob(EveryWithOffset(7*time.Hour, 30*time.Minute), func() {})
+	if err != nil {
+		t.Fatalf("Failed to add interval job: %v", err)
+	}
+	if job.Expression() != "every 7h0m0s offset 30m0s" || job.cron() != nil {
+		t.Errorf("Expected an interval job described by its schedule, got %q", job.Expression())
+	}
+	runs := scheduler.Simulate(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 1, 23, 59, 0, 0, time.UTC))
+	if len(runs) != 3 || !runs[0].Time.Equal(time.Date(2024, 5, 1, 3, 30, 0, 0, time.UTC)) {
+		t.Errorf("Expected 3 runs from 03:30, got %v", runs)
+	}
+
+	var mu sync.Mutex
+	var count int
+	scheduler = NewCronScheduler()
+	_, _ = scheduler.AddScheduledJob(Every(100*time.Millisecond), func() {
+		mu.Lock()
+		count++
+		mu.Unlock()
+	})
+	scheduler.Start()
+	time.Sleep(550 * time.Millisecond)
+	scheduler.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if count < 3 || count > 6 {
+		t.Errorf("Expected about 5 runs of a 100ms job in 550ms, got %d", count)
+	}
+}
+
+// timesSchedule is a custom Schedule firing at a fixed list of times.
+type timesSchedule []time.Time
+
+func (s timesSchedule) Next(from time.Time) time.Time {
+	for _, t := range s {
+		if t.After(from) {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// stuckSchedule is a broken Schedule that never moves past from.
+type stuckSchedule struct{}
+
+func (stuckSchedule) Next(from time.Time) time.Time { return from }
+
+// TestCustomSchedule tests that jobs run on user-defined Schedules.
+func TestCustomSchedule(t *testing.T) {
+	times := timesSchedule{
+		time.Date(2030, 3, 20, 6, 1, 0, 0, time.UTC),
+		time.Date(2030, 6, 21, 4, 43, 0, 0, time.UTC),
+	}
+	scheduler := NewCronScheduler()
+	job, err := scheduler.AddScheduledJob(times, func() {}, WithMaxRuns(5))
+	if err != nil {
+		t.Fatalf("Failed to add job: %v", err)
+	}
+	runs := scheduler.Simulate(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC))
+	if len(runs) != 2 || !runs[0].Time.Equal(times[0]) || !runs[1].Time.Equal(times[1]) {
+		t.Errorf("Expected the schedule's two times, got %v", runs)
+	}
+	if job.Expression() != "" {
+		t.Errorf("Expected no expression for a schedule without String, got %q", job.Expression())
+	}
+
+	stuck, _ := scheduler.AddScheduledJob(stuckSchedule{}, func() {})
+	next, err := scheduler.NextRuns(stuck.ID, 3)
+	if err != nil || len(next) != 0 {
+		t.Errorf("Expected a schedule not moving forward to never fire, got %v, %v", next, err)
+	}
+
+	if _, err := scheduler.AddScheduledJob(nil, func() {}); err == nil {
+		t.Errorf("Expected a nil schedule to be rejected")
+	}
+}
+
+// TestBusinessDaySchedule tests that fire times on weekends and holidays
+// are skipped or moved to a business day.
+func TestBusinessDaySchedule(t *testing.T) {
+	monthly, _ := ParseCronExpression("0 18 1 * *")
+	from := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+	// 2024-06-01 is a Saturday.
+	tests := []struct {
+		schedule *BusinessDaySchedule
+		want     time.Time
+	}{
+		{BusinessDays(monthly, nil, BusinessDaySkip), time.Date(2024, 7, 1, 18, 0, 0, 0, time.UTC)},
+		{BusinessDays(monthly, nil, BusinessDayFollowing), time.Date(2024, 6, 3, 18, 0, 0, 0, time.UTC)},
+		{BusinessDays(monthly, nil, BusinessDayPreceding), time.Date(2024, 5, 31, 18, 0, 0, 0, time.UTC)},
+		{BusinessDays(monthly, NewDateCalendar(time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)), BusinessDayFollowing), time.Date(2024, 6, 4, 18, 0, 0, 0, time.UTC)},
+		{&BusinessDaySchedule{Schedule: monthly, Adjustment: BusinessDayFollowing, Weekend: []time.Weekday{time.Friday, time.Saturday}}, time.Date(2024, 6, 2, 18, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := tt.schedule.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.schedule, tt.want, got)
+		}
+	}
+
+	// Fire times moved onto one already there run once.
+	daily, _ := ParseCronExpression("0 9 * * *")
+	mayDay := NewDateCalendar(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+	scheduler := NewCronScheduler()
+	_, _ = scheduler.AddScheduledJob(BusinessDays(daily, mayDay, BusinessDayFollowing), func() {})
+	var got []int
+	for _, run := range scheduler.Simulate(time.Date(2024, 4, 27, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 5, 0, 0, 0, 0, time.UTC)) {
+		got = append(got, run.Time.Day())
+	}
+	if want := []int{29, 30, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected runs on days %v, got %v", want, got)
+	}
+
+	never := &BusinessDaySchedule{Schedule: daily, Weekend: []time.Weekday{0, 1, 2, 3, 4, 5, 6}}
+	if next := never.Next(from); !next.IsZero() {
+		t.Errorf("Expected a schedule without business days to never fire, got %v", next)
+	}
+}
+
+// TestRepeatingInterval tests parsing ISO 8601 repeating intervals and
+// scheduling jobs with them.
+func TestRepeatingInterval(t *testing.T) {
+	from := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"R/2025-01-01T00:00:00Z/P1D", time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{"R/2025-01-01T00:00:00Z/PT90M", time.Date(2025, 3, 10, 13, 30, 0, 0, time.UTC)},
+		{"R/2025-01-31T08:00:00Z/P1M", time.Date(2025, 3, 31, 8, 0, 0, 0, time.UTC)},
+		{"R/2025-01-01T00:00:00+01:00/P1W", time.Date(2025, 3, 11, 23, 0, 0, 0, time.UTC)},
+		{"R/2025-01-01T00:00:00Z/2025-01-01T06:00:00Z", time.Date(2025, 3, 10, 18, 0, 0, 0, time.UTC)},
+		{"R/2025-06-01T00:00:00Z/P1Y2M10DT2H30M", time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"R/2025-03-10T11:59:59.5Z/PT0.25S", time.Date(2025, 3, 10, 12, 0, 0, 250000000, time.UTC)},
+		{"R5/2025-01-01T00:00:00Z/P1W", time.Time{}},
+		{"R11/2025-01-01T00:00:00Z/P1W", time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		r, err := ParseRepeatingInterval(tt.expr)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", tt.expr, err)
+		}
+		if got := r.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.expr, tt.want, got)
+		}
+	}
+	if r, _ := ParseRepeatingInterval("R3/2025-01-01T09:00:00Z/P1Y2M10DT2H30M1.5S"); r.String() != "R3/2025-01-01T09:00:00Z/P1Y2M10DT2H30M1.5S" {
+		t.Errorf("Expected the interval to format back, got %q", r.String())
+	}
+
+	for _, expr := range []string{"R/2025-01-01T00:00:00Z", "R/2025-01-01/P1D", "R/2025-01-01T00:00:00Z/P", "R/2025-01-01T00:00:00Z/P1.5D", "R/2025-01-01T00:00:00Z/PT0S", "Rx/2025-01-01T00:00:00Z/P1D", "R/2025-01-01T00:00:00Z/P1H"} {
+		if _, err := ParseRepeatingInterval(expr); err == nil {
+			t.Errorf("Expected %q to fail", expr)
+		}
+	}
+
+	scheduler := NewCronScheduler()
+	job, err := scheduler.AddJob("R3/2030-01-01T09:00:00Z/P1D", func() {})
+	if err != nil {
+		t.Fatalf("Failed to add job: %v", err)
+	}
+	id := job.ID
+	if err := scheduler.Validate("R/2030-01-01T09:00:00Z/PT0S"); err == nil {
+		t.Errorf("Expected the scheduler to reject an empty period")
+	}
+	runs, _ := scheduler.NextRuns(id, 5)
+	if len(runs) != 3 || !runs[2].Equal(time.Date(2030, 1, 3, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected 3 daily runs from 2030-01-01, got %v", runs)
+	}
+}
+
+// TestParseOnCalendar tests systemd calendar events.
+func TestParseOnCalendar(t *testing.T) {
+	// 2024-05-01 is a Wednesday.
+	from := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	lisbon, _ := time.LoadLocation("Europe/Lisbon")
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"Mon..Fri 10:00", time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)},
+		{"*-*-01 00:00:00", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"Sat..Sun 09:15", time.Date(2024, 5, 4, 9, 15, 0, 0, time.UTC)},
+		{"Mon,Fri *-*-* 18:00", time.Date(2024, 5, 3, 18, 0, 0, 0, time.UTC)},
+		{"*:0/15", time.Date(2024, 5, 1, 12, 15, 0, 0, time.UTC)},
+		{"*-*~01 23:00", time.Date(2024, 5, 31, 23, 0, 0, 0, time.UTC)},
+		{"2025-01-01", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"12-25 08:00", time.Date(2024, 12, 25, 8, 0, 0, 0, time.UTC)},
+		{"9..17:30:05", time.Date(2024, 5, 1, 12, 30, 5, 0, time.UTC)},
+		{"weekly", time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)},
+		{"quarterly", time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"Sat *-*-* 02:30 Europe/Lisbon", time.Date(2024, 5, 4, 2, 30, 0, 0, lisbon)},
+		{"Fri 13:00 UTC", time.Date(2024, 5, 3, 13, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		schedule, err := ParseOnCalendar(tt.expr)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", tt.expr, err)
+		}
+		if got := schedule.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.expr, tt.want, got)
+		}
+	}
+
+	for _, expr := range []string{"", "Funday 10:00", "*-13-01", "25:00", "10:00 11:00", "*-*-* *:*:*:*", "*:0/0", "Mon 10:00 Nowhere/City"} {
+		if _, err := ParseOnCalendar(expr); err == nil {
+			t.Errorf("Expected %q to fail", expr)
+		}
+	}
+
+	schedule, _ := ParseOnCalendar("daily")
+	scheduler := NewCronScheduler()
+	job, _ := scheduler.AddScheduledJob(schedule, func() {})
+	if job.Expression() != "*-*-* 00:00:00" {
+		t.Errorf("Expected the expanded event as the expression, got %q", job.Expression())
+	}
+}
+
+// TestExportImportState tests that jobs move between schedulers with their
+// pause states and statistics.
+func TestExportImportState(t *testing.T) {
+	source := NewCronScheduler()
+	source.RegisterTask("report", func(ctx context.Context) error { return nil })
+	if _, err := source.AddRegisteredJob("report", "0 6 * * *"); err != nil {
+		t.Fatalf("Failed to add job: %v", err)
+	}
+	_, _ = source.AddNamedJob("cleanup", "@hourly", func() {}, WithMetadata(map[string]string{"owner": "ops"}), WithGroup("maintenance"))
+	_ = source.PauseJob("cleanup")
+	lastRun := time.Date(2025, 3, 1, 6, 0, 0, 0, time.UTC)
+	job, _ := source.GetJob("report")
+	source.mutex.Lock()
+	job.runCount, job.lastRun, job.lastError = 3, lastRun, errors.New("timeout")
+	source.mutex.Unlock()
+
+	data, err := source.ExportState()
+	if err != nil {
+		t.Fatalf("Failed to export state: %v", err)
+	}
+
+	target := NewCronScheduler()
+	noop := func(ctx context.Context) error { return nil }
+	if err := target.ImportState(data, TaskRegistry{"report": noop}); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Expected ErrTaskNotFound for a missing task, got %v", err)
+	}
+	if len(target.ListJobInfo()) != 0 {
+		t.Error("Expected a failed import to add no jobs")
+	}
+	if err := target.ImportState(data, TaskRegistry{"report": noop, "cleanup": noop}); err != nil {
+		t.Fatalf("Failed to import state: %v", err)
+	}
+	report, err := target.GetJob("report")
+	if err != nil {
+		t.Fatalf("Expected the report job to be imported: %v", err)
+	}
+	if report.Expression() != "0 6 * * *" || report.task != "report" || report.runCount != 3 || !report.lastRun.Equal(lastRun) || report.lastError == nil || report.lastError.Error() != "timeout" {
+		t.Errorf("Unexpected imported report job: %+v", report)
+	}
+	cleanup, _ := target.GetJob("cleanup")
+	if !cleanup.paused || cleanup.group != "maintenance" || cleanup.metadata["owner"] != "ops" {
+		t.Errorf("Unexpected imported cleanup job: paused %v, group %q, metadata %v", cleanup.paused, cleanup.group, cleanup.metadata)
+	}
+
+	if err := target.ImportState([]byte(`{"version":2}`), nil); err == nil {
+		t.Error("Expected an error for an unsupported version")
+	}
+}
+
+// TestReplaceJobs tests that ReplaceJobs adds, replaces, keeps and removes
+// jobs to match the desired set.
+func TestReplaceJobs(t *testing.T) {
+	scheduler := NewCronScheduler()
+	noop := func(ctx context.Context) error { return nil }
+	_, _ = scheduler.AddNamedJob("kept", "@daily", func() {})
+	_, _ = scheduler.AddNamedJob("changed", "@hourly", func() {})
+	_, _ = scheduler.AddNamedJob("removed", "@weekly", func() {})
+	kept, _ := scheduler.GetJob("kept")
+	scheduler.mutex.Lock()
+	kept.runCount = 2
+	scheduler.mutex.Unlock()
+
+	var mu sync.Mutex
+	var count int
+	task := func(ctx context.Context) error {
+		mu.Lock()
+		count++
+		mu.Unlock()
+		return nil
+	}
+	err := scheduler.ReplaceJobs([]JobSpec{
+		{ID: "kept", Expression: "0 0 * * *", Task: task},
+		{ID: "changed", Expression: "*/5 * * * *", Task: noop},
+		{ID: "added", Expression: "@monthly", Task: noop, Options: []JobOption{WithGroup("reports")}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to replace jobs: %v", err)
+	}
+	got := make(map[string]string)
+	for _, info := range scheduler.ListJobInfo() {
+		got[info.ID] = info.Expression
+	}
+	if !reflect.DeepEqual(got, map[string]string{"kept": "@daily", "changed": "*/5 * * * *", "added": "@monthly"}) {
+		t.Errorf("Unexpected jobs after replace: %v", got)
+	}
+	if job, _ := scheduler.GetJob("kept"); job != kept || job.runCount != 2 {
+		t.Error("Expected an unchanged job to be kept with its statistics")
+	}
+	if err := scheduler.RunNowAndWait(context.Background(), "kept"); err != nil {
+		t.Fatalf("Failed to run kept job: %v", err)
+	}
+	mu.Lock()
+	if count != 1 {
+		t.Errorf("Expected the kept job to run its new task, got %d runs", count)
+	}
+	mu.Unlock()
+
+	kept, _ = scheduler.GetJob("kept")
+	added, _ := scheduler.GetJob("added")
+	err = scheduler.ReplaceJobs([]JobSpec{
+		{ID: "kept", Expression: "@daily", Task: task, Options: []JobOption{WithTimeout(time.Minute)}},
+		{ID: "changed", Expression: "*/5 * * * *", Task: noop},
+		{ID: "added", Expression: "@monthly", Task: noop, Options: []JobOption{WithGroup("reports")}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to replace jobs: %v", err)
+	}
+	if job, _ := scheduler.GetJob("kept"); job == kept || job.timeout != time.Minute {
+		t.Error("Expected a job whose options changed to be replaced")
+	}
+	if job, _ := scheduler.GetJob("added"); job != added {
+		t.Error("Expected a job with unchanged options to be kept")
+	}
+
+	invalid := [][]JobSpec{
+		{{ID: "a", Expression: "61 * * * *", Task: noop}},
+		{{ID: "a", Expression: "@daily", Task: noop}, {ID: "a", Expression: "@hourly", Task: noop}},
+		{{Expression: "@daily", Task: noop}},
+		{{ID: "a", Expression: "@daily"}},
+	}
+	for _, specs := range invalid {
+		if err := scheduler.ReplaceJobs(specs); err == nil {
+			t.Errorf("Expected an error for %+v", specs)
+		}
+	}
+	if len(scheduler.ListJobInfo()) != 3 {
+		t.Error("Expected a failed replace to change nothing")
+	}
+
+	if err := scheduler.ReplaceJobs(nil); err != nil || len(scheduler.ListJobInfo()) != 0 {
+		t.Errorf("Expected an empty set to remove every job, got %v", err)
+	}
+}
+
+// TestCronExpressionEqual tests that equivalent expressions written in
+// different forms compare equal.
+func TestCronExpressionEqual(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		equal bool
+	}{
+		{"0,30 9-17 * * MON-FRI", "*/30 9-17 * * 1-5", true},
+		{"@daily", "0 0 * * *", true},
+		{"0 0 * * 7", "0 0 * * SUN", true},
+		{"5,1,3 * * * *", "1,3,5 * * * *", true},
+		{"0 0 L * *", "0 0 L * *", true},
+		{"0 9 * * *", "0 10 * * *", false},
+		{"0 0 1-31 * 1", "0 0 * * 1", false},
+		{"0 0 1-31 * *", "0 0 * * *", true},
+		{"0 0 * * 0-6", "0 0 * * *", true},
+		{"0 0 L * *", "0 0 L-1 * *", false},
+		{"@every 1m", "@every 60s", true},
+		{"@every 1m", "* * * * *", false},
+	}
+	for _, test := range tests {
+		a, err := ParseCronExpression(test.a)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", test.a, err)
+		}
+		b, err := ParseCronExpression(test.b)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", test.b, err)
+		}
+		if got := a.Equal(b); got != test.equal {
+			t.Errorf("Expected %q.Equal(%q) to be %v, got %v", test.a, test.b, test.equal, got)
+		}
+	}
+
+	expr := &CronExpression{minutes: newFieldSet([]int{30, 0, 30}), hours: newFieldSet([]int{9}), years: make([]int, 2099-1970+1)}
+	for i := range expr.years {
+		expr.years[i] = 2099 - i
+	}
+	if n := expr.Normalize(); !reflect.DeepEqual(n.Minutes(), []int{0, 30}) || n.Years() != nil {
+		t.Errorf("Unexpected normalized expression: minutes %v, years %v", n.Minutes(), n.Years())
+	}
+	if expr.years[0] != 2099 {
+		t.Error("Expected Normalize to leave the expression unchanged")
+	}
+	every, _ := ParseCronExpression("0 0 1-31 * 1")
+	mondays, _ := ParseCronExpression("0 0 * * 1")
+	every.DayMatching, mondays.DayMatching = DayOr, DayOr
+	if every.Equal(mondays) {
+		t.Error("Expected a full day-of-month list to differ from \"*\" under DayOr")
+	}
+	mondays.DayMatching = DayAnd
+	if every.Equal(mondays) {
+		t.Error("Expected expressions with different day matching to differ")
+	}
+}
+
+// TestJobStats tests that job statistics count failures and summarize run
+// durations.
+func TestJobStats(t *testing.T) {
+	var stats jobStats
+	for i := 1; i <= 20; i++ {
+		var err error
+		if i > 17 {
+			err = errors.New("failed")
+		}
+		stats.record(time.Duration(i)*time.Millisecond, err)
+	}
+	got := stats.snapshot()
+	want := JobStats{
+		Runs:                20,
+		Failures:            3,
+		ConsecutiveFailures: 3,
+		SuccessRate:         0.85,
+		MeanDuration:        10500 * time.Microsecond,
+		MaxDuration:         20 * time.Millisecond,
+		P95Duration:         19 * time.Millisecond,
+	}
+	if got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+	stats.record(time.Millisecond, nil)
+	if got := stats.snapshot(); got.ConsecutiveFailures != 0 {
+		t.Errorf("Expected a success to reset consecutive failures, got %d", got.ConsecutiveFailures)
+	}
+
+	scheduler := NewCronScheduler()
+	fail := true
+	job, _ := scheduler.AddJobWithError("@daily", func() error {
+		if fail {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	_ = scheduler.RunNowAndWait(context.Background(), job.ID)
+	fail = false
+	_ = scheduler.RunNowAndWait(context.Background(), job.ID)
+	if got, err := scheduler.Stats(job.ID); err != nil || got.Runs != 2 || got.Failures != 1 || got.SuccessRate != 0.5 {
+		t.Errorf("Unexpected stats %+v, %v", got, err)
+	}
+	if _, err := scheduler.Stats("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+}
+
+// TestCircuitBreaker tests that a job is paused after consecutive failures
+// and resumed after the cooldown.
+func TestCircuitBreaker(t *testing.T) {
+	scheduler := NewCronScheduler()
+	events := make(chan JobEvent, 100)
+	scheduler.Subscribe(events)
+	job, _ := scheduler.AddJobWithError("@yearly", func() error { return errors.New("boom") },
+		WithCircuitBreaker(CircuitBreaker{Threshold: 2, Cooldown: 50 * time.Millisecond}))
+
+	_ = scheduler.RunNowAndWait(context.Background(), job.ID)
+	if info, _ := scheduler.JobInfo(job.ID); info.Paused {
+		t.Fatal("Expected the circuit to stay closed below the threshold")
+	}
+	_ = scheduler.RunNowAndWait(context.Background(), job.ID)
+	if info, _ := scheduler.JobInfo(job.ID); !info.Paused {
+		t.Fatal("Expected the circuit to open at the threshold")
+	}
+
+	deadline := time.After(time.Second)
+	var got []EventType
+	for len(got) == 0 || got[len(got)-1] != EventCircuitClosed {
+		select {
+		case event := <-events:
+			got = append(got, event.Type)
+		case <-deadline:
+			t.Fatalf("Expected the circuit to close after the cooldown, got events %v", got)
+		}
+	}
+	want := []EventType{EventStarted, EventFailed, EventStarted, EventFailed, EventCircuitOpen, EventCircuitClosed}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected events %v, got %v", want, got)
+	}
+	if info, _ := scheduler.JobInfo(job.ID); info.Paused {
+		t.Error("Expected the job to be resumed after the cooldown")
+	}
+
+	// A job resumed by hand is not resumed again when the cooldown ends.
+	_ = scheduler.RunNowAndWait(context.Background(), job.ID)
+	_ = scheduler.ResumeJob(job.ID)
+	_ = scheduler.PauseJob(job.ID)
+	time.Sleep(100 * time.Millisecond)
+	if info, _ := scheduler.JobInfo(job.ID); !info.Paused {
+		t.Error("Expected a job paused by hand to stay paused")
+	}
+
+	// A circuit opened again after a resume by hand keeps its own cooldown.
+	job, _ = scheduler.AddJobWithError("@yearly", func() error { return errors.New("boom") },
+		WithCircuitBreaker(CircuitBreaker{Threshold: 1, Cooldown: 100 * time.Millisecond}))
+	_ = scheduler.RunNowAndWait(context.Background(), job.ID)
+	time.Sleep(60 * time.Millisecond)
+	_ = scheduler.ResumeJob(job.ID)
+	_ = scheduler.RunNowAndWait(context.Background(), job.ID)
+	time.Sleep(70 * time.Millisecond)
+	if info, _ := scheduler.JobInfo(job.ID); !info.Paused {
+		t.Error("Expected the first cooldown not to close the reopened circuit")
+	}
+	time.Sleep(80 * time.Millisecond)
+	if info, _ := scheduler.JobInfo(job.ID); info.Paused {
+		t.Error("Expected the reopened circuit to close after its own cooldown")
+	}
+}
+
+// TestPanicLimit tests that a job is disabled after repeated panics and
+// reported by Health until it is resumed.
+func TestPanicLimit(t *testing.T) {
+	scheduler := NewCronScheduler()
+	scheduler.SetPanicHandler(func(string, any, []byte) {})
+	events := make(chan JobEvent, 100)
+	scheduler.Subscribe(events)
+	_, _ = scheduler.AddNamedJob("panics", "@yearly", func() { panic("boom") }, WithPanicLimit(3, time.Hour))
+
+	for i := 0; i < 2; i++ {
+		_ = scheduler.RunNowAndWait(context.Background(), "panics")
+	}
+	if health := scheduler.Health(); len(health.Disabled) != 0 {
+		t.Fatalf("Expected no disabled job below the limit, got %v", health.Disabled)
+	}
+	_ = scheduler.RunNowAndWait(context.Background(), "panics")
+	if health := scheduler.Health(); !reflect.DeepEqual(health.Disabled, []string{"panics"}) {
+		t.Errorf("Expected the job to be reported disabled, got %v", health.Disabled)
+	}
+	if info, _ := scheduler.JobInfo("panics"); !info.Paused {
+		t.Error("Expected a disabled job to be paused")
+	}
+	var disabled []JobEvent
+	for len(events) > 0 {
+		if event := <-events; event.Type == EventDisabled {
+			disabled = append(disabled, event)
+		}
+	}
+	var panicErr *PanicError
+	if len(disabled) != 1 || !errors.As(disabled[0].Err, &panicErr) {
+		t.Errorf("Expected one EventDisabled with the panic, got %v", disabled)
+	}
+
+	_ = scheduler.ResumeJob("panics")
+	_ = scheduler.RunNowAndWait(context.Background(), "panics")
+	if health := scheduler.Health(); len(health.Disabled) != 0 {
+		t.Errorf("Expected a resumed job to start counting panics again, got %v", health.Disabled)
+	}
+}
+
+// TestOnComplete tests that the completion callback receives the run's
+// result.
+func TestOnComplete(t *testing.T) {
+	scheduler := NewCronScheduler()
+	var mu sync.Mutex
+	var results []RunResult
+	var count int
+	job, _ := scheduler.AddJobWithError("@yearly", func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		count++
+		if count < 3 {
+			return errors.New("flaky")
+		}
+		return nil
+	}, WithRetry(RetryPolicy{MaxAttempts: 3}), WithOnComplete(func(result RunResult) {
+		mu.Lock()
+		results = append(results, result)
+		mu.Unlock()
+	}))
+
+	before := time.Now()
+	if err := scheduler.RunNowAndWait(context.Background(), job.ID); err != nil {
+		t.Fatalf("Expected the run to succeed on its last attempt: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(results) != 1 {
+		t.Fatalf("Expected one result, got %d", len(results))
+	}
+	result := results[0]
+	if result.JobID != job.ID || result.Outcome != OutcomeSuccess || result.Err != nil || result.Attempt != 3 ||
+		!result.Scheduled.IsZero() || result.Start.Before(before) {
+		t.Errorf("Unexpected result %+v", result)
+	}
+}
+
+// TestPinger tests that runs are pinged to Healthchecks.io and Cronitor
+// style URLs.
+func TestPinger(t *testing.T) {
+	pings := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		pings <- r.Method + " " + r.URL.RequestURI() + " " + string(body)
+	}))
+	defer server.Close()
+	receive := func(n int) []string {
+		var got []string
+		timeout := time.After(time.Second)
+		for len(got) < n {
+			select {
+			case ping := <-pings:
+				got = append(got, ping)
+			case <-timeout:
+				t.Fatalf("Expected %d pings, got %v", n, got)
+			}
+		}
+		sort.Strings(got)
+		return got
+	}
+
+	scheduler := NewCronScheduler()
+	_, _ = scheduler.AddNamedJob("backup", "@yearly", func() {})
+	_, _ = scheduler.AddJobWithError("@yearly", func() error { return errors.New("disk full") })
+	_, _ = scheduler.AddNamedJob("quiet", "@yearly", func() {})
+	pinger := &Pinger{URL: server.URL + "/key/{job}", Slug: func(id string) string {
+		return map[string]string{"backup": "backup", "job-1": "sync"}[id]
+	}}
+	stop := pinger.Watch(scheduler)
+	_ = scheduler.RunNowAndWait(context.Background(), "backup")
+	_ = scheduler.RunNowAndWait(context.Background(), "job-1")
+	_ = scheduler.RunNowAndWait(context.Background(), "quiet")
+	want := []string{"GET /key/backup ", "GET /key/backup/start ", "GET /key/sync/start ", "POST /key/sync/fail disk full"}
+	if got := receive(4); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected Healthchecks pings %q, got %q", want, got)
+	}
+	stop()
+
+	cronitor := &Pinger{URL: server.URL + "/p/key/{job}", Service: Cronitor}
+	stop = cronitor.Watch(scheduler)
+	defer stop()
+	_ = scheduler.RunNowAndWait(context.Background(), "job-1")
+	want = []string{"GET /p/key/job-1?message=disk+full&state=fail ", "GET /p/key/job-1?state=run "}
+	if got := receive(2); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected Cronitor pings %q, got %q", want, got)
+	}
+	select {
+	case ping := <-pings:
+		t.Errorf("Unexpected ping %q", ping)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestWebhook tests that job events are posted signed, and that failed
+// deliveries are retried.
+func TestWebhook(t *testing.T) {
+	secret := []byte("s3cret")
+	var mu sync.Mutex
+	var attempts int
+	payloads := make(chan WebhookPayload, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(body)
+		if r.Header.Get(SignatureHeader) != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+			t.Errorf("Unexpected signature %q", r.Header.Get(SignatureHeader))
+		}
+		mu.Lock()
+		attempts++
+		first := attempts == 1
+		mu.Unlock()
+		if first {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var payload WebhookPayload
+		_ = json.Unmarshal(body, &payload)
+		payloads <- payload
+	}))
+	defer server.Close()
+
+	scheduler := NewCronScheduler()
+	job, _ := scheduler.AddJobWithError("@yearly", func() error { return errors.New("boom") })
+	webhook := &Webhook{
+		URLs:   []string{server.URL},
+		Events: []EventType{EventFailed},
+		Secret: secret,
+		Retry:  RetryPolicy{MaxAttempts: 2, Delay: time.Millisecond},
+	}
+	stop := webhook.Watch(scheduler)
+	defer stop()
+	_ = scheduler.RunNowAndWait(context.Background(), job.ID)
+
+	select {
+	case payload := <-payloads:
+		if payload.Event != "failed" || payload.JobID != job.ID || payload.Error != "boom" || payload.Time.IsZero() {
+			t.Errorf("Unexpected payload %+v", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the failure to be delivered on retry")
+	}
+	select {
+	case payload := <-payloads:
+		t.Errorf("Expected only the failed event to be posted, got %+v", payload)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestEmailAlerter tests that alerts are emailed after the failures of a
+// job's rule, to the recipients of its job or group rule.
+func TestEmailAlerter(t *testing.T) {
+	type mail struct {
+		to  []string
+		msg string
+	}
+	mails := make(chan mail, 10)
+	const evil = "evil\r\nBcc: victim@example.com"
+	alerter := &EmailAlerter{
+		Addr:    "smtp.example.com:25",
+		From:    "cron@example.com",
+		Default: AlertRule{To: []string{"ops@example.com"}, Failures: 2},
+		Jobs:    map[string]AlertRule{evil: {To: []string{"ops@example.com"}}},
+		Groups:  map[string]AlertRule{"billing": {To: []string{"billing@example.com"}}},
+		Send: func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+			mails <- mail{to, string(msg)}
+			return nil
+		},
+	}
+	scheduler := NewCronScheduler()
+	job, _ := scheduler.AddJobWithError("@yearly", func() error { return errors.New("boom") })
+	invoice, _ := scheduler.AddJobWithError("@yearly", func() error { return errors.New("declined") }, WithGroup("billing"))
+	_, _ = scheduler.AddNamedJob(evil, "@yearly", func() { panic("boom") })
+	scheduler.SetPanicHandler(func(string, any, []byte) {})
+	stop := alerter.Watch(scheduler)
+	defer stop()
+
+	receive := func() mail {
+		select {
+		case m := <-mails:
+			return m
+		case <-time.After(time.Second):
+			t.Fatal("Expected an alert")
+			return mail{}
+		}
+	}
+	_ = scheduler.RunNowAndWait(context.Background(), invoice.ID)
+	if m := receive(); !reflect.DeepEqual(m.to, []string{"billing@example.com"}) ||
+		!strings.Contains(m.msg, "Subject: [cronjob] job "+invoice.ID+" failed\r\n") || !strings.Contains(m.msg, "declined") {
+		t.Errorf("Unexpected group alert %+v", m)
+	}
+
+	for i := 0; i < 3; i++ {
+		_ = scheduler.RunNowAndWait(context.Background(), job.ID)
+	}
+	if m := receive(); !reflect.DeepEqual(m.to, []string{"ops@example.com"}) ||
+		!strings.Contains(m.msg, "failed 2 times in a row") || !strings.Contains(m.msg, "boom") {
+		t.Errorf("Unexpected default alert %+v", m)
+	}
+	select {
+	case m := <-mails:
+		t.Errorf("Expected one alert per streak of failures, got %+v", m)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	_ = scheduler.RunNowAndWait(context.Background(), evil)
+	header, _, _ := strings.Cut(receive().msg, "\r\n\r\n")
+	if strings.Contains(header, "\r\nBcc:") {
+		t.Errorf("Expected line breaks in the subject to be encoded, got %q", header)
+	}
+}
+
+// TestExecJob tests that commands run with their environment and working
+// directory, with their output captured in the run history.
+func TestExecJob(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	scheduler := NewCronScheduler()
+	dir := t.TempDir()
+	job, err := scheduler.AddExecJob("@yearly", ExecJob{
+		Command: "sh",
+		Args:    []string{"-c", `echo "$GREETING from $(pwd)"; echo oops >&2; exit 3`},
+		Env:     []string{"GREETING=hello"},
+		Dir:     dir,
+	})
+	if err != nil {
+		t.Fatalf("Failed to add exec job: %v", err)
+	}
+	var exitErr *exec.ExitError
+	if err := scheduler.RunNowAndWait(context.Background(), job.ID); !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Errorf("Expected the exit status to fail the run, got %v", err)
+	}
+	history, _ := scheduler.History(job.ID)
+	if len(history) != 1 || history[0].Stdout != "hello from "+dir+"\n" || history[0].Stderr != "oops\n" {
+		t.Errorf("Unexpected run records %+v", history)
+	}
+
+	job, _ = scheduler.AddExecJob("@yearly", ExecJob{Command: "sleep", Args: []string{"5"}, Timeout: 50 * time.Millisecond})
+	start := time.Now()
+	if err := scheduler.RunNowAndWait(context.Background(), job.ID); err == nil || !strings.Contains(err.Error(), "killed after 50ms") {
+		t.Errorf("Expected the timeout to kill the command, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the command to be killed promptly, took %v", elapsed)
+	}
+
+	buf := &limitedBuffer{limit: 4}
+	_, _ = buf.Write([]byte("abc"))
+	_, _ = buf.Write([]byte("def"))
+	if string(buf.buf) != "abcd" {
+		t.Errorf("Expected output to be capped, got %q", buf.buf)
+	}
+}
+
+// TestLoadCrontab tests that crontab entries become exec jobs with the
+// environment, shell and time zone set before them.
+func TestLoadCrontab(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	scheduler := NewCronScheduler()
+	crontab := `# backups
+MAILTO=ops@example.com
+GREETING = "hello world"
+*/15 9-17 * * mon-fri  echo "$GREETING" to $MAILTO
+CRON_TZ=America/New_York
+@daily   cat%first line%second \% line
+`
+	ids, err := scheduler.LoadCrontab(strings.NewReader(crontab))
+	if err != nil {
+		t.Fatalf("Failed to load crontab: %v", err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("Expected 2 jobs, got %v", ids)
+	}
+	first, _ := scheduler.GetJob(ids[0])
+	second, _ := scheduler.GetJob(ids[1])
+	if first.Expression() != "*/15 9-17 * * mon-fri" || second.Expression() != "@daily" {
+		t.Errorf("Unexpected expressions %q and %q", first.Expression(), second.Expression())
+	}
+	if first.location != time.Local || second.location.String() != "America/New_York" {
+		t.Errorf("Unexpected locations %v and %v", first.location, second.location)
+	}
+
+	for _, id := range ids {
+		_ = scheduler.RunNowAndWait(context.Background(), id)
+	}
+	history, _ := scheduler.History(ids[0])
+	if len(history) != 1 || history[0].Stdout != "hello world to ops@example.com\n" {
+		t.Errorf("Unexpected output of the first entry: %+v", history)
+	}
+	history, _ = scheduler.History(ids[1])
+	if len(history) != 1 || history[0].Stdout != "first line\nsecond % line\n" {
+		t.Errorf("Unexpected output of the second entry: %+v", history)
+	}
+
+	invalid := []string{
+		"* * * * *\n",
+		"61 * * * * echo\n",
+		"CRON_TZ=Nowhere/Special\n",
+		"@every\n",
+	}
+	for _, crontab := range invalid {
+		if _, err := scheduler.LoadCrontab(strings.NewReader(crontab)); err == nil {
+			t.Errorf("Expected an error for %q", crontab)
+		}
+	}
+	if len(scheduler.ListJobInfo()) != 2 {
+		t.Error("Expected invalid crontabs to add no jobs")
+	}
+}
+
+// TestCronTimeZonePrefix tests that a CRON_TZ= prefix evaluates the
+// expression in its time zone.
+func TestCronTimeZonePrefix(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	expr, err := ParseCronExpression("CRON_TZ=America/New_York 0 9 * * Mon")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	from := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	want := time.Date(2025, 1, 6, 9, 0, 0, 0, newYork)
+	if got := expr.Next(from); !got.Equal(want) || got.Location() != time.UTC {
+		t.Errorf("Expected %v in UTC, got %v", want, got)
+	}
+	if got := expr.String(); got != "CRON_TZ=America/New_York 0 9 * * 1" {
+		t.Errorf("Unexpected string %q", got)
+	}
+	if tz, _ := ParseCronExpression("TZ=America/New_York 0 9 * * 1"); !expr.Equal(tz) {
+		t.Error("Expected the TZ= prefix to be equivalent")
+	}
+	if plain, _ := ParseCronExpression("0 9 * * 1"); expr.Equal(plain) {
+		t.Error("Expected expressions in different time zones to differ")
+	}
+	for _, invalid := range []string{"CRON_TZ=Nowhere/Special 0 9 * * *", "CRON_TZ= 0 9 * * *", "CRON_TZ=UTC"} {
+		if _, err := ParseCronExpression(invalid); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+
+	// The prefix overrides the job's location.
+	scheduler := NewCronScheduler()
+	job, _ := scheduler.AddJob("CRON_TZ=America/New_York 0 9 * * *", func() {}, WithLocation(time.UTC))
+	id := job.ID
+	runs, _ := scheduler.NextRuns(id, 1)
+	if len(runs) != 1 || runs[0].In(newYork).Hour() != 9 {
+		t.Errorf("Expected the job to fire at 09:00 in New York, got %v", runs)
+	}
+}
+
+// TestHTTPJob tests that requests are sent as configured and their status
+// codes recorded in the run history.
+func TestHTTPJob(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || r.Header.Get("Authorization") != "Bearer token" || string(body) != "{}" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	scheduler := NewCronScheduler()
+	job, err := scheduler.AddHTTPJob("*/5 * * * *", HTTPJob{
+		URL:     server.URL,
+		Method:  http.MethodPost,
+		Headers: http.Header{"Authorization": {"Bearer token"}},
+		Body:    "{}",
+	})
+	if err != nil {
+		t.Fatalf("Failed to add HTTP job: %v", err)
+	}
+	if err := scheduler.RunNowAndWait(context.Background(), job.ID); err != nil {
+		t.Errorf("Expected a 2xx response to succeed, got %v", err)
+	}
+	strict, _ := scheduler.AddHTTPJob("@hourly", HTTPJob{URL: server.URL, ExpectedStatus: http.StatusOK})
+	if err := scheduler.RunNowAndWait(context.Background(), strict.ID); err == nil || !strings.Contains(err.Error(), "unexpected status 400") {
+		t.Errorf("Expected an unexpected status to fail the run, got %v", err)
+	}
+
+	for id, want := range map[string]int{job.ID: http.StatusAccepted, strict.ID: http.StatusBadRequest} {
+		if history, _ := scheduler.History(id); len(history) != 1 || history[0].StatusCode != want {
+			t.Errorf("Expected status %d in the history of %s, got %+v", want, id, history)
+		}
+	}
+}
+
+// TestExecJobSoftFailure tests that soft exit codes are recorded without
+// failing the run, and that old runs lose their output past the retention.
+func TestExecJobSoftFailure(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	var mu sync.Mutex
+	var failures int
+	scheduler := NewCronScheduler()
+	scheduler.OnError(func(string, error) {
+		mu.Lock()
+		failures++
+		mu.Unlock()
+	})
+	job, err := scheduler.AddExecJob("@yearly", ExecJob{
+		Command:       "sh",
+		Args:          []string{"-c", `echo run; exit 75`},
+		SoftExitCodes: []int{75},
+	}, WithRetry(RetryPolicy{MaxAttempts: 3}), WithOutputRetention(2))
+	if err != nil {
+		t.Fatalf("Failed to add exec job: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := scheduler.RunNowAndWait(context.Background(), job.ID); err != nil {
+			t.Errorf("Expected a soft failure not to fail the run, got %v", err)
+		}
+	}
+	history, _ := scheduler.History(job.ID)
+	if len(history) != 3 {
+		t.Fatalf("Expected 3 run records, got %+v", history)
+	}
+	for i, record := range history {
+		if record.Outcome != OutcomeSoftFailure || !errors.Is(record.Err, ErrSoftFailure) {
+			t.Errorf("Expected run %d to be a soft failure, got %v: %v", i, record.Outcome, record.Err)
+		}
+		if want := map[bool]string{true: "run\n"}[i > 0]; record.Stdout != want {
+			t.Errorf("Expected run %d to have output %q, got %q", i, want, record.Stdout)
+		}
+	}
+	stats, _ := scheduler.Stats(job.ID)
+	mu.Lock()
+	defer mu.Unlock()
+	if failures != 0 || stats.Failures != 0 || stats.Runs != 3 {
+		t.Errorf("Expected soft failures not to count as failures, got %d reported and %+v", failures, stats)
+	}
+}
+
+// TestJobsSnapshot tests that Jobs returns copies that can be read while
+// the jobs run.
+func TestJobsSnapshot(t *testing.T) {
+	scheduler := NewCronScheduler()
+	job, _ := scheduler.AddJob("@every 10ms", func() {}, WithMetadata(map[string]string{"team": "ops"}))
+	_, _ = scheduler.AddJob("0 6 * * *", func() {})
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	deadline := time.Now().Add(100 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		jobs := scheduler.Jobs()
+		if len(jobs) != 2 || jobs[0].ID != job.ID || jobs[1].Expression != "0 6 * * *" {
+			t.Fatalf("Unexpected jobs snapshot: %+v", jobs)
+		}
+		jobs[0].Metadata["team"] = "changed"
+	}
+	if info, _ := scheduler.JobInfo(job.ID); info.Metadata["team"] != "ops" || info.RunCount == 0 {
+		t.Errorf("Expected the snapshots not to affect the job, got %+v", info)
+	}
+}
+
+// TestJobAccessors tests that a job's accessors can be called while its
+// schedule is being updated.
+func TestJobAccessors(t *testing.T) {
+	scheduler := NewCronScheduler()
+	job, _ := scheduler.AddJob("0 6 * * *", func() {})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = scheduler.UpdateSchedule(job.ID, fmt.Sprintf("%d 6 * * *", i%60))
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		_, _, _ = job.Expression(), job.Schedule(), job.Task()
+	}
+	<-done
+	if job.Expression() != "39 6 * * *" || job.Schedule().(*CronExpression).String() != "39 6 * * *" || job.Task() == nil {
+		t.Errorf("Unexpected job after the updates: %q, %v", job.Expression(), job.Schedule())
+	}
+}
+
+// TestUpdateScheduleWhileRunning tests that a job's schedule can be updated
+// while its runs start, which the race detector checks.
+func TestUpdateScheduleWhileRunning(t *testing.T) {
+	scheduler := NewCronScheduler()
+	var mu sync.Mutex
+	var expressions []string
+	record := func(next JobHandler) JobHandler {
+		return func(ctx context.Context, run RunInfo) error {
+			mu.Lock()
+			expressions = append(expressions, run.Expression)
+			mu.Unlock()
+			return next(ctx, run)
+		}
+	}
+	job, _ := scheduler.AddJob("@every 2ms", func() { time.Sleep(3 * time.Millisecond) }, WithMiddleware(record))
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	for i := 0; i < 30; i++ {
+		time.Sleep(4 * time.Millisecond)
+		_ = scheduler.UpdateSchedule(job.ID, fmt.Sprintf("@every %dms", 2+i%2))
+	}
+	time.Sleep(10 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(expressions) == 0 {
+		t.Fatalf("Expected the job to run while its schedule was updated")
+	}
+	for _, expr := range expressions {
+		if expr != "@every 2ms" && expr != "@every 3ms" {
+			t.Errorf("Unexpected run expression %q", expr)
+		}
+	}
+}
+
+// TestRestart tests that a stopped scheduler can be started again, with its
+// jobs rescheduled and nothing firing while it is stopped.
+func TestRestart(t *testing.T) {
+	scheduler := NewCronScheduler()
+	var mu sync.Mutex
+	var ticks, reboots int
+	_, _ = scheduler.AddJob("@every 10ms", func() {
+		mu.Lock()
+		ticks++
+		mu.Unlock()
+	})
+	_, _ = scheduler.AddJob("@reboot", func() {
+		mu.Lock()
+		reboots++
+		mu.Unlock()
+	})
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return ticks
+	}
+
+	for cycle := 1; cycle <= 3; cycle++ {
+		before := count()
+		scheduler.Start()
+		if scheduler.runDueJobs(make(chan struct{}), scheduler.lane, time.Now()) {
+			t.Errorf("Cycle %d: expected a loop of an earlier start to exit", cycle)
+		}
+		deadline := time.Now().Add(time.Second)
+		for count() < before+2 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if err := scheduler.StopAndWait(context.Background()); err != nil {
+			t.Fatalf("Cycle %d: failed to stop: %v", cycle, err)
+		}
+		stopped := count()
+		if stopped < before+2 {
+			t.Fatalf("Cycle %d: expected the job to fire again, got %d runs", cycle, stopped-before)
+		}
+		time.Sleep(40 * time.Millisecond)
+		if n := count(); n != stopped {
+			t.Fatalf("Cycle %d: expected no runs while stopped, got %d", cycle, n-stopped)
+		}
+		if health := scheduler.Health(); health.Running {
+			t.Errorf("Cycle %d: expected the scheduler to report it is stopped", cycle)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if reboots != 3 {
+		t.Errorf("Expected the @reboot job to run on every start, got %d runs", reboots)
+	}
+}
+
+// TestPauseScheduler tests that a paused scheduler holds due runs and makes
+// them up on Resume according to each job's catch-up policy.
+func TestPauseScheduler(t *testing.T) {
+	scheduler := NewCronScheduler()
+	var mu sync.Mutex
+	runs := make(map[string]int)
+	task := func(name string) func() {
+		return func() {
+			mu.Lock()
+			runs[name]++
+			mu.Unlock()
+		}
+	}
+	fast, _ := scheduler.AddJob("@every 10ms", task("fast"))
+	policies := map[string]CatchUpPolicy{"ignore": IgnoreMissed, "once": RunOnceOnStartupIfMissed, "all": RunAllMissed}
+	for name, policy := range policies {
+		_, _ = scheduler.AddNamedJob(name, "@every 1h", task(name), WithCatchUp(policy))
+	}
+	scheduler.Pause()
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	time.Sleep(60 * time.Millisecond)
+	mu.Lock()
+	if len(runs) != 0 {
+		t.Errorf("Expected no runs while paused, got %v", runs)
+	}
+	mu.Unlock()
+	if health := scheduler.Health(); !health.Paused || !health.Healthy || !scheduler.Paused() {
+		t.Errorf("Expected a healthy paused scheduler, got %+v", health)
+	}
+	var held []*Job
+	for name := range policies {
+		job, _ := scheduler.GetJob(name)
+		held = append(held, job)
+	}
+	scheduler.mutex.Lock()
+	if fast.held < 2 {
+		t.Errorf("Expected the due runs to be held, got %d", fast.held)
+	}
+	fast.held = 0
+	for _, job := range held {
+		job.held = 3
+	}
+	scheduler.mutex.Unlock()
+
+	scheduler.Resume()
+	time.Sleep(60 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if runs["fast"] == 0 {
+		t.Errorf("Expected the job to fire again after Resume")
+	}
+	for name, want := range map[string]int{"ignore": 0, "once": 1, "all": 3} {
+		if runs[name] != want {
+			t.Errorf("Expected %d held runs of %s to be made up, got %d", want, name, runs[name])
+		}
+	}
+}
+
+// TestWithResolution tests that fire times are rounded up to the
+// scheduler's resolution, at most one per period.
+func TestWithResolution(t *testing.T) {
+	scheduler := NewCronScheduler(WithResolution(time.Minute))
+	for _, expr := range []string{"15,45 * * * * *", "@every 10s", "*/5 * * * *"} {
+		job, err := scheduler.AddJob(expr, func() {})
+		if err != nil {
+			t.Fatalf("Failed to add %q: %v", expr, err)
+		}
+		runs, _ := scheduler.NextRuns(job.ID, 3)
+		for i, run := range runs {
+			if !run.Equal(run.Truncate(time.Minute)) || i > 0 && !run.After(runs[i-1]) {
+				t.Errorf("%q: expected distinct whole-minute fire times, got %v", expr, runs)
+				break
+			}
+		}
+		if expr == "15,45 * * * * *" && len(runs) == 3 && runs[2].Sub(runs[0]) != 2*time.Minute {
+			t.Errorf("Expected one run per minute, got %v", runs)
+		}
+	}
+
+	at := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	if got := roundUp(at, time.Minute); !got.Equal(at) {
+		t.Errorf("Expected a whole minute to be kept, got %v", got)
+	}
+	if got := roundUp(at.Add(time.Second), time.Minute); !got.Equal(at.Add(time.Minute)) {
+		t.Errorf("Expected rounding up to the next minute, got %v", got)
+	}
+}
+
+// TestQueueAllBackpressure tests that QueueAll runs every overlapping run in
+// turn, reporting the queue depth and an EventBackpressure once the queued
+// runs reach the threshold.
+func TestQueueAllBackpressure(t *testing.T) {
+	scheduler := NewCronScheduler(WithBackpressureThreshold(3))
+	events := make(chan JobEvent, 100)
+	scheduler.Subscribe(events)
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var runs int
+	job, _ := scheduler.AddJob("@yearly", func() {
+		<-release
+		mu.Lock()
+		runs++
+		mu.Unlock()
+	}, WithOverlapPolicy(QueueAll))
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	for i := 0; i < 4; i++ {
+		_ = scheduler.RunNow(job.ID)
+	}
+	if n := scheduler.QueueDepth(); n != 3 {
+		t.Errorf("Expected 3 queued runs, got %d", n)
+	}
+	if info, _ := scheduler.JobInfo(job.ID); info.QueueDepth != 3 {
+		t.Errorf("Expected a queue depth of 3 in the job info, got %d", info.QueueDepth)
+	}
+	if health := scheduler.Health(); health.Queued != 3 {
+		t.Errorf("Expected 3 queued runs in the health status, got %d", health.Queued)
+	}
+	var backpressure []JobEvent
+	for len(events) > 0 {
+		if e := <-events; e.Type == EventBackpressure {
+			backpressure = append(backpressure, e)
+		}
+	}
+	if len(backpressure) != 1 || backpressure[0].JobID != job.ID || backpressure[0].Queued != 3 {
+		t.Errorf("Expected one EventBackpressure at 3 queued runs, got %+v", backpressure)
+	}
+
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) && scheduler.QueueDepth() > 0 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if runs != 4 || scheduler.QueueDepth() != 0 {
+		t.Errorf("Expected all 4 runs with an empty queue, got %d runs and %d queued", runs, scheduler.QueueDepth())
+	}
+}
+
+// TestOccurrenceFiresOnce tests that an occurrence runs at most once, even
+// if the job is queued for it again and the loop handles it twice.
+func TestOccurrenceFiresOnce(t *testing.T) {
+	scheduler := NewCronScheduler()
+	var mu sync.Mutex
+	runs := 0
+	job, err := scheduler.AddJob("0 0 0 1 1 *", func() {
+		mu.Lock()
+		runs++
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduler.Start()
+	defer scheduler.Stop()
+	count := func(want int) int {
+		deadline := time.Now().Add(500 * time.Millisecond)
+		for {
+			mu.Lock()
+			n := runs
+			mu.Unlock()
+			if n >= want || time.Now().After(deadline) {
+				return n
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	tick := time.Now().Truncate(time.Second)
+	fire := func(at time.Time) {
+		scheduler.mutex.Lock()
+		job.next = at
+		stop, l := scheduler.stop, scheduler.lane
+		scheduler.mutex.Unlock()
+		if !scheduler.runDueJobs(stop, l, at) {
+			t.Fatal("Expected the running scheduler to handle due jobs")
+		}
+	}
+	fire(tick)
+	if n := count(1); n != 1 {
+		t.Fatalf("Expected the occurrence to run, got %d runs", n)
+	}
+	fire(tick)
+	fire(tick.Add(-time.Minute))
+	if n := count(2); n != 1 {
+		t.Errorf("Expected a handled occurrence not to run again, got %d runs", n)
+	}
+	fire(tick.Add(time.Second))
+	if n := count(2); n != 2 {
+		t.Errorf("Expected the next occurrence to run, got %d runs", n)
+	}
+}
+
+// TestManyJobs tests that a scheduler with 50,000 jobs keeps looking up,
+// removing and firing jobs correctly, including a dependent whose
+// dependency was replaced.
+func TestManyJobs(t *testing.T) {
+	scheduler := NewCronScheduler()
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	var ids []string
+	for i := 0; i < 50000; i++ {
+		job, err := scheduler.AddJob("0 0 1 1 *", func() {})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, job.ID)
+	}
+	for i := 0; i < len(ids); i += 50 {
+		if err := scheduler.RemoveJob(ids[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := scheduler.GetJob(ids[50]); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected removed job to be gone, got %v", err)
+	}
+	if _, err := scheduler.GetJob(ids[51]); err != nil {
+		t.Errorf("Expected remaining job to be found, got %v", err)
+	}
+
+	var mu sync.Mutex
+	var runs int
+	_, _ = scheduler.AddNamedJob("extract", "* * * * * *", func() {})
+	_, _ = scheduler.AddNamedJob("load", "* * * * * *", func() {
+		mu.Lock()
+		runs++
+		mu.Unlock()
+	}, WithDependsOn("extract"))
+	_, _ = scheduler.UpsertJob("extract", "* * * * * *", func() {})
+
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := runs
+		mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if runs == 0 {
+		t.Error("Expected the dependent job to run among 49,000 idle jobs")
+	}
+	jobs := scheduler.Jobs()
+	if len(jobs) != 49002 || jobs[0].ID != ids[1] || jobs[len(jobs)-1].ID != "extract" {
+		t.Errorf("Expected 49,002 jobs in the order they were added, got %d", len(jobs))
+	}
+}
+
+// TestPriority tests that runs waiting for a concurrency slot or a worker
+// start in priority order, unless a run has waited past the starvation
+// limit.
+func TestPriority(t *testing.T) {
+	for name, opt := range map[string]SchedulerOption{"limit": WithMaxConcurrentJobs(1), "workers": WithWorkers(1)} {
+		t.Run(name, func(t *testing.T) {
+			scheduler := NewCronScheduler(opt, WithStarvationLimit(200*time.Millisecond))
+			var mu sync.Mutex
+			var order []string
+			record := func(id string) func() {
+				return func() {
+					mu.Lock()
+					order = append(order, id)
+					mu.Unlock()
+				}
+			}
+			release := make(chan struct{})
+			_, _ = scheduler.AddNamedJob("blocker", "0 0 1 1 *", func() { <-release })
+			_, _ = scheduler.AddNamedJob("low", "0 0 1 1 *", record("low"), WithPriority(-1))
+			_, _ = scheduler.AddNamedJob("high", "0 0 1 1 *", record("high"), WithPriority(10))
+			scheduler.Start()
+			defer scheduler.Stop()
+
+			block := func(wait time.Duration, runs int) {
+				_ = scheduler.RunNow("blocker")
+				time.Sleep(20 * time.Millisecond)
+				_ = scheduler.RunNow("low")
+				time.Sleep(wait)
+				_ = scheduler.RunNow("high")
+				time.Sleep(20 * time.Millisecond)
+				release <- struct{}{}
+				deadline := time.Now().Add(time.Second)
+				for time.Now().Before(deadline) {
+					mu.Lock()
+					n := len(order)
+					mu.Unlock()
+					if n >= runs {
+						break
+					}
+					time.Sleep(5 * time.Millisecond)
+				}
+			}
+			block(0, 2)
+			block(300*time.Millisecond, 4)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if !reflect.DeepEqual(order, []string{"high", "low", "low", "high"}) {
+				t.Errorf("Expected high before low, then the starved low first, got %v", order)
+			}
+			if info, _ := scheduler.JobInfo("high"); info.Priority != 10 {
+				t.Errorf("Expected priority 10 in the job info, got %d", info.Priority)
+			}
+		})
+	}
+}
+
+// BenchmarkAddJob adds jobs to a running scheduler that already has 50,000.
+func BenchmarkAddJob(b *testing.B) {
+	scheduler := NewCronScheduler()
+	for i := 0; i < 50000; i++ {
+		_, _ = scheduler.AddJob("0 0 1 1 *", func() {})
+	}
+	scheduler.Start()
+	defer scheduler.Stop()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = scheduler.AddJob("0 0 1 1 *", func() {})
+	}
+}
+
+// BenchmarkIsTimeMatching matches a time against 10,000 expressions, with
+// the bitmask field sets and with linear scans of the value slices they
+// replaced.
+func BenchmarkIsTimeMatching(b *testing.B) {
+	layouts := []string{"* * * * *", "*/5 * * * *", "0,15,30,45 9-17 * * 1-5", "H H * * *", "30 2 1,15 * *", "59 23 * * 0,6"}
+	exprs := make([]*CronExpression, 10000)
+	for i := range exprs {
+		exprs[i], _ = ParseCronExpression(layouts[i%len(layouts)], WithHashKey(fmt.Sprintf("job-%d", i)))
+	}
+	at := time.Date(2025, 3, 15, 23, 59, 0, 0, time.UTC)
+
+	b.Run("bitset", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, expr := range exprs {
+				isTimeMatching(expr, at)
+			}
+		}
+	})
+	b.Run("slices", func(b *testing.B) {
+		type fields struct{ seconds, minutes, hours, days, months, weekdays []int }
+		sets := make([]fields, len(exprs))
+		for i, expr := range exprs {
+			sets[i] = fields{expr.Seconds(), expr.Minutes(), expr.Hours(), expr.DayOfMonth(), expr.Month(), expr.DayOfWeek()}
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for _, f := range sets {
+				_ = contains(f.seconds, at.Second()) && contains(f.minutes, at.Minute()) && contains(f.hours, at.Hour()) &&
+					contains(f.months, int(at.Month())) && contains(f.days, at.Day()) && contains(f.weekdays, int(at.Weekday()))
+			}
+		}
+	})
+}
+
+// TestValidate tests that validation errors name the faulty field.
+func TestValidate(t *testing.T) {
+	if err := Validate("*/5 * * * *"); err != nil {
+		t.Errorf("Expected a valid expression, got %v", err)
+	}
+
+	tests := []struct {
+		expr    string
+		field   string
+		index   int
+		message string
+	}{
+		{"75 * * * *", "minute", 1, `minute field, token "75" at position 1: value 75 out of range 0-59`},
+		{"0 10-25 * * *", "hour", 2, `hour field, token "10-25" at position 3: invalid range end: value 25 out of range 0-23`},
+		{"0 0 * Foo *", "month", 4, `month field, token "Foo" at position 7: invalid value: Foo`},
+		{"0 0 * * Mon,SunFunday", "day-of-week", 5, `day-of-week field, token "SunFunday" at position 13: invalid value: SunFunday`},
+		{"0 0 * * Mon#9", "day-of-week", 5, `day-of-week field, token "Mon#9" at position 9: invalid nth weekday: Mon#9`},
+		{"0 0 0 1 1 * 1900", "year", 7, `year field, token "1900" at position 13: value 1900 out of range 1970-2099`},
+		{"1,2, * * * *", "minute", 1, `minute field at position 5: empty value`},
+		{"0 ,5 * * *", "hour", 2, `hour field at position 3: empty value`},
+		{"*/99999999999999999999 * * * *", "minute", 1, `minute field, token "*/99999999999999999999" at position 1: invalid step: 99999999999999999999`},
+		{"0 H,H/5,25 * * *", "hour", 2, `hour field, token "25" at position 9: value 25 out of range 0-23`},
+		{"CRON_TZ=UTC  0 0 * * Mon-Sunday", "day-of-week", 5, `day-of-week field, token "Mon-Sunday" at position 22: invalid range end: invalid value: Sunday`},
+	}
+	for _, tt := range tests {
+		err := Validate(tt.expr)
+		if strings.Count(tt.expr, " ") > 4 && !strings.Contains(tt.expr, "=") {
+			err = Validate(tt.expr, WithSeconds())
+		}
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) || fieldErr.Field != tt.field || fieldErr.Index != tt.index {
+			t.Errorf("%q: expected a %s field error for field %d, got %v", tt.expr, tt.field, tt.index, err)
+			continue
+		}
+		if err.Error() != tt.message {
+			t.Errorf("%q: expected %q, got %q", tt.expr, tt.message, err.Error())
+		}
+	}
+
+	if err := Validate("* * *"); err == nil || err.Error() != "invalid cron expression: * * *: expected 5 fields, got 3" {
+		t.Errorf("Unexpected error for a short expression: %v", err)
+	}
+}
+
+// TestAnalyze tests that Analyze warns about days some listed months lack
+// and about expressions that can never fire.
+func TestAnalyze(t *testing.T) {
+	from := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expr     string
+		dayAnd   bool
+		warnings []string
+		never    bool
+	}{
+		{"0 0 31 2 *", false, []string{"day-of-month field: day 31 does not exist in February"}, true},
+		{"0 0 30,31 2 *", false, []string{
+			"day-of-month field: day 30 does not exist in February",
+			"day-of-month field: day 31 does not exist in February",
+		}, true},
+		{"0 0 31 * *", false, []string{
+			"day-of-month field: day 31 does not exist in February, April, June, September and November",
+		}, false},
+		{"0 0 29 2 *", false, []string{"day-of-month field: day 29 does not exist in February outside leap years"}, false},
+		{"0 0 L-29 1-3 *", false, []string{"day-of-month field: L-29 does not exist in February"}, false},
+		{"0 0 30W 4 *", false, nil, false},
+		{"0 0 0 29 2 * 2027", false, []string{"day-of-month field: day 29 does not exist in February outside leap years"}, true},
+		{"0 0 0 1 1 * 2020-2025", false, nil, true},
+		{"0 0 0 1 1 * 2090", false, nil, false},
+		{"0 0 31 2 Mon", false, []string{"day-of-month field: day 31 does not exist in February"}, false},
+		{"0 0 1-7 * Mon#2", false, nil, false},
+		{"0 0 1-7 * Mon#2", true, nil, true},
+		{"0 0 15 * *", false, nil, false},
+		{"0 0 L * *", false, nil, false},
+		{"@every 5m", false, nil, false},
+	}
+	for _, tt := range tests {
+		expr, err := ParseCronExpressionMode(tt.expr, ParseAuto)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", tt.expr, err)
+		}
+		if tt.dayAnd {
+			expr.DayMatching = DayAnd
+		}
+		var got []string
+		never := false
+		for _, w := range analyze(expr, from) {
+			if w.Never {
+				never = true
+				continue
+			}
+			got = append(got, w.String())
+		}
+		if !reflect.DeepEqual(got, tt.warnings) || never != tt.never {
+			t.Errorf("%q: expected warnings %q (never %v), got %q (never %v)", tt.expr, tt.warnings, tt.never, got, never)
+		}
+	}
+
+	if _, err := ValidateWarnings("0 0 32 * *"); err == nil {
+		t.Error("Expected ValidateWarnings to reject an invalid expression")
+	}
+	if warnings, err := ValidateWarnings("0 0 31 2 *"); err != nil || len(warnings) != 2 || !warnings[1].Never {
+		t.Errorf("Expected a never-firing warning, got %v, %v", warnings, err)
+	}
+
+	var buf bytes.Buffer
+	scheduler := NewCronScheduler(WithLogger(slog.New(slog.NewTextHandler(&buf, nil))))
+	if warnings, err := scheduler.ValidateWarnings("0 0 0 31 2 *"); err != nil || len(warnings) != 2 {
+		t.Errorf("Expected the scheduler to report 2 warnings, got %v, %v", warnings, err)
+	}
+	if warnings, err := scheduler.ValidateWarnings("R/2026-01-01T00:00:00Z/PT1H"); err != nil || warnings != nil {
+		t.Errorf("Expected no warnings for a repeating interval, got %v, %v", warnings, err)
+	}
+	if _, err := scheduler.AddJob("0 0 0 31 2 *", func() {}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "day 31 does not exist in February") || !strings.Contains(buf.String(), "never fires") {
+		t.Errorf("Expected AddJob to log the warnings, got %q", buf.String())
+	}
+}
+
+// TestStrictParsing tests that Strict rejects suspicious fields that
+// Lenient accepts, and that the scheduler applies its strictness.
+func TestStrictParsing(t *testing.T) {
+	tests := []struct {
+		expr    string
+		message string
+	}{
+		{"10-5 * * * *", `minute field, token "10-5" at position 1: range start is after its end: 10-5`},
+		{"0 0 * * Fri-Mon", `day-of-week field, token "Fri-Mon" at position 9: range start is after its end: Fri-Mon`},
+		{"*/90 * * * *", `minute field, token "*/90" at position 1: step 90 is larger than the range of */90`},
+		{"0 0 * 1-3/5 *", `month field, token "1-3/5" at position 7: step 5 is larger than the range of 1-3/5`},
+		{"0 0 * * Mon-5", `day-of-week field, token "Mon-5" at position 9: range mixes names and numbers: Mon-5`},
+		{"0 0 * Jan-6 *", `month field, token "Jan-6" at position 7: range mixes names and numbers: Jan-6`},
+	}
+	for _, tt := range tests {
+		if err := Validate(tt.expr); err != nil {
+			t.Errorf("%q: expected Lenient to accept it, got %v", tt.expr, err)
+		}
+		if err := Validate(tt.expr, WithStrictness(Strict)); err == nil || err.Error() != tt.message {
+			t.Errorf("%q: expected %q, got %v", tt.expr, tt.message, err)
+		}
+	}
+	for _, expr := range []string{"0 9-17/2 * * Mon-Fri", "0 0 * * Fri-Sun", "0 0 * * 5-7", "*/15 * * * *", "0 0 1-31/30 * *"} {
+		if err := Validate(expr, WithStrictness(Strict)); err != nil {
+			t.Errorf("%q: expected Strict to accept it, got %v", expr, err)
+		}
+	}
+	if err := Validate("10-5/0 * * * *"); err == nil {
+		t.Error("Expected a zero step to be rejected in both modes")
+	}
+
+	scheduler := NewCronScheduler(WithParseStrictness(Strict))
+	if _, err := scheduler.AddJob("0 0 * * Fri-Mon", func() {}); err == nil {
+		t.Error("Expected the strict scheduler to reject a wrapping range")
+	}
+}
+
+// TestWeekdayNumbering tests that Sunday matches as 0, 7 and "Sun" in the
+// standard numbering, and that QuartzWeekdays numbers the days from 1 for
+// Sunday in every kind of day-of-week part.
+func TestWeekdayNumbering(t *testing.T) {
+	tests := []struct {
+		field    string
+		quartz   bool
+		weekdays []int
+	}{
+		{"0", false, []int{0}},
+		{"7", false, []int{0}},
+		{"Sun", false, []int{0}},
+		{"0,7", false, []int{0}},
+		{"5-7", false, []int{0, 5, 6}},
+		{"Fri-Sun", false, []int{0, 5, 6}},
+		{"*/2", false, []int{0, 2, 4, 6}},
+		{"1", true, []int{0}},
+		{"Sun", true, []int{0}},
+		{"2-6", true, []int{1, 2, 3, 4, 5}},
+		{"Mon-Fri", true, []int{1, 2, 3, 4, 5}},
+		{"7", true, []int{6}},
+		{"6-2", true, []int{0, 1, 5, 6}},
+	}
+	for _, tt := range tests {
+		var opts []ParseOption
+		if tt.quartz {
+			opts = append(opts, WithWeekdayNumbering(QuartzWeekdays))
+		}
+		expr, err := ParseCronExpression("0 0 * * "+tt.field, opts...)
+		if err != nil {
+			t.Errorf("%q (quartz %v): %v", tt.field, tt.quartz, err)
+			continue
+		}
+		if got := expr.DayOfWeek(); !reflect.DeepEqual(got, tt.weekdays) {
+			t.Errorf("%q (quartz %v): expected weekdays %v, got %v", tt.field, tt.quartz, tt.weekdays, got)
+		}
+	}
+	if err := Validate("0 0 * * 0", WithWeekdayNumbering(QuartzWeekdays)); err == nil {
+		t.Error("Expected 0 to be out of range with QuartzWeekdays")
+	}
+
+	// Sunday, 1 March 2026, is the first Sunday of the month.
+	from := time.Date(2026, 2, 28, 12, 0, 0, 0, time.UTC)
+	next := []struct {
+		field  string
+		quartz bool
+		want   time.Time
+	}{
+		{"7", false, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"0#1", false, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"7#1", false, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"1#2", true, time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)},
+		{"6L", true, time.Date(2026, 3, 27, 0, 0, 0, 0, time.UTC)},
+		{"5L", false, time.Date(2026, 3, 27, 0, 0, 0, 0, time.UTC)},
+		{"L", true, time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range next {
+		var opts []ParseOption
+		if tt.quartz {
+			opts = append(opts, WithWeekdayNumbering(QuartzWeekdays))
+		}
+		expr, err := ParseCronExpression("0 0 * * "+tt.field, opts...)
+		if err != nil {
+			t.Errorf("%q (quartz %v): %v", tt.field, tt.quartz, err)
+			continue
+		}
+		if got := expr.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q (quartz %v): expected next run at %v, got %v", tt.field, tt.quartz, tt.want, got)
+		}
+	}
+
+	standard, err := ParseCronExpression("0 0 * * H", WithHashKey("report"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	quartz, err := ParseCronExpression("0 0 * * H", WithHashKey("report"), WithWeekdayNumbering(QuartzWeekdays))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(standard.DayOfWeek(), quartz.DayOfWeek()) {
+		t.Errorf("Expected H to pick the same weekday in both numberings, got %v and %v", standard.DayOfWeek(), quartz.DayOfWeek())
+	}
+	if got := quartz.String(); got != standard.String() {
+		t.Errorf("Expected String to use the standard numbering, got %q", got)
+	}
+
+	scheduler := NewCronScheduler(WithParseWeekdayNumbering(QuartzWeekdays))
+	if _, err := scheduler.AddJob("0 0 * * 0", func() {}); err == nil {
+		t.Error("Expected the Quartz scheduler to reject weekday 0")
+	}
+}
+
+// TestParseSteps tests that steps count from the start of their range, also
+// within lists, and that steps past the range keep only its start.
+func TestParseSteps(t *testing.T) {
+	tests := []struct {
+		field string
+		want  []int
+	}{
+		{"*/20", []int{0, 20, 40}},
+		{"5/15", []int{5, 20, 35, 50}},
+		{"1-30/10", []int{1, 11, 21}},
+		{"1-10/5,30", []int{1, 6, 30}},
+		{"50-10/10", []int{0, 10, 50}},
+		{"*/1000", []int{0}},
+	}
+	for _, tt := range tests {
+		expr, err := ParseCronExpression(tt.field + " * * * *")
+		if err != nil {
+			t.Errorf("%q: %v", tt.field, err)
+			continue
+		}
+		if got := expr.Minutes(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: expected minutes %v, got %v", tt.field, tt.want, got)
+		}
+	}
+}
+
+// FuzzParseCronExpression tests that the parser never panics, that field
+// errors point at their token in the expression and that valid expressions
+// survive a round trip through String.
+func FuzzParseCronExpression(f *testing.F) {
+	for _, seed := range []string{
+		"* * * * *", "*/5 9-17 * * Mon-Fri", "0 0 L * *", "0 0 15W * *", "0 0 * * 5L", "0 0 * * Mon#2",
+		"H H(0-5) * * *", "0 0 0 1 1 * 2030", "CRON_TZ=UTC 0 9 * * *", "@every 5m", "@daily",
+		"1,2, * * * *", ",,, * * * *", "*/0 * * * *", "*/99999999999999999999 * * * *", "1-2-3 * * * *",
+		"5/ * * * *", "-1 * * * *", "0 0 * * SunFunday", "60-0/7 * * * *", "0 0 L-99 * *",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		_, _ = ParseCronExpression(input, WithSeconds(), WithStrictness(Strict))
+		expr, err := ParseCronExpression(input, WithSeconds(), WithHashKey("fuzz"))
+		if err != nil {
+			var fieldErr *FieldError
+			if errors.As(err, &fieldErr) && fieldErr.Token != "" && fieldErr.Position > 0 && utf8.ValidString(input) {
+				runes := []rune(input)
+				start := fieldErr.Position - 1
+				token := []rune(fieldErr.Token)
+				if start+len(token) > len(runes) || string(runes[start:start+len(token)]) != fieldErr.Token {
+					t.Errorf("%q: token %q not at position %d", input, fieldErr.Token, fieldErr.Position)
+				}
+			}
+			return
+		}
+		if expr.interval > 0 || expr.reboot {
+			return
+		}
+		again, err := ParseCronExpressionMode(expr.String(), ParseAuto)
+		if err != nil {
+			t.Fatalf("%q: String gave %q, which does not parse: %v", input, expr.String(), err)
+		}
+		if !expr.Equal(again) {
+			t.Errorf("%q: String gave %q, which parses differently", input, expr.String())
+		}
+	})
+}
+
+// TestIterator tests that an Iterator yields successive fire times and
+// stops for good once its schedule does.
+func TestIterator(t *testing.T) {
+	expr, err := ParseCronExpression("0 9 * * Mon-Fri")
+	if err != nil {
+		t.Fatal(err)
+	}
+	it := expr.Iterator(time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)) // a Friday
+	want := []time.Time{
+		time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 21, 9, 0, 0, 0, time.UTC),
+	}
+	for _, w := range want {
+		if got := it.Next(); !got.Equal(w) {
+			t.Errorf("Expected %v, got %v", w, got)
+		}
+	}
+
+	once, err := ParseCronExpression("0 0 0 1 1 * 2027", WithSeconds())
+	if err != nil {
+		t.Fatal(err)
+	}
+	it = once.Iterator(time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC))
+	if got := it.Next(); !got.Equal(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the only fire time, got %v", got)
+	}
+	for range 2 {
+		if got := it.Next(); !got.IsZero() {
+			t.Errorf("Expected the iterator to be exhausted, got %v", got)
+		}
+	}
+
+	if got := NewIterator(stuckSchedule{}, time.Now()).Next(); !got.IsZero() {
+		t.Errorf("Expected a stuck schedule to stop the iterator, got %v", got)
+	}
+	it = NewIterator(Every(90*time.Second), time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	it.Next()
+	if got := it.Next(); !got.Equal(time.Date(2026, 1, 1, 0, 3, 0, 0, time.UTC)) {
+		t.Errorf("Expected the second interval fire time, got %v", got)
+	}
+}
+
+// TestOccurrences tests that Occurrences lists the fire times in a window,
+// including its ends, up to the limit.
+func TestOccurrences(t *testing.T) {
+	expr, err := ParseCronExpression("0 0 1,15 * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	want := []time.Time{
+		time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if got := expr.Occurrences(from, to, 0); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got := expr.Occurrences(from, to, 2); !reflect.DeepEqual(got, want[:2]) {
+		t.Errorf("Expected the first 2 fire times, got %v", got)
+	}
+	if got := expr.Occurrences(to, from, 0); got != nil {
+		t.Errorf("Expected no fire times in an empty window, got %v", got)
+	}
+
+	every, err := ParseCronExpression("@every 20m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := every.Occurrences(from, from.Add(time.Hour), 0); len(got) != 3 || !got[0].Equal(from.Add(20*time.Minute)) {
+		t.Errorf("Expected 3 fire times every 20 minutes after from, got %v", got)
+	}
+}
+
+// TestCronExpressionString tests that String produces a canonical expression that parses back to the same one.
+func TestCronExpressionString(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"* * * * *", "* * * * *"},
+		{"*/15 9-17 * * Mon-Fri", "*/15 9-17 * * 1-5"},
+		{"30 */10 * * * *", "30 */10 * * * *"},
+		{"*/30 * * * *", "*/30 * * * *"},
+		{"0 0 1,15 Jan,Jul ?", "0 0 1,15 */6 *"},
+		{"0 0 1,15 Jan,Jun ?", "0 0 1,15 1,6 *"},
+		{"1-59/2 * * * *", "1-59/2 * * * *"},
+		{"0,10,20,31,32,33 * * * *", "0-20/10,31-33 * * * *"},
+		{"0 0 1,8,15,22,29 * *", "0 0 1-29/7 * *"},
+		{"0 0 * * Mon,Wed,Fri", "0 0 * * 1-5/2"},
+		{"0 0 * * Fri-Mon", "0 0 * * 0,1,5,6"},
+		{"0 12 L,L-2,15W,LW * *", "0 12 L,L-2,15W,LW * *"},
+		{"0 9 * * 5L,Tue#2", "0 9 * * 5L,2#2"},
+		{"0 0 0 1 1 * 2025-2027", "0 0 0 1 1 * 2025-2027"},
+		{"@daily", "0 0 * * *"},
+		{"@every 1m30s", "@every 1m30s"},
+		{"@reboot", "@reboot"},
+	}
+	for _, tt := range tests {
+		expr, err := ParseCronExpressionMode(tt.expr, ParseAuto)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", tt.expr, err)
+		}
+		got := expr.String()
+		if got != tt.want {
+			t.Errorf("%q: expected %q, got %q", tt.expr, tt.want, got)
+		}
+		reparsed, err := ParseCronExpressionMode(got, ParseAuto)
+		if err != nil {
+			t.Fatalf("Failed to parse %q back: %v", got, err)
+		}
+		if reparsed.String() != got {
+			t.Errorf("%q: round trip changed %q to %q", tt.expr, got, reparsed.String())
+		}
+	}
+
+	scheduler := NewCronScheduler()
+	job, _ := scheduler.AddJob("*/5  *  * * MON", func() {})
+	if job.Expression() != "*/5  *  * * MON" {
+		t.Errorf("Expected the original expression to be kept, got %q", job.Expression())
+	}
+	if jobs := scheduler.ListJobs(); len(jobs) != 1 || jobs[0] != "Job job-1: */5  *  * * MON" {
+		t.Errorf("Unexpected job list: %v", jobs)
+	}
+}
+
+// TestStartEndDate tests that a job only fires within its start and end dates.
+func TestStartEndDate(t *testing.T) {
+	scheduler := NewCronSchedulerWithLocation(time.UTC)
+	start := time.Date(2030, 1, 3, 10, 0, 0, 0, time.UTC)
+	end := time.Date(2030, 1, 5, 12, 0, 0, 0, time.UTC)
+	_, _ = scheduler.AddNamedJob("campaign", "0 12 * * *", func() {}, WithStartDate(start), WithEndDate(end))
+	_, _ = scheduler.AddNamedJob("hourly", "@every 1h", func() {}, WithStartDate(start), WithEndDate(start.Add(2*time.Hour)))
+
+	runs := scheduler.Simulate(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2030, 1, 10, 0, 0, 0, 0, time.UTC))
+	var got []string
+	for _, run := range runs {
+		got = append(got, run.JobID+" "+run.Time.Format(time.DateTime))
+	}
+	want := []string{
+		"hourly 2030-01-03 10:00:00",
+		"hourly 2030-01-03 11:00:00",
+		"hourly 2030-01-03 12:00:00",
+		"campaign 2030-01-03 12:00:00",
+		"campaign 2030-01-04 12:00:00",
+		"campaign 2030-01-05 12:00:00",
+	}
+	sort.Strings(got)
+	sort.Strings(want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected runs:\n got %v\nwant %v", got, want)
+	}
+
+	ended := NewCronScheduler()
+	ran := make(chan struct{}, 1)
+	_, _ = ended.AddNamedJob("ended", "* * * * * *", func() {}, WithEndDate(time.Now().Add(-time.Minute)))
+	_, _ = ended.AddNamedJob("later", "@reboot", func() { ran <- struct{}{} }, WithStartDate(time.Now().Add(time.Hour)))
+	ended.Start()
+	defer ended.Stop()
+	if info, _ := ended.JobInfo("ended"); !info.NextRun.IsZero() {
+		t.Errorf("Expected an ended job to be inactive, next run %v", info.NextRun)
+	}
+	select {
+	case <-ran:
+		t.Error("Expected a @reboot job not to run before its start date")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// TestMaxRuns tests that a job deactivates after its maximum number of scheduled runs.
+func TestMaxRuns(t *testing.T) {
+	scheduler := NewCronScheduler()
+	var mu sync.Mutex
+	runs := 0
+	_, _ = scheduler.AddNamedJob("limited", "@every 20ms", func() {
+		mu.Lock()
+		runs++
+		mu.Unlock()
+	}, WithMaxRuns(3))
+	_, _ = scheduler.AddNamedJob("mondays", "0 9 * * MON", func() {}, WithMaxRuns(2))
+
+	if next, _ := scheduler.NextRuns("mondays", 5); len(next) != 2 {
+		t.Errorf("Expected 2 upcoming runs, got %v", next)
+	}
+
+	scheduler.Start()
+	defer scheduler.Stop()
+	time.Sleep(300 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if runs != 3 {
+		t.Errorf("Expected 3 runs, got %d", runs)
+	}
+	if info, _ := scheduler.JobInfo("limited"); !info.NextRun.IsZero() {
+		t.Errorf("Expected the job to be inactive, next run %v", info.NextRun)
+	}
+}
+
+// TestExcludedCalendar tests that jobs skip fire times excluded by their calendars.
+func TestExcludedCalendar(t *testing.T) {
+	holidays := NewDateCalendar(time.Date(2030, 12, 25, 0, 0, 0, 0, time.UTC))
+	if !holidays.Excludes(time.Date(2030, 12, 25, 23, 59, 0, 0, time.UTC)) || holidays.Excludes(time.Date(2030, 12, 26, 0, 0, 0, 0, time.UTC)) {
+		t.Error("Expected the date calendar to exclude exactly Christmas day")
+	}
+
+	maintenance := WeeklyCalendar{{Day: time.Saturday, Start: 22 * time.Hour, End: 26 * time.Hour}}
+	tests := []struct {
+		t    time.Time
+		want bool
+	}{
+		{time.Date(2030, 12, 28, 21, 59, 0, 0, time.UTC), false}, // Saturday
+		{time.Date(2030, 12, 28, 22, 0, 0, 0, time.UTC), true},
+		{time.Date(2030, 12, 29, 1, 59, 0, 0, time.UTC), true}, // Sunday, wrapped into the start of the week
+		{time.Date(2030, 12, 29, 2, 0, 0, 0, time.UTC), false},
+		{time.Date(2030, 12, 25, 23, 0, 0, 0, time.UTC), false}, // Wednesday
+	}
+	for _, tt := range tests {
+		if got := maintenance.Excludes(tt.t); got != tt.want {
+			t.Errorf("Excludes(%v) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+
+	scheduler := NewCronSchedulerWithLocation(time.UTC)
+	_, _ = scheduler.AddNamedJob("daily", "0 23 * * *", func() {}, WithExcludedCalendar(holidays), WithExcludedCalendar(maintenance))
+	var got []int
+	for _, run := range scheduler.Simulate(time.Date(2030, 12, 24, 0, 0, 0, 0, time.UTC), time.Date(2030, 12, 30, 0, 0, 0, 0, time.UTC)) {
+		got = append(got, run.Time.Day())
+	}
+	if want := []int{24, 26, 27, 29}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected runs on days %v, got %v", want, got)
+	}
+
+	weekend := WeeklyCalendar{{Day: time.Saturday, End: 48 * time.Hour}}
+	seconds := NewCronSchedulerWithLocation(time.UTC)
+	_, _ = seconds.AddNamedJob("second", "* * * * * *", func() {}, WithExcludedCalendar(weekend))
+	_, _ = seconds.AddNamedJob("interval", "@every 1h", func() {}, WithExcludedCalendar(weekend))
+	var times []string
+	for _, run := range seconds.Simulate(time.Date(2030, 12, 27, 23, 30, 0, 0, time.UTC), time.Date(2030, 12, 30, 0, 30, 1, 0, time.UTC)) {
+		if run.JobID == "interval" || run.Time.Before(time.Date(2030, 12, 30, 0, 0, 2, 0, time.UTC)) {
+			times = append(times, run.JobID+" "+run.Time.Format(time.DateTime))
+		}
+	}
+	want := []string{"second 2030-12-27 23:59:59", "second 2030-12-30 00:00:00", "second 2030-12-30 00:00:01", "interval 2030-12-30 00:30:00"}
+	if len(times) < 4 || !reflect.DeepEqual(times[len(times)-4:], want) {
+		t.Errorf("Expected the weekend to be skipped, got %v", times[max(len(times)-4, 0):])
+	}
+
+	_, _ = scheduler.AddNamedJob("never", "0 9 * * *", func() {}, WithExcludedCalendar(WeeklyCalendar{{Day: time.Sunday, End: week}}))
+	if next, _ := scheduler.NextRuns("never", 1); len(next) != 0 {
+		t.Errorf("Expected a fully excluded job never to fire, got %v", next)
+	}
+}
+
+// TestSimulate tests predicting runs in a window, including a DST change and a month end.
+func TestSimulate(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	scheduler := NewCronSchedulerWithLocation(loc)
+	_, _ = scheduler.AddNamedJob("early", "30 2 * * *", func() {})
+	_, _ = scheduler.AddNamedJob("close", "0 0 L * *", func() {})
+	_, _ = scheduler.AddNamedJob("tick", "@every 12h", func() {})
+	_, _ = scheduler.AddNamedJob("boot", "@reboot", func() {})
+
+	// Clocks skip from 2:00 to 3:00 on March 10, 2024.
+	from := time.Date(2024, 3, 9, 0, 0, 0, 0, loc)
+	to := time.Date(2024, 3, 11, 0, 0, 0, 0, loc)
+	var got []string
+	for _, run := range scheduler.Simulate(from, to) {
+		got = append(got, run.JobID+" "+run.Time.Format("01-02 15:04"))
+	}
+	// 2:30 does not exist on March 10, so it runs at 3:30, and the 12h interval crosses the change.
+	want := []string{"early 03-09 02:30", "tick 03-09 12:00", "tick 03-10 00:00", "early 03-10 03:30", "tick 03-10 13:00"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	var closes []time.Time
+	for _, run := range scheduler.Simulate(time.Date(2024, 2, 1, 0, 0, 0, 0, loc), time.Date(2024, 4, 1, 0, 0, 0, 0, loc)) {
+		if run.JobID == "close" {
+			closes = append(closes, run.Time)
+		}
+	}
+	if len(closes) != 2 || closes[0].Day() != 29 || closes[1].Day() != 31 {
+		t.Errorf("Expected the month-end job on February 29 and March 31, got %v", closes)
+	}
+}
+
+// TestPauseResumeJob tests that a paused job does not fire until resumed.
+func TestPauseResumeJob(t *testing.T) {
+	scheduler := NewCronScheduler()
+	var mu sync.Mutex
+	runs := 0
+	_, _ = scheduler.AddNamedJob("tick", "@every 20ms", func() {
+		mu.Lock()
+		runs++
+		mu.Unlock()
+	})
+	if err := scheduler.PauseJob("tick"); err != nil {
+		t.Fatalf("Failed to pause job: %v", err)
+	}
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+	mu.Lock()
+	if runs != 0 {
+		t.Errorf("Expected a paused job not to run, got %d runs", runs)
+	}
+	mu.Unlock()
+	if info, _ := scheduler.JobInfo("tick"); !info.Paused || !info.NextRun.IsZero() {
+		t.Errorf("Expected the job to be reported paused with no next run, got %+v", info)
+	}
+
+	if err := scheduler.ResumeJob("tick"); err != nil {
+		t.Fatalf("Failed to resume job: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	mu.Lock()
+	if runs == 0 {
+		t.Error("Expected the resumed job to run")
+	}
+	mu.Unlock()
+
+	if err := scheduler.PauseJob("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+}
+
+// TestHandler tests the HTTP admin API.
+func TestHandler(t *testing.T) {
+	scheduler := NewCronScheduler()
+	ran := make(chan struct{}, 1)
+	_, _ = scheduler.AddNamedJob("report", "0 6 * * *", func() { ran <- struct{}{} })
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	server := httptest.NewServer(scheduler.Handler())
+	defer server.Close()
+	do := func(method, path string) *http.Response {
+		req, _ := http.NewRequest(method, server.URL+path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, path, err)
+		}
+		return resp
+	}
+
+	if resp := do("POST", "/jobs/report/run"); resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected 202 from run, got %d", resp.StatusCode)
+	}
+	<-ran
+	if resp := do("POST", "/jobs/report/pause"); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected 204 from pause, got %d", resp.StatusCode)
+	}
+
+	var jobs []map[string]any
+	resp := do("GET", "/jobs")
+	_ = json.NewDecoder(resp.Body).Decode(&jobs)
+	resp.Body.Close()
+	if len(jobs) != 1 || jobs[0]["id"] != "report" || jobs[0]["paused"] != true {
+		t.Errorf("Unexpected job list: %v", jobs)
+	}
+
+	_ = do("POST", "/jobs/report/resume")
+	var job struct {
+		NextRuns []time.Time `json:"next_runs"`
+		Paused   bool        `json:"paused"`
+	}
+	resp = do("GET", "/jobs/report?n=3")
+	_ = json.NewDecoder(resp.Body).Decode(&job)
+	resp.Body.Close()
+	if len(job.NextRuns) != 3 || job.Paused {
+		t.Errorf("Expected 3 next runs of a resumed job, got %+v", job)
+	}
+
+	var history []map[string]any
+	deadline := time.Now().Add(time.Second)
+	for len(history) == 0 && time.Now().Before(deadline) {
+		resp = do("GET", "/jobs/report/history")
+		_ = json.NewDecoder(resp.Body).Decode(&history)
+		resp.Body.Close()
+	}
+	if len(history) != 1 || history[0]["outcome"] != "success" {
+		t.Errorf("Unexpected history: %v", history)
+	}
+
+	if resp := do("GET", "/jobs/missing"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown job, got %d", resp.StatusCode)
+	}
+	for _, path := range []string{"/jobs?n=1000000000", "/jobs/report?n=101", "/jobs?history=101", "/jobs/report?n=-1"} {
+		if resp := do("GET", path); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", path, resp.StatusCode)
+		}
+	}
+}
+
+// TestDashboard tests that the dashboard serves its page and the admin API.
+func TestDashboard(t *testing.T) {
+	scheduler := NewCronScheduler()
+	_, _ = scheduler.AddNamedJob("report", "0 6 * * *", func() {})
+
+	mux := http.NewServeMux()
+	mux.Handle("/cron/", http.StripPrefix("/cron", scheduler.Dashboard()))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/cron/")
+	if err != nil {
+		t.Fatalf("Failed to get dashboard: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Errorf("Expected the HTML page, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	resp, err = http.Get(server.URL + "/cron/api/jobs")
+	if err != nil {
+		t.Fatalf("Failed to get jobs: %v", err)
+	}
+	var jobs []map[string]any
+	_ = json.NewDecoder(resp.Body).Decode(&jobs)
+	resp.Body.Close()
+	if len(jobs) != 1 || jobs[0]["id"] != "report" {
+		t.Errorf("Unexpected jobs from the dashboard API: %v", jobs)
+	}
+
+	for i := 0; i < 3; i++ {
+		_ = scheduler.RunNowAndWait(context.Background(), "report")
+	}
+	resp, err = http.Get(server.URL + "/cron/api/jobs?n=2&history=2")
+	if err != nil {
+		t.Fatalf("Failed to get jobs: %v", err)
+	}
+	var views []jobView
+	_ = json.NewDecoder(resp.Body).Decode(&views)
+	resp.Body.Close()
+	if len(views) != 1 || len(views[0].NextRuns) != 2 || len(views[0].History) != 2 {
+		t.Errorf("Expected next runs and history in the job list, got %+v", views)
+	}
+	resp, err = http.Get(server.URL + "/cron/api/jobs?history=-1")
+	if err != nil {
+		t.Fatalf("Failed to get jobs: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected a bad request for a negative history, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,168 @@
+package cronjob
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// defaultCrontabShell is the shell crontab commands run in unless SHELL is
+// set.
+const defaultCrontabShell = "/bin/sh"
+
+// envAssignment matches a crontab environment line, "NAME = value".
+var envAssignment = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`)
+
+// LoadCrontab reads a classic user crontab from r and adds an ExecJob for
+// each of its entries, returning their IDs in order, to ease migrating from
+// system cron. Entries are a five-field expression or a macro such as
+// "@daily", followed by a command, which runs as SHELL -c command. An
+// unescaped "%" in a command ends it, the rest of it being written to the
+// command's standard input with further "%" as newlines, as cron does.
+//
+// Blank lines and "#" comments are skipped, and "NAME=value" lines set
+// environment variables for the entries after them. SHELL sets the shell,
+// /bin/sh by default, and CRON_TZ the time zone of the expressions after
+// it; MAILTO and the others are passed to the commands' environment. opts
+// apply to every job. If any line is invalid, no job is added.
+func (c *CronScheduler) LoadCrontab(r io.Reader, opts ...JobOption) ([]string, error) {
+	type entry struct {
+		expr    string
+		command ExecJob
+		loc     *time.Location
+	}
+	var entries []entry
+	var errs []error
+	var env []string
+	shell := defaultCrontabShell
+	var loc *time.Location
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if m := envAssignment.FindStringSubmatch(line); m != nil {
+			name, value := m[1], unquoteEnvValue(m[2])
+			switch name {
+			case "SHELL":
+				shell = value
+			case "CRON_TZ":
+				tz, err := time.LoadLocation(value)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("line %d: %w", n, err))
+				}
+				loc = tz
+				continue
+			}
+			env = append(env, name+"="+value)
+			continue
+		}
+
+		expr, command, err := splitCrontabEntry(line)
+		if err == nil {
+			err = c.Validate(expr)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", n, err))
+			continue
+		}
+		command, stdin := splitCrontabCommand(command)
+		entries = append(entries, entry{
+			expr: expr,
+			command: ExecJob{
+				Command: shell,
+				Args:    []string{"-c", command},
+				Env:     append([]string(nil), env...),
+				Stdin:   stdin,
+			},
+			loc: loc,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading crontab: %w", err)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		jobOpts := opts
+		if e.loc != nil {
+			jobOpts = append([]JobOption{WithLocation(e.loc)}, opts...)
+		}
+		job, err := c.AddExecJob(e.expr, e.command, jobOpts...)
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, job.ID)
+	}
+	return ids, nil
+}
+
+// splitCrontabEntry splits a crontab entry into its expression, five fields
+// or a macro, and its command.
+func splitCrontabEntry(line string) (expr, command string, err error) {
+	fields := 5
+	if strings.HasPrefix(line, "@") {
+		fields = 1
+		if strings.HasPrefix(line, "@every") {
+			fields = 2
+		}
+	}
+	rest := line
+	for i := 0; i < fields; i++ {
+		rest = strings.TrimLeft(rest, " \t")
+		end := strings.IndexAny(rest, " \t")
+		if end < 0 {
+			return "", "", fmt.Errorf("missing command: %s", line)
+		}
+		rest = rest[end:]
+	}
+	command = strings.TrimSpace(rest)
+	if command == "" {
+		return "", "", fmt.Errorf("missing command: %s", line)
+	}
+	return strings.Join(strings.Fields(strings.TrimSuffix(line, rest)), " "), command, nil
+}
+
+// splitCrontabCommand splits a crontab command at its first unescaped "%"
+// into the command and its standard input, with further unescaped "%" as
+// newlines and "\%" as "%".
+func splitCrontabCommand(command string) (string, string) {
+	var parts []string
+	var b strings.Builder
+	for i := 0; i < len(command); i++ {
+		switch {
+		case command[i] == '\\' && i+1 < len(command) && command[i+1] == '%':
+			b.WriteByte('%')
+			i++
+		case command[i] == '%':
+			parts = append(parts, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(command[i])
+		}
+	}
+	parts = append(parts, b.String())
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], strings.Join(parts[1:], "\n") + "\n"
+}
+
+// unquoteEnvValue strips the matching single or double quotes around a
+// crontab environment value.
+func unquoteEnvValue(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
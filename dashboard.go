@@ -0,0 +1,27 @@
+package cronjob
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+//go:embed dashboard.html
+var dashboardHTML []byte
+
+// Dashboard returns an http.Handler serving an HTML dashboard of the
+// scheduler at its root, with a job table, upcoming runs and recent
+// failures, and the Handler admin API it uses under /api/. Mount it at a
+// path ending in a slash, e.g.
+//
+//	http.Handle("/cron/", http.StripPrefix("/cron", scheduler.Dashboard()))
+//
+// Like Handler, it has no authentication of its own.
+func (c *CronScheduler) Dashboard() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/api/", http.StripPrefix("/api", c.Handler()))
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(dashboardHTML)
+	})
+	return mux
+}
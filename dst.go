@@ -0,0 +1,91 @@
+package cronjob
+
+import "time"
+
+// DSTPolicy controls what a job does when its fire time falls in the hour
+// skipped by a daylight saving time change, such as 02:30 when clocks jump
+// from 02:00 to 03:00.
+//
+// It only applies to jobs with a restricted hour field. Jobs firing every
+// hour, like "*/15 * * * *", keep their absolute cadence across a change:
+// they have no runs in a skipped hour, and run twice in a repeated one.
+// Jobs with a restricted hour field never fire twice in the hour repeated
+// when clocks go back; only its first occurrence counts.
+type DSTPolicy int
+
+const (
+	// DSTFireOnce runs a fire time in a skipped hour once, shifted by the
+	// length of the change: 02:30 runs at 03:30. This is the default.
+	DSTFireOnce DSTPolicy = iota
+	// DSTSkip drops fire times in a skipped hour, so a daily 02:30 job
+	// does not run on the day of the change.
+	DSTSkip
+)
+
+// WithDSTPolicy sets what the scheduler's jobs do about fire times in an
+// hour skipped by a DST change. The default is DSTFireOnce.
+func WithDSTPolicy(policy DSTPolicy) SchedulerOption {
+	return func(c *CronScheduler) {
+		c.dstPolicy = policy
+	}
+}
+
+// maxDSTChange bounds the size of a DST change, used to look back from a
+// time for the change it may follow.
+const maxDSTChange = 4 * time.Hour
+
+// isRepeatedWallTime reports whether t is the second occurrence of a wall
+// clock time repeated when clocks went back.
+func isRepeatedWallTime(t time.Time) bool {
+	_, offset := t.Zone()
+	_, before := t.Add(-maxDSTChange).Zone()
+	if before <= offset {
+		return false
+	}
+	earlier := t.Add(-time.Duration(before-offset) * time.Second)
+	_, earlierOffset := earlier.Zone()
+	return earlierOffset == before
+}
+
+// nextSkippedTime returns the earliest fire time of expr in an hour skipped
+// by a DST change of fromTime's location, shifted by the change, that is
+// after fromTime and before until, or the zero time if there is none.
+func nextSkippedTime(expr *CronExpression, fromTime, until time.Time) time.Time {
+	loc := fromTime.Location()
+	const step = 24 * time.Hour
+	// Shifted fire times come up to one change after it, so look back.
+	for start := fromTime.Add(-maxDSTChange); start.Before(until); start = start.Add(step) {
+		end := start.Add(step)
+		_, startOffset := start.In(loc).Zone()
+		_, endOffset := end.In(loc).Zone()
+		if endOffset <= startOffset {
+			continue
+		}
+		// Find the second the clocks jumped forward.
+		lo, hi := start, end
+		for hi.Sub(lo) > time.Second {
+			mid := lo.Add(hi.Sub(lo) / 2).Truncate(time.Second)
+			if _, offset := mid.In(loc).Zone(); offset == startOffset {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		change := hi.Truncate(time.Second)
+		// The skipped wall clock times, in UTC so they are not normalized.
+		gap := time.Duration(endOffset-startOffset) * time.Second
+		skippedFrom := change.Add(time.Duration(startOffset) * time.Second).UTC()
+		for wall := skippedFrom; wall.Before(skippedFrom.Add(gap)); wall = wall.Add(time.Second) {
+			if !isTimeMatching(expr, wall) {
+				continue
+			}
+			// Interpreting the wall time with the offset before the change
+			// shifts it by the length of the change.
+			t := wall.Add(-time.Duration(startOffset) * time.Second).In(loc)
+			if t.After(fromTime) && t.Before(until) {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
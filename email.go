@@ -0,0 +1,137 @@
+package cronjob
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// AlertRule says who an EmailAlerter emails about a job, and when.
+type AlertRule struct {
+	// To lists the recipients. A rule without recipients sends nothing.
+	To []string
+	// Failures is the number of consecutive failed runs, errors, panics and
+	// timeouts alike, that sends an alert, once per streak of failures.
+	// Zero means 1.
+	Failures int
+	// Missed also sends an alert when a scheduled run starts too late, as
+	// reported by an EventMissedDeadline.
+	Missed bool
+}
+
+// EmailAlerter emails a summary when a job keeps failing or misses a run,
+// for small teams without a full observability stack:
+//
+//	alerter := &cronjob.EmailAlerter{
+//		Addr:    "smtp.example.com:587",
+//		Auth:    smtp.PlainAuth("", user, password, "smtp.example.com"),
+//		From:    "cron@example.com",
+//		Default: cronjob.AlertRule{To: []string{"ops@example.com"}, Failures: 3},
+//	}
+//	stop := alerter.Watch(scheduler)
+//	defer stop()
+//
+// A job's rule is the one in Jobs under its ID, else the one in Groups
+// under its group's name, else Default.
+type EmailAlerter struct {
+	// Addr is the SMTP server's "host:port".
+	Addr string
+	Auth smtp.Auth
+	From string
+
+	Default AlertRule
+	Jobs    map[string]AlertRule
+	Groups  map[string]AlertRule
+
+	// Send sends a message. If it is nil, smtp.SendMail is used.
+	Send func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+	// OnError, if set, is called when an alert cannot be sent.
+	OnError func(jobID string, err error)
+}
+
+// alertEventBuffer is how many events an EmailAlerter holds while it
+// handles earlier ones.
+const alertEventBuffer = 256
+
+// Watch subscribes the alerter to the scheduler's events and starts
+// emailing alerts for them. Emails are sent in the background, so a slow
+// server never delays a run. The returned function stops it.
+func (a *EmailAlerter) Watch(c *CronScheduler) (stop func()) {
+	// failures counts each job's consecutive failures. Events are handled
+	// one at a time, so it needs no lock.
+	failures := make(map[string]int)
+	return c.watchEvents(alertEventBuffer, func(event JobEvent) {
+		switch event.Type {
+		case EventSucceeded, EventRemoved:
+			delete(failures, event.JobID)
+			return
+		case EventFailed, EventPanicked, EventMissedDeadline:
+		default:
+			return
+		}
+		rule := a.rule(c, event.JobID)
+		var subject, summary string
+		if event.Type == EventMissedDeadline {
+			if !rule.Missed {
+				return
+			}
+			subject = fmt.Sprintf("job %s missed its scheduled run", event.JobID)
+			summary = fmt.Sprintf("The run started %v late.", event.Late)
+		} else {
+			failures[event.JobID]++
+			n := failures[event.JobID]
+			if n != max(rule.Failures, 1) {
+				return
+			}
+			subject = fmt.Sprintf("job %s failed", event.JobID)
+			if n > 1 {
+				subject = fmt.Sprintf("job %s failed %d times in a row", event.JobID, n)
+			}
+			summary = fmt.Sprintf("Last error: %v", event.Err)
+		}
+		a.alert(rule.To, event, subject, summary)
+	})
+}
+
+// rule returns the alert rule of the job with the given ID.
+func (a *EmailAlerter) rule(c *CronScheduler, id string) AlertRule {
+	if rule, ok := a.Jobs[id]; ok {
+		return rule
+	}
+	if len(a.Groups) > 0 {
+		if info, err := c.JobInfo(id); err == nil && info.Group != "" {
+			if rule, ok := a.Groups[info.Group]; ok {
+				return rule
+			}
+		}
+	}
+	return a.Default
+}
+
+// alert emails the alert about event to to, in the background.
+func (a *EmailAlerter) alert(to []string, event JobEvent, subject, summary string) {
+	if len(to) == 0 {
+		return
+	}
+	send := a.Send
+	if send == nil {
+		send = smtp.SendMail
+	}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", a.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	// Job IDs and errors may hold line breaks, which would end the header;
+	// Q-encoding turns them into plain text.
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", "[cronjob] "+subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", event.Time.Format(time.RFC1123Z))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "Job: %s\r\nTime: %s\r\n%s\r\n", event.JobID, event.Time.Format(time.RFC3339), summary)
+	go func() {
+		if err := send(a.Addr, a.Auth, a.From, to, msg.Bytes()); err != nil && a.OnError != nil {
+			a.OnError(event.JobID, fmt.Errorf("sending alert: %w", err))
+		}
+	}()
+}
@@ -0,0 +1,189 @@
+package cronjob
+
+import (
+	"errors"
+	"time"
+)
+
+// EventType identifies a stage in a job's lifecycle.
+type EventType int
+
+const (
+	// EventScheduled is sent when a job's next run is queued.
+	EventScheduled EventType = iota
+	// EventStarted is sent when a run starts.
+	EventStarted
+	// EventSucceeded is sent when a run finishes without error.
+	EventSucceeded
+	// EventFailed is sent when a run finishes with an error, including a
+	// timeout.
+	EventFailed
+	// EventPanicked is sent when a run's task panics.
+	EventPanicked
+	// EventSkipped is sent when a due run does not happen, because of the
+	// job's overlap policy, another instance holding its lock or a failed
+	// dependency.
+	EventSkipped
+	// EventRemoved is sent when a job is removed from the scheduler.
+	EventRemoved
+	// EventClockJump is sent, with an empty JobID, when the wall clock
+	// moves by more than real time elapsed, as after an NTP step or a
+	// suspend and resume.
+	EventClockJump
+	// EventStuck is sent when a run is still going after the job's
+	// WithStuckThreshold.
+	EventStuck
+	// EventMissedDeadline is sent when a scheduled run starts later than
+	// the scheduler's WithLatenessTolerance allows, with how late it was.
+	EventMissedDeadline
+	// EventCircuitOpen is sent, with the last run's error, when the job's
+	// WithCircuitBreaker pauses it after repeated failures.
+	EventCircuitOpen
+	// EventCircuitClosed is sent when the breaker resumes the job after
+	// its cooldown.
+	EventCircuitClosed
+	// EventDisabled is sent, with the last panic, when the job's
+	// WithPanicLimit disables it.
+	EventDisabled
+	// EventBackpressure is sent, with the JobID of the job whose run was
+	// queued last, when the runs queued by the QueueOne and QueueAll
+	// policies across all jobs reach the scheduler's
+	// WithBackpressureThreshold.
+	EventBackpressure
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventScheduled:
+		return "scheduled"
+	case EventStarted:
+		return "started"
+	case EventSucceeded:
+		return "succeeded"
+	case EventFailed:
+		return "failed"
+	case EventPanicked:
+		return "panicked"
+	case EventSkipped:
+		return "skipped"
+	case EventRemoved:
+		return "removed"
+	case EventClockJump:
+		return "clock jump"
+	case EventStuck:
+		return "stuck"
+	case EventMissedDeadline:
+		return "missed deadline"
+	case EventCircuitOpen:
+		return "circuit open"
+	case EventCircuitClosed:
+		return "circuit closed"
+	case EventDisabled:
+		return "disabled"
+	case EventBackpressure:
+		return "backpressure"
+	}
+	return "unknown"
+}
+
+// JobEvent describes something that happened to a job.
+type JobEvent struct {
+	Type  EventType
+	JobID string
+	// Time is when the event happened.
+	Time time.Time
+	// Next is the fire time of the queued run, for EventScheduled.
+	Next time.Time
+	// Err is the run's error, for EventFailed, EventPanicked,
+	// EventCircuitOpen and EventDisabled.
+	Err error
+	// Jump is how far the wall clock moved beyond real time, negative if
+	// it went back, for EventClockJump.
+	Jump time.Duration
+	// Late is how long after its fire time the run started, for
+	// EventMissedDeadline.
+	Late time.Duration
+	// Queued is the number of runs queued across all jobs, for
+	// EventBackpressure.
+	Queued int
+}
+
+// Subscribe sends the scheduler's job events to ch until the returned
+// function is called. Events are never waited on: if ch is full when an
+// event happens, that event is dropped, so give ch enough buffer for the
+// subscriber to keep up.
+func (c *CronScheduler) Subscribe(ch chan<- JobEvent) (unsubscribe func()) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.subscribers = append(c.subscribers, ch)
+	return func() {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		for i, sub := range c.subscribers {
+			if sub == ch {
+				c.subscribers = append(c.subscribers[:i], c.subscribers[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// watchEvents subscribes to the scheduler's events, buffering up to size
+// of them, and calls handle with each in turn, on a goroutine of its own,
+// until the returned function is called.
+func (c *CronScheduler) watchEvents(size int, handle func(JobEvent)) (stop func()) {
+	events := make(chan JobEvent, size)
+	unsubscribe := c.Subscribe(events)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case event := <-events:
+				handle(event)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		unsubscribe()
+		close(done)
+	}
+}
+
+// emit sends an event of type t for job to every subscriber. The caller
+// must hold c.mutex.
+func (c *CronScheduler) emit(t EventType, job *Job, err error) {
+	if len(c.subscribers) == 0 {
+		return
+	}
+	event := JobEvent{Type: t, JobID: job.ID, Time: time.Now(), Err: err}
+	if t == EventScheduled {
+		event.Next = job.next
+	}
+	c.publish(event)
+}
+
+// publish sends event to every subscriber. The caller must hold c.mutex.
+func (c *CronScheduler) publish(event JobEvent) {
+	for _, ch := range c.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// emitResult sends the event matching a finished run's error. The caller
+// must hold c.mutex.
+func (c *CronScheduler) emitResult(job *Job, err error) {
+	var panicErr *PanicError
+	switch {
+	case err == nil:
+		c.emit(EventSucceeded, job, nil)
+	case errors.As(err, &panicErr):
+		c.emit(EventPanicked, job, err)
+	default:
+		c.emit(EventFailed, job, err)
+	}
+}
@@ -4,4 +4,9 @@ go 1.23.1
 
 require github.com/flyzard/go-cronjob v1.0.2
 
-require golang.org/x/text v0.19.0 // indirect
+require (
+	golang.org/x/text v0.19.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/flyzard/go-cronjob => ../
@@ -13,7 +13,7 @@ func main() {
 	scheduler := cronjob.NewCronScheduler()
 
 	// Add a job that runs every minute
-	err := scheduler.AddJob("* * * * *", func() {
+	_, err := scheduler.AddJob("* * * * *", func() {
 		fmt.Println("Task 1: Runs every minute -", time.Now().Format(time.RFC1123))
 	})
 	if err != nil {
@@ -22,7 +22,7 @@ func main() {
 	}
 
 	// Add a job that runs at 9 AM every Monday
-	err = scheduler.AddJob("0 9 * * Mon", func() {
+	_, err = scheduler.AddJob("0 9 * * Mon", func() {
 		fmt.Println("Task 2: Runs at 9 AM every Monday -", time.Now().Format(time.RFC1123))
 	})
 	if err != nil {
@@ -31,7 +31,7 @@ func main() {
 	}
 
 	// Add a job that runs every 15 minutes
-	err = scheduler.AddJob("*/15 * * * *", func() {
+	_, err = scheduler.AddJob("*/15 * * * *", func() {
 		fmt.Println("Task 3: Runs every 15 minutes -", time.Now().Format(time.RFC1123))
 	})
 	if err != nil {
@@ -40,7 +40,7 @@ func main() {
 	}
 
 	// Add a job that runs at midnight on the first day of every month
-	err = scheduler.AddJob("0 0 1 * *", func() {
+	_, err = scheduler.AddJob("0 0 1 * *", func() {
 		fmt.Println("Task 4: Runs at midnight on the first day of every month -", time.Now().Format(time.RFC1123))
 	})
 	if err != nil {
@@ -49,7 +49,7 @@ func main() {
 	}
 
 	// Add a job with named month and day of week
-	err = scheduler.AddJob("30 14 15 Jan-Mar Fri", func() {
+	_, err = scheduler.AddJob("30 14 15 Jan-Mar Fri", func() {
 		fmt.Println("Task 5: Runs at 14:30 on the 15th day of Jan, Feb, Mar and every Friday -", time.Now().Format(time.RFC1123))
 	})
 	if err != nil {
@@ -58,7 +58,7 @@ func main() {
 	}
 
 	// Add a job that will panic to demonstrate panic handling
-	err = scheduler.AddJob("2 * * * *", func() {
+	_, err = scheduler.AddJob("2 * * * *", func() {
 		fmt.Println("Task 6: This task will panic -", time.Now().Format(time.RFC1123))
 		panic("intentional panic for testing")
 	})
@@ -0,0 +1,148 @@
+package cronjob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxOutput is how many bytes of each of its output streams an
+// ExecJob captures unless changed with MaxOutput.
+const DefaultMaxOutput = 64 << 10
+
+// execWaitDelay is how long a killed command's output is waited for, in
+// case a child process it started still holds it open.
+const execWaitDelay = 5 * time.Second
+
+// ExecJob runs an external command as a job's task, for process-based
+// workloads crond would otherwise run. The command's standard output and
+// error are captured in the run's RunRecord, and a non-zero exit status
+// fails the run, unless it is one of SoftExitCodes.
+type ExecJob struct {
+	Command string
+	Args    []string
+	// Env holds extra "KEY=value" environment variables, added to the
+	// scheduler process's environment.
+	Env []string
+	// Dir is the command's working directory. If it is empty, the command
+	// runs in the scheduler process's.
+	Dir string
+	// Stdin, if set, is written to the command's standard input.
+	Stdin string
+	// Timeout, if positive, kills the command once it has run that long.
+	Timeout time.Duration
+	// MaxOutput is how many bytes of each output stream are captured;
+	// later output is discarded. Zero means DefaultMaxOutput.
+	MaxOutput int
+	// SoftExitCodes lists non-zero exit codes that are soft failures: the
+	// run is recorded as OutcomeSoftFailure, without being retried or
+	// reported as failed. See ErrSoftFailure.
+	SoftExitCodes []int
+}
+
+// AddExecJob adds a new job running command and returns it, with its
+// generated ID.
+// A run fails if the command cannot be started, exits with a non-zero
+// status or is killed by its Timeout.
+func (c *CronScheduler) AddExecJob(expr string, command ExecJob, opts ...JobOption) (*Job, error) {
+	job, err := c.newJob(expr, command.Run, opts)
+	if err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.setID(job, c.generateID())
+	c.insertJob(job)
+	return job, nil
+}
+
+// Run runs the command once, killing it when ctx is done or its Timeout
+// passes. If ctx is a run's context, the command's output is recorded in
+// the run's RunRecord, so Run can also be registered with RegisterTask.
+func (e ExecJob) Run(ctx context.Context) error {
+	cmdCtx := ctx
+	if e.Timeout > 0 {
+		var cancel context.CancelFunc
+		cmdCtx, cancel = context.WithTimeout(ctx, e.Timeout)
+		defer cancel()
+	}
+	limit := e.MaxOutput
+	if limit <= 0 {
+		limit = DefaultMaxOutput
+	}
+	stdout, stderr := &limitedBuffer{limit: limit}, &limitedBuffer{limit: limit}
+	cmd := exec.CommandContext(cmdCtx, e.Command, e.Args...)
+	cmd.Dir = e.Dir
+	cmd.Env = append(os.Environ(), e.Env...)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	if e.Stdin != "" {
+		cmd.Stdin = strings.NewReader(e.Stdin)
+	}
+	cmd.WaitDelay = execWaitDelay
+	err := cmd.Run()
+	if output, ok := ctx.Value(runOutputKey{}).(*runOutput); ok {
+		output.set(string(stdout.buf), string(stderr.buf))
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() == nil && cmdCtx.Err() != nil:
+		return fmt.Errorf("%s: killed after %v: %w", e.Command, e.Timeout, err)
+	case errors.As(err, &exitErr) && slices.Contains(e.SoftExitCodes, exitErr.ExitCode()):
+		return fmt.Errorf("%s: %w: %w", e.Command, ErrSoftFailure, err)
+	default:
+		return fmt.Errorf("%s: %w", e.Command, err)
+	}
+}
+
+// limitedBuffer keeps the first limit bytes written to it and discards the
+// rest.
+type limitedBuffer struct {
+	buf   []byte
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if n := b.limit - len(b.buf); n > 0 {
+		b.buf = append(b.buf, p[:min(n, len(p))]...)
+	}
+	return len(p), nil
+}
+
+// runOutputKey is the context key of a run's runOutput.
+type runOutputKey struct{}
+
+// runOutput holds the output a run's task captured, for its RunRecord.
+type runOutput struct {
+	mu             sync.Mutex
+	stdout, stderr string
+	statusCode     int
+}
+
+// set records the output of the run's latest attempt.
+func (o *runOutput) set(stdout, stderr string) {
+	o.mu.Lock()
+	o.stdout, o.stderr = stdout, stderr
+	o.mu.Unlock()
+}
+
+// setStatus records the response status code of the run's latest attempt.
+func (o *runOutput) setStatus(code int) {
+	o.mu.Lock()
+	o.statusCode = code
+	o.mu.Unlock()
+}
+
+// record copies the recorded output into r.
+func (o *runOutput) record(r *RunRecord) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	r.Stdout, r.Stderr, r.StatusCode = o.stdout, o.stderr, o.statusCode
+}
@@ -0,0 +1,40 @@
+package cronjob
+
+import "math/bits"
+
+// fieldSet is the set of values a cron field allows, as a bitmask with bit
+// v set for value v, so matching a time is a bit test. Every field but the
+// year fits, with values from 0 to 59.
+type fieldSet uint64
+
+// newFieldSet returns the set of values, which must be between 0 and 63.
+func newFieldSet(values []int) fieldSet {
+	var s fieldSet
+	for _, v := range values {
+		s |= 1 << v
+	}
+	return s
+}
+
+// has reports whether v is in the set.
+func (s fieldSet) has(v int) bool {
+	return v >= 0 && v < 64 && s&(1<<v) != 0
+}
+
+// len returns the number of values in the set.
+func (s fieldSet) len() int {
+	return bits.OnesCount64(uint64(s))
+}
+
+// values returns the values in the set in increasing order, or nil if it is
+// empty.
+func (s fieldSet) values() []int {
+	if s == 0 {
+		return nil
+	}
+	values := make([]int, 0, s.len())
+	for rest := uint64(s); rest != 0; rest &= rest - 1 {
+		values = append(values, bits.TrailingZeros64(rest))
+	}
+	return values
+}
@@ -2,4 +2,7 @@ module github.com/flyzard/go-cronjob
 
 go 1.23.1
 
-require golang.org/x/text v0.19.0
+require (
+	golang.org/x/text v0.19.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,153 @@
+package cronjob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Group is a named set of related jobs, such as every billing job, that can
+// be paused, resumed, removed and limited as a unit. Jobs join a group when
+// added through its methods or with WithGroup.
+type Group struct {
+	c    *CronScheduler
+	name string
+	// slots, if set, holds a token for every running task of the group.
+	slots *limiter
+}
+
+// WithGroup adds the job to the group with the given name, as if it had been
+// added through c.Group(name).
+func WithGroup(name string) JobOption {
+	return func(j *Job) {
+		j.group = name
+	}
+}
+
+// Group returns the group with the given name, creating it on first use.
+// Calls with the same name return the same group. The name must not be
+// empty.
+func (c *CronScheduler) Group(name string) *Group {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	g, ok := c.groups[name]
+	if !ok {
+		g = &Group{c: c, name: name}
+		c.groups[name] = g
+	}
+	return g
+}
+
+// Name returns the group's name.
+func (g *Group) Name() string {
+	return g.name
+}
+
+// AddJob adds a new job to the group, like CronScheduler.AddJob.
+func (g *Group) AddJob(expr string, task Task, opts ...JobOption) (*Job, error) {
+	return g.c.AddJob(expr, task, g.options(opts)...)
+}
+
+// AddJobContext adds a new context-aware job to the group, like
+// CronScheduler.AddJobContext.
+func (g *Group) AddJobContext(expr string, task func(ctx context.Context), opts ...JobOption) (*Job, error) {
+	return g.c.AddJobContext(expr, task, g.options(opts)...)
+}
+
+// AddJobWithError adds a new job whose task can fail to the group, like
+// CronScheduler.AddJobWithError.
+func (g *Group) AddJobWithError(expr string, task func() error, opts ...JobOption) (*Job, error) {
+	return g.c.AddJobWithError(expr, task, g.options(opts)...)
+}
+
+// AddNamedJob adds a new job to the group under a user-supplied ID, like
+// CronScheduler.AddNamedJob.
+func (g *Group) AddNamedJob(id, expr string, task func(), opts ...JobOption) (*Job, error) {
+	return g.c.AddNamedJob(id, expr, task, g.options(opts)...)
+}
+
+// options returns opts followed by the option adding a job to the group.
+func (g *Group) options(opts []JobOption) []JobOption {
+	return append(opts[:len(opts):len(opts)], WithGroup(g.name))
+}
+
+// Jobs returns the IDs of the group's jobs, in the order they were added.
+func (g *Group) Jobs() []string {
+	g.c.mutex.Lock()
+	defer g.c.mutex.Unlock()
+	var ids []string
+	for _, job := range g.c.jobs {
+		if job.group == g.name {
+			ids = append(ids, job.ID)
+		}
+	}
+	return ids
+}
+
+// Pause pauses every job in the group, like PauseJob. Jobs added to the
+// group afterwards are not paused.
+func (g *Group) Pause() {
+	g.c.mutex.Lock()
+	defer g.c.mutex.Unlock()
+	for _, job := range g.c.jobs {
+		if job.group == g.name {
+			g.c.pauseJob(job)
+		}
+	}
+}
+
+// Resume resumes every paused job in the group, like ResumeJob.
+func (g *Group) Resume() {
+	g.c.mutex.Lock()
+	defer g.c.mutex.Unlock()
+	for _, job := range g.c.jobs {
+		if job.group == g.name {
+			g.c.resumeJob(job)
+		}
+	}
+}
+
+// Remove removes every job in the group, like RemoveJob. The group itself
+// stays usable for adding new jobs.
+func (g *Group) Remove() error {
+	c := g.c
+	c.mutex.Lock()
+	var persisted []string
+	for i := len(c.jobs) - 1; i >= 0; i-- {
+		job := c.jobs[i]
+		if job.group != g.name {
+			continue
+		}
+		if job.persisted {
+			persisted = append(persisted, job.ID)
+		}
+		c.removeJob(job)
+	}
+	store := c.store
+	c.mutex.Unlock()
+
+	if store == nil {
+		return nil
+	}
+	var errs []error
+	for _, id := range persisted {
+		if err := store.Delete(id); err != nil {
+			errs = append(errs, fmt.Errorf("deleting job %s: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SetMaxConcurrent limits the number of the group's tasks running at the
+// same time to n, on top of any scheduler-wide WithMaxConcurrentJobs limit.
+// Runs over the limit are handled by the scheduler's LimitPolicy. Zero or a
+// negative n removes the limit. Runs already waiting keep the limit they
+// started waiting under.
+func (g *Group) SetMaxConcurrent(n int) {
+	g.c.mutex.Lock()
+	defer g.c.mutex.Unlock()
+	g.slots = nil
+	if n > 0 {
+		g.slots = newLimiter(n, g.c.starvationLimit)
+	}
+}
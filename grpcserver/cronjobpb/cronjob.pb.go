@@ -0,0 +1,555 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: cronjobpb/cronjob.proto
+
+package cronjobpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Job is a snapshot of a job's schedule and run state.
+type Job struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Expression string                 `protobuf:"bytes,2,opt,name=expression,proto3" json:"expression,omitempty"`
+	// Unset if the job will not fire again or is paused.
+	NextRun *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=next_run,json=nextRun,proto3" json:"next_run,omitempty"`
+	// Unset if the job has not run yet.
+	LastRun *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_run,json=lastRun,proto3" json:"last_run,omitempty"`
+	// Empty if the last run succeeded.
+	LastError     string               `protobuf:"bytes,5,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	LastDuration  *durationpb.Duration `protobuf:"bytes,6,opt,name=last_duration,json=lastDuration,proto3" json:"last_duration,omitempty"`
+	RunCount      int64                `protobuf:"varint,7,opt,name=run_count,json=runCount,proto3" json:"run_count,omitempty"`
+	Paused        bool                 `protobuf:"varint,8,opt,name=paused,proto3" json:"paused,omitempty"`
+	Metadata      map[string]string    `protobuf:"bytes,9,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Job) Reset() {
+	*x = Job{}
+	mi := &file_cronjobpb_cronjob_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Job) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job) ProtoMessage() {}
+
+func (x *Job) ProtoReflect() protoreflect.Message {
+	mi := &file_cronjobpb_cronjob_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job.ProtoReflect.Descriptor instead.
+func (*Job) Descriptor() ([]byte, []int) {
+	return file_cronjobpb_cronjob_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Job) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Job) GetExpression() string {
+	if x != nil {
+		return x.Expression
+	}
+	return ""
+}
+
+func (x *Job) GetNextRun() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NextRun
+	}
+	return nil
+}
+
+func (x *Job) GetLastRun() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastRun
+	}
+	return nil
+}
+
+func (x *Job) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *Job) GetLastDuration() *durationpb.Duration {
+	if x != nil {
+		return x.LastDuration
+	}
+	return nil
+}
+
+func (x *Job) GetRunCount() int64 {
+	if x != nil {
+		return x.RunCount
+	}
+	return 0
+}
+
+func (x *Job) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *Job) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type AddJobRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name the task was registered under, also used as the job's ID.
+	Name          string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Expression    string `protobuf:"bytes,2,opt,name=expression,proto3" json:"expression,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddJobRequest) Reset() {
+	*x = AddJobRequest{}
+	mi := &file_cronjobpb_cronjob_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddJobRequest) ProtoMessage() {}
+
+func (x *AddJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cronjobpb_cronjob_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddJobRequest.ProtoReflect.Descriptor instead.
+func (*AddJobRequest) Descriptor() ([]byte, []int) {
+	return file_cronjobpb_cronjob_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AddJobRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AddJobRequest) GetExpression() string {
+	if x != nil {
+		return x.Expression
+	}
+	return ""
+}
+
+type PauseJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PauseJobRequest) Reset() {
+	*x = PauseJobRequest{}
+	mi := &file_cronjobpb_cronjob_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PauseJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseJobRequest) ProtoMessage() {}
+
+func (x *PauseJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cronjobpb_cronjob_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseJobRequest.ProtoReflect.Descriptor instead.
+func (*PauseJobRequest) Descriptor() ([]byte, []int) {
+	return file_cronjobpb_cronjob_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PauseJobRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ResumeJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeJobRequest) Reset() {
+	*x = ResumeJobRequest{}
+	mi := &file_cronjobpb_cronjob_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeJobRequest) ProtoMessage() {}
+
+func (x *ResumeJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cronjobpb_cronjob_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeJobRequest.ProtoReflect.Descriptor instead.
+func (*ResumeJobRequest) Descriptor() ([]byte, []int) {
+	return file_cronjobpb_cronjob_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ResumeJobRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type TriggerJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerJobRequest) Reset() {
+	*x = TriggerJobRequest{}
+	mi := &file_cronjobpb_cronjob_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerJobRequest) ProtoMessage() {}
+
+func (x *TriggerJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cronjobpb_cronjob_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerJobRequest.ProtoReflect.Descriptor instead.
+func (*TriggerJobRequest) Descriptor() ([]byte, []int) {
+	return file_cronjobpb_cronjob_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TriggerJobRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type TriggerJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerJobResponse) Reset() {
+	*x = TriggerJobResponse{}
+	mi := &file_cronjobpb_cronjob_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerJobResponse) ProtoMessage() {}
+
+func (x *TriggerJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cronjobpb_cronjob_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerJobResponse.ProtoReflect.Descriptor instead.
+func (*TriggerJobResponse) Descriptor() ([]byte, []int) {
+	return file_cronjobpb_cronjob_proto_rawDescGZIP(), []int{5}
+}
+
+type ListJobsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsRequest) Reset() {
+	*x = ListJobsRequest{}
+	mi := &file_cronjobpb_cronjob_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsRequest) ProtoMessage() {}
+
+func (x *ListJobsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cronjobpb_cronjob_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsRequest.ProtoReflect.Descriptor instead.
+func (*ListJobsRequest) Descriptor() ([]byte, []int) {
+	return file_cronjobpb_cronjob_proto_rawDescGZIP(), []int{6}
+}
+
+type ListJobsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jobs          []*Job                 `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsResponse) Reset() {
+	*x = ListJobsResponse{}
+	mi := &file_cronjobpb_cronjob_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsResponse) ProtoMessage() {}
+
+func (x *ListJobsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cronjobpb_cronjob_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsResponse.ProtoReflect.Descriptor instead.
+func (*ListJobsResponse) Descriptor() ([]byte, []int) {
+	return file_cronjobpb_cronjob_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListJobsResponse) GetJobs() []*Job {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+var File_cronjobpb_cronjob_proto protoreflect.FileDescriptor
+
+const file_cronjobpb_cronjob_proto_rawDesc = "" +
+	"\n" +
+	"\x17cronjobpb/cronjob.proto\x12\n" +
+	"cronjob.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xaf\x03\n" +
+	"\x03Job\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1e\n" +
+	"\n" +
+	"expression\x18\x02 \x01(\tR\n" +
+	"expression\x125\n" +
+	"\bnext_run\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\anextRun\x125\n" +
+	"\blast_run\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\alastRun\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x05 \x01(\tR\tlastError\x12>\n" +
+	"\rlast_duration\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\flastDuration\x12\x1b\n" +
+	"\trun_count\x18\a \x01(\x03R\brunCount\x12\x16\n" +
+	"\x06paused\x18\b \x01(\bR\x06paused\x129\n" +
+	"\bmetadata\x18\t \x03(\v2\x1d.cronjob.v1.Job.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"C\n" +
+	"\rAddJobRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1e\n" +
+	"\n" +
+	"expression\x18\x02 \x01(\tR\n" +
+	"expression\"!\n" +
+	"\x0fPauseJobRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\"\n" +
+	"\x10ResumeJobRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"#\n" +
+	"\x11TriggerJobRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x14\n" +
+	"\x12TriggerJobResponse\"\x11\n" +
+	"\x0fListJobsRequest\"7\n" +
+	"\x10ListJobsResponse\x12#\n" +
+	"\x04jobs\x18\x01 \x03(\v2\x0f.cronjob.v1.JobR\x04jobs2\xcf\x02\n" +
+	"\rCronScheduler\x124\n" +
+	"\x06AddJob\x12\x19.cronjob.v1.AddJobRequest\x1a\x0f.cronjob.v1.Job\x128\n" +
+	"\bPauseJob\x12\x1b.cronjob.v1.PauseJobRequest\x1a\x0f.cronjob.v1.Job\x12:\n" +
+	"\tResumeJob\x12\x1c.cronjob.v1.ResumeJobRequest\x1a\x0f.cronjob.v1.Job\x12K\n" +
+	"\n" +
+	"TriggerJob\x12\x1d.cronjob.v1.TriggerJobRequest\x1a\x1e.cronjob.v1.TriggerJobResponse\x12E\n" +
+	"\bListJobs\x12\x1b.cronjob.v1.ListJobsRequest\x1a\x1c.cronjob.v1.ListJobsResponseB4Z2github.com/flyzard/go-cronjob/grpcserver/cronjobpbb\x06proto3"
+
+var (
+	file_cronjobpb_cronjob_proto_rawDescOnce sync.Once
+	file_cronjobpb_cronjob_proto_rawDescData []byte
+)
+
+func file_cronjobpb_cronjob_proto_rawDescGZIP() []byte {
+	file_cronjobpb_cronjob_proto_rawDescOnce.Do(func() {
+		file_cronjobpb_cronjob_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_cronjobpb_cronjob_proto_rawDesc), len(file_cronjobpb_cronjob_proto_rawDesc)))
+	})
+	return file_cronjobpb_cronjob_proto_rawDescData
+}
+
+var file_cronjobpb_cronjob_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_cronjobpb_cronjob_proto_goTypes = []any{
+	(*Job)(nil),                   // 0: cronjob.v1.Job
+	(*AddJobRequest)(nil),         // 1: cronjob.v1.AddJobRequest
+	(*PauseJobRequest)(nil),       // 2: cronjob.v1.PauseJobRequest
+	(*ResumeJobRequest)(nil),      // 3: cronjob.v1.ResumeJobRequest
+	(*TriggerJobRequest)(nil),     // 4: cronjob.v1.TriggerJobRequest
+	(*TriggerJobResponse)(nil),    // 5: cronjob.v1.TriggerJobResponse
+	(*ListJobsRequest)(nil),       // 6: cronjob.v1.ListJobsRequest
+	(*ListJobsResponse)(nil),      // 7: cronjob.v1.ListJobsResponse
+	nil,                           // 8: cronjob.v1.Job.MetadataEntry
+	(*timestamppb.Timestamp)(nil), // 9: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 10: google.protobuf.Duration
+}
+var file_cronjobpb_cronjob_proto_depIdxs = []int32{
+	9,  // 0: cronjob.v1.Job.next_run:type_name -> google.protobuf.Timestamp
+	9,  // 1: cronjob.v1.Job.last_run:type_name -> google.protobuf.Timestamp
+	10, // 2: cronjob.v1.Job.last_duration:type_name -> google.protobuf.Duration
+	8,  // 3: cronjob.v1.Job.metadata:type_name -> cronjob.v1.Job.MetadataEntry
+	0,  // 4: cronjob.v1.ListJobsResponse.jobs:type_name -> cronjob.v1.Job
+	1,  // 5: cronjob.v1.CronScheduler.AddJob:input_type -> cronjob.v1.AddJobRequest
+	2,  // 6: cronjob.v1.CronScheduler.PauseJob:input_type -> cronjob.v1.PauseJobRequest
+	3,  // 7: cronjob.v1.CronScheduler.ResumeJob:input_type -> cronjob.v1.ResumeJobRequest
+	4,  // 8: cronjob.v1.CronScheduler.TriggerJob:input_type -> cronjob.v1.TriggerJobRequest
+	6,  // 9: cronjob.v1.CronScheduler.ListJobs:input_type -> cronjob.v1.ListJobsRequest
+	0,  // 10: cronjob.v1.CronScheduler.AddJob:output_type -> cronjob.v1.Job
+	0,  // 11: cronjob.v1.CronScheduler.PauseJob:output_type -> cronjob.v1.Job
+	0,  // 12: cronjob.v1.CronScheduler.ResumeJob:output_type -> cronjob.v1.Job
+	5,  // 13: cronjob.v1.CronScheduler.TriggerJob:output_type -> cronjob.v1.TriggerJobResponse
+	7,  // 14: cronjob.v1.CronScheduler.ListJobs:output_type -> cronjob.v1.ListJobsResponse
+	10, // [10:15] is the sub-list for method output_type
+	5,  // [5:10] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_cronjobpb_cronjob_proto_init() }
+func file_cronjobpb_cronjob_proto_init() {
+	if File_cronjobpb_cronjob_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cronjobpb_cronjob_proto_rawDesc), len(file_cronjobpb_cronjob_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_cronjobpb_cronjob_proto_goTypes,
+		DependencyIndexes: file_cronjobpb_cronjob_proto_depIdxs,
+		MessageInfos:      file_cronjobpb_cronjob_proto_msgTypes,
+	}.Build()
+	File_cronjobpb_cronjob_proto = out.File
+	file_cronjobpb_cronjob_proto_goTypes = nil
+	file_cronjobpb_cronjob_proto_depIdxs = nil
+}
@@ -0,0 +1,287 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: cronjobpb/cronjob.proto
+
+package cronjobpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CronScheduler_AddJob_FullMethodName     = "/cronjob.v1.CronScheduler/AddJob"
+	CronScheduler_PauseJob_FullMethodName   = "/cronjob.v1.CronScheduler/PauseJob"
+	CronScheduler_ResumeJob_FullMethodName  = "/cronjob.v1.CronScheduler/ResumeJob"
+	CronScheduler_TriggerJob_FullMethodName = "/cronjob.v1.CronScheduler/TriggerJob"
+	CronScheduler_ListJobs_FullMethodName   = "/cronjob.v1.CronScheduler/ListJobs"
+)
+
+// CronSchedulerClient is the client API for CronScheduler service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// CronScheduler manages the jobs of a running scheduler.
+type CronSchedulerClient interface {
+	// AddJob schedules the task registered under name with RegisterTask.
+	AddJob(ctx context.Context, in *AddJobRequest, opts ...grpc.CallOption) (*Job, error)
+	// PauseJob stops a job from firing until it is resumed.
+	PauseJob(ctx context.Context, in *PauseJobRequest, opts ...grpc.CallOption) (*Job, error)
+	// ResumeJob makes a paused job fire again.
+	ResumeJob(ctx context.Context, in *ResumeJobRequest, opts ...grpc.CallOption) (*Job, error)
+	// TriggerJob runs a job now, outside its schedule.
+	TriggerJob(ctx context.Context, in *TriggerJobRequest, opts ...grpc.CallOption) (*TriggerJobResponse, error)
+	// ListJobs returns every job, in the order the jobs were added.
+	ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsResponse, error)
+}
+
+type cronSchedulerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCronSchedulerClient(cc grpc.ClientConnInterface) CronSchedulerClient {
+	return &cronSchedulerClient{cc}
+}
+
+func (c *cronSchedulerClient) AddJob(ctx context.Context, in *AddJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, CronScheduler_AddJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cronSchedulerClient) PauseJob(ctx context.Context, in *PauseJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, CronScheduler_PauseJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cronSchedulerClient) ResumeJob(ctx context.Context, in *ResumeJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, CronScheduler_ResumeJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cronSchedulerClient) TriggerJob(ctx context.Context, in *TriggerJobRequest, opts ...grpc.CallOption) (*TriggerJobResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TriggerJobResponse)
+	err := c.cc.Invoke(ctx, CronScheduler_TriggerJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cronSchedulerClient) ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListJobsResponse)
+	err := c.cc.Invoke(ctx, CronScheduler_ListJobs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CronSchedulerServer is the server API for CronScheduler service.
+// All implementations must embed UnimplementedCronSchedulerServer
+// for forward compatibility.
+//
+// CronScheduler manages the jobs of a running scheduler.
+type CronSchedulerServer interface {
+	// AddJob schedules the task registered under name with RegisterTask.
+	AddJob(context.Context, *AddJobRequest) (*Job, error)
+	// PauseJob stops a job from firing until it is resumed.
+	PauseJob(context.Context, *PauseJobRequest) (*Job, error)
+	// ResumeJob makes a paused job fire again.
+	ResumeJob(context.Context, *ResumeJobRequest) (*Job, error)
+	// TriggerJob runs a job now, outside its schedule.
+	TriggerJob(context.Context, *TriggerJobRequest) (*TriggerJobResponse, error)
+	// ListJobs returns every job, in the order the jobs were added.
+	ListJobs(context.Context, *ListJobsRequest) (*ListJobsResponse, error)
+	mustEmbedUnimplementedCronSchedulerServer()
+}
+
+// UnimplementedCronSchedulerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCronSchedulerServer struct{}
+
+func (UnimplementedCronSchedulerServer) AddJob(context.Context, *AddJobRequest) (*Job, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddJob not implemented")
+}
+func (UnimplementedCronSchedulerServer) PauseJob(context.Context, *PauseJobRequest) (*Job, error) {
+	return nil, status.Error(codes.Unimplemented, "method PauseJob not implemented")
+}
+func (UnimplementedCronSchedulerServer) ResumeJob(context.Context, *ResumeJobRequest) (*Job, error) {
+	return nil, status.Error(codes.Unimplemented, "method ResumeJob not implemented")
+}
+func (UnimplementedCronSchedulerServer) TriggerJob(context.Context, *TriggerJobRequest) (*TriggerJobResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method TriggerJob not implemented")
+}
+func (UnimplementedCronSchedulerServer) ListJobs(context.Context, *ListJobsRequest) (*ListJobsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListJobs not implemented")
+}
+func (UnimplementedCronSchedulerServer) mustEmbedUnimplementedCronSchedulerServer() {}
+func (UnimplementedCronSchedulerServer) testEmbeddedByValue()                       {}
+
+// UnsafeCronSchedulerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CronSchedulerServer will
+// result in compilation errors.
+type UnsafeCronSchedulerServer interface {
+	mustEmbedUnimplementedCronSchedulerServer()
+}
+
+func RegisterCronSchedulerServer(s grpc.ServiceRegistrar, srv CronSchedulerServer) {
+	// If the following call panics, it indicates UnimplementedCronSchedulerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CronScheduler_ServiceDesc, srv)
+}
+
+func _CronScheduler_AddJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CronSchedulerServer).AddJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CronScheduler_AddJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CronSchedulerServer).AddJob(ctx, req.(*AddJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CronScheduler_PauseJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CronSchedulerServer).PauseJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CronScheduler_PauseJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CronSchedulerServer).PauseJob(ctx, req.(*PauseJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CronScheduler_ResumeJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CronSchedulerServer).ResumeJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CronScheduler_ResumeJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CronSchedulerServer).ResumeJob(ctx, req.(*ResumeJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CronScheduler_TriggerJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TriggerJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CronSchedulerServer).TriggerJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CronScheduler_TriggerJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CronSchedulerServer).TriggerJob(ctx, req.(*TriggerJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CronScheduler_ListJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListJobsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CronSchedulerServer).ListJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CronScheduler_ListJobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CronSchedulerServer).ListJobs(ctx, req.(*ListJobsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CronScheduler_ServiceDesc is the grpc.ServiceDesc for CronScheduler service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CronScheduler_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cronjob.v1.CronScheduler",
+	HandlerType: (*CronSchedulerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddJob",
+			Handler:    _CronScheduler_AddJob_Handler,
+		},
+		{
+			MethodName: "PauseJob",
+			Handler:    _CronScheduler_PauseJob_Handler,
+		},
+		{
+			MethodName: "ResumeJob",
+			Handler:    _CronScheduler_ResumeJob_Handler,
+		},
+		{
+			MethodName: "TriggerJob",
+			Handler:    _CronScheduler_TriggerJob_Handler,
+		},
+		{
+			MethodName: "ListJobs",
+			Handler:    _CronScheduler_ListJobs_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "cronjobpb/cronjob.proto",
+}
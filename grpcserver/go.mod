@@ -0,0 +1,19 @@
+module github.com/flyzard/go-cronjob/grpcserver
+
+go 1.23.1
+
+require (
+	github.com/flyzard/go-cronjob v1.0.2
+	google.golang.org/grpc v1.67.1
+	google.golang.org/protobuf v1.36.11
+)
+
+require (
+	golang.org/x/net v0.28.0 // indirect
+	golang.org/x/sys v0.24.0 // indirect
+	golang.org/x/text v0.19.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240814211410-ddb44dafa142 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/flyzard/go-cronjob => ../
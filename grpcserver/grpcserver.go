@@ -0,0 +1,132 @@
+// Package grpcserver serves a cronjob.CronScheduler over gRPC, so jobs can be
+// added, paused, resumed, triggered and listed remotely. The service is
+// defined in cronjobpb/cronjob.proto.
+//
+// It lives in its own module so the cronjob package does not depend on gRPC.
+// Like the scheduler's HTTP Handler, it has no authentication of its own:
+// add it with grpc.Creds or an interceptor.
+package grpcserver
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative cronjobpb/cronjob.proto
+
+import (
+	"context"
+	"errors"
+
+	cronjob "github.com/flyzard/go-cronjob"
+	"github.com/flyzard/go-cronjob/grpcserver/cronjobpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server implements cronjobpb.CronSchedulerServer for a scheduler.
+type Server struct {
+	cronjobpb.UnimplementedCronSchedulerServer
+	scheduler *cronjob.CronScheduler
+}
+
+// NewServer returns a Server managing scheduler. Jobs can only be added by
+// the name of a task registered with scheduler.RegisterTask.
+func NewServer(scheduler *cronjob.CronScheduler) *Server {
+	return &Server{scheduler: scheduler}
+}
+
+// Register registers a Server for scheduler with s.
+func Register(s grpc.ServiceRegistrar, scheduler *cronjob.CronScheduler) {
+	cronjobpb.RegisterCronSchedulerServer(s, NewServer(scheduler))
+}
+
+// AddJob schedules the task registered under req.Name.
+func (s *Server) AddJob(ctx context.Context, req *cronjobpb.AddJobRequest) (*cronjobpb.Job, error) {
+	if err := s.scheduler.Validate(req.GetExpression()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := s.scheduler.AddRegisteredJob(req.GetName(), req.GetExpression()); err != nil {
+		return nil, toStatus(err)
+	}
+	return s.job(req.GetName())
+}
+
+// PauseJob pauses the job with the given ID.
+func (s *Server) PauseJob(ctx context.Context, req *cronjobpb.PauseJobRequest) (*cronjobpb.Job, error) {
+	if err := s.scheduler.PauseJob(req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return s.job(req.GetId())
+}
+
+// ResumeJob resumes the job with the given ID.
+func (s *Server) ResumeJob(ctx context.Context, req *cronjobpb.ResumeJobRequest) (*cronjobpb.Job, error) {
+	if err := s.scheduler.ResumeJob(req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return s.job(req.GetId())
+}
+
+// TriggerJob starts a run of the job with the given ID without waiting for
+// it to finish.
+func (s *Server) TriggerJob(ctx context.Context, req *cronjobpb.TriggerJobRequest) (*cronjobpb.TriggerJobResponse, error) {
+	if err := s.scheduler.RunNow(req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &cronjobpb.TriggerJobResponse{}, nil
+}
+
+// ListJobs returns every job in the scheduler.
+func (s *Server) ListJobs(ctx context.Context, req *cronjobpb.ListJobsRequest) (*cronjobpb.ListJobsResponse, error) {
+	infos := s.scheduler.ListJobInfo()
+	jobs := make([]*cronjobpb.Job, 0, len(infos))
+	for _, info := range infos {
+		jobs = append(jobs, newJob(info))
+	}
+	return &cronjobpb.ListJobsResponse{Jobs: jobs}, nil
+}
+
+func (s *Server) job(id string) (*cronjobpb.Job, error) {
+	info, err := s.scheduler.JobInfo(id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return newJob(info), nil
+}
+
+func newJob(info cronjob.JobInfo) *cronjobpb.Job {
+	job := &cronjobpb.Job{
+		Id:         info.ID,
+		Expression: info.Expression,
+		RunCount:   int64(info.RunCount),
+		Paused:     info.Paused,
+		Metadata:   info.Metadata,
+	}
+	if !info.NextRun.IsZero() {
+		job.NextRun = timestamppb.New(info.NextRun)
+	}
+	if !info.LastRun.IsZero() {
+		job.LastRun = timestamppb.New(info.LastRun)
+		job.LastDuration = durationpb.New(info.LastDuration)
+	}
+	if info.LastError != nil {
+		job.LastError = info.LastError.Error()
+	}
+	return job
+}
+
+// toStatus maps a scheduler error to its gRPC status.
+func toStatus(err error) error {
+	var fieldErr *cronjob.FieldError
+	switch {
+	case errors.Is(err, cronjob.ErrJobNotFound), errors.Is(err, cronjob.ErrTaskNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, cronjob.ErrDuplicateJobID):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, cronjob.ErrJobSkipped):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.As(err, &fieldErr):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,98 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	cronjob "github.com/flyzard/go-cronjob"
+	"github.com/flyzard/go-cronjob/grpcserver/cronjobpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServer(t *testing.T) {
+	scheduler := cronjob.NewCronScheduler()
+	ran := make(chan struct{}, 1)
+	scheduler.RegisterTask("report", func(ctx context.Context) error {
+		ran <- struct{}{}
+		return nil
+	})
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	Register(server, scheduler)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := cronjobpb.NewCronSchedulerClient(conn)
+	ctx := context.Background()
+
+	job, err := client.AddJob(ctx, &cronjobpb.AddJobRequest{Name: "report", Expression: "0 0 1 1 *"})
+	if err != nil {
+		t.Fatalf("AddJob: %v", err)
+	}
+	if job.GetId() != "report" || job.GetExpression() != "0 0 1 1 *" || job.GetNextRun() == nil {
+		t.Errorf("AddJob returned %v", job)
+	}
+
+	tests := []struct {
+		name string
+		req  *cronjobpb.AddJobRequest
+		code codes.Code
+	}{
+		{"unregistered task", &cronjobpb.AddJobRequest{Name: "missing", Expression: "* * * * *"}, codes.NotFound},
+		{"duplicate", &cronjobpb.AddJobRequest{Name: "report", Expression: "* * * * *"}, codes.AlreadyExists},
+		{"invalid expression", &cronjobpb.AddJobRequest{Name: "report", Expression: "61 * * * *"}, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.AddJob(ctx, tt.req)
+			if status.Code(err) != tt.code {
+				t.Errorf("AddJob error = %v, want code %v", err, tt.code)
+			}
+		})
+	}
+
+	if job, err = client.PauseJob(ctx, &cronjobpb.PauseJobRequest{Id: "report"}); err != nil || !job.GetPaused() {
+		t.Errorf("PauseJob = %v, %v, want paused job", job, err)
+	}
+	if job, err = client.ResumeJob(ctx, &cronjobpb.ResumeJobRequest{Id: "report"}); err != nil || job.GetPaused() {
+		t.Errorf("ResumeJob = %v, %v, want resumed job", job, err)
+	}
+	if _, err = client.PauseJob(ctx, &cronjobpb.PauseJobRequest{Id: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("PauseJob of unknown job error = %v, want NotFound", err)
+	}
+
+	if _, err = client.TriggerJob(ctx, &cronjobpb.TriggerJobRequest{Id: "report"}); err != nil {
+		t.Fatalf("TriggerJob: %v", err)
+	}
+	select {
+	case <-ran:
+	case <-time.After(2 * time.Second):
+		t.Fatal("triggered job did not run")
+	}
+
+	list, err := client.ListJobs(ctx, &cronjobpb.ListJobsRequest{})
+	if err != nil {
+		t.Fatalf("ListJobs: %v", err)
+	}
+	if len(list.GetJobs()) != 1 || list.GetJobs()[0].GetId() != "report" {
+		t.Errorf("ListJobs = %v, want the report job", list.GetJobs())
+	}
+}
@@ -0,0 +1,98 @@
+package cronjob
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// hashRange is the range "H" picks from in each field. Days of the month
+// stop at 28, so a hashed day exists in every month.
+var hashRange = map[string][2]int{
+	"second":       {0, 59},
+	"minute":       {0, 59},
+	"hour":         {0, 23},
+	"day-of-month": {1, 28},
+	"month":        {1, 12},
+	"day-of-week":  {0, 6},
+}
+
+// expandHash rewrites the "H" parts of the named field as plain values
+// hashed from key, and reports whether there were any:
+//
+//	H        one value in the field's range
+//	H(a-b)   one value in a-b
+//	H/n      every nth value, from an offset below n
+//	H(a-b)/n every nth value in a-b, from an offset below n
+//
+// The same key and field always give the same values, and different keys
+// spread over the range, so jobs sharing a schedule don't start together.
+// With QuartzWeekdays, the day-of-week values run from 1 to 7 instead, and
+// pick the same days.
+func expandHash(field, name, key string, numbering WeekdayNumbering) (string, bool, error) {
+	if !strings.Contains(field, "H") {
+		return field, false, nil
+	}
+	bounds, ok := hashRange[name]
+	if !ok {
+		return "", false, fmt.Errorf("H is not allowed in the %s field", name)
+	}
+	if name == "day-of-week" && numbering == QuartzWeekdays {
+		bounds = [2]int{1, 7}
+	}
+	sum := hashOf(key, name)
+	parts := strings.Split(field, ",")
+	hashed := false
+	for i, part := range parts {
+		if !strings.HasPrefix(part, "H") {
+			continue
+		}
+		hashed = true
+		lo, hi := bounds[0], bounds[1]
+		rest := part[1:]
+		if strings.HasPrefix(rest, "(") {
+			end := strings.Index(rest, ")")
+			if end < 0 {
+				return "", false, fmt.Errorf("invalid hash range: %s", part)
+			}
+			start, stop, ok := strings.Cut(rest[1:end], "-")
+			var err error
+			if lo, err = parseValue(start, bounds[0], bounds[1], nil); !ok || err != nil {
+				return "", false, fmt.Errorf("invalid hash range: %s", part)
+			}
+			if hi, err = parseValue(stop, bounds[0], bounds[1], nil); err != nil || lo > hi {
+				return "", false, fmt.Errorf("invalid hash range: %s", part)
+			}
+			rest = rest[end+1:]
+		}
+		span := uint64(hi - lo + 1)
+		switch {
+		case rest == "":
+			parts[i] = strconv.Itoa(lo + int(sum%span))
+		case strings.HasPrefix(rest, "/"):
+			step, err := strconv.Atoi(rest[1:])
+			if err != nil || step <= 0 {
+				return "", false, fmt.Errorf("invalid step: %s", rest[1:])
+			}
+			var values []string
+			for v := lo + int(sum%min(uint64(step), span)); v <= hi; v += step {
+				values = append(values, strconv.Itoa(v))
+			}
+			parts[i] = strings.Join(values, ",")
+		default:
+			return "", false, fmt.Errorf("invalid hash: %s", part)
+		}
+	}
+	return strings.Join(parts, ","), hashed, nil
+}
+
+// hashOf hashes key for the named field, so fields of the same key get
+// unrelated values.
+func hashOf(key, name string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	h.Write([]byte{0})
+	h.Write([]byte(name))
+	return h.Sum64()
+}
@@ -0,0 +1,129 @@
+package cronjob
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// DefaultOverdueTolerance is how late a run may be before Health reports
+// its job as overdue, unless changed with WithOverdueTolerance.
+const DefaultOverdueTolerance = time.Minute
+
+// WithOverdueTolerance sets how long past its fire time a run may still be
+// waiting to start before Health reports the job as overdue.
+func WithOverdueTolerance(d time.Duration) SchedulerOption {
+	return func(c *CronScheduler) {
+		c.overdueTolerance = d
+	}
+}
+
+// DefaultLatenessTolerance is how late a scheduled run may start before an
+// EventMissedDeadline is sent, unless changed with WithLatenessTolerance.
+const DefaultLatenessTolerance = time.Second
+
+// WithLatenessTolerance sets how late after its fire time a scheduled run
+// may start, for example after waiting for a WithMaxConcurrentJobs slot or
+// a free worker, before the scheduler sends an EventMissedDeadline and, with
+// WithLogger, logs a warning, so overloaded schedulers can be detected. A
+// job's WithJitter delay is added to the tolerance. Jobs with
+// WithDependsOn, which wait for their dependencies by design, and reruns of
+// interrupted WithAtLeastOnce runs are not checked. Zero or a negative d
+// disables the check.
+func WithLatenessTolerance(d time.Duration) SchedulerOption {
+	return func(c *CronScheduler) {
+		c.latenessTolerance = d
+	}
+}
+
+// checkLateness reports a run of job scheduled at tick that starts at start
+// if it is later than the lateness tolerance allows. The caller must hold
+// c.mutex.
+func (c *CronScheduler) checkLateness(job *Job, tick, start time.Time) {
+	if tick.IsZero() || tick.Equal(job.recovering) || c.latenessTolerance <= 0 || len(job.dependsOn) > 0 {
+		return
+	}
+	late := start.Sub(tick)
+	if late <= c.latenessTolerance+job.jitter {
+		return
+	}
+	c.publish(JobEvent{Type: EventMissedDeadline, JobID: job.ID, Time: start, Late: late})
+	if c.logger != nil {
+		c.logger.Warn("job missed deadline",
+			slog.String("job", job.ID),
+			slog.Time("scheduled_time", tick),
+			slog.Duration("late", late))
+	}
+}
+
+// HealthStatus summarizes the state of a scheduler for liveness checks.
+type HealthStatus struct {
+	// Healthy reports whether the scheduler is running, its scheduling
+	// loop has ticked recently and no job is overdue.
+	Healthy bool `json:"healthy"`
+	Running bool `json:"running"`
+	// Paused reports whether scheduled runs are held with Pause, which
+	// does not make the scheduler unhealthy.
+	Paused bool `json:"paused"`
+	Jobs   int  `json:"jobs"`
+	// Queued is the number of runs queued across all jobs, as reported by
+	// QueueDepth. A lasting high value means jobs are falling behind.
+	Queued int `json:"queued"`
+	// Overdue lists the jobs whose next run is more than the overdue
+	// tolerance past its fire time without having started.
+	Overdue []string `json:"overdue,omitempty"`
+	// Disabled lists the jobs disabled by WithPanicLimit. They do not make
+	// the scheduler unhealthy, since restarting it would not fix them.
+	Disabled []string `json:"disabled,omitempty"`
+	// LastTick is when the scheduling loop last woke up, which it does at
+	// least once a minute while running.
+	LastTick time.Time `json:"last_tick"`
+}
+
+// Health returns a summary of the scheduler's state. A running scheduler
+// is unhealthy if a job is overdue, or if its loop has not woken up for
+// longer than a minute plus the overdue tolerance, both of which mean it is
+// stuck.
+func (c *CronScheduler) Health() HealthStatus {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := time.Now()
+	status := HealthStatus{
+		Running:  c.running,
+		Paused:   c.paused,
+		Jobs:     len(c.jobs),
+		Queued:   c.queuedRuns,
+		LastTick: c.lastTick,
+	}
+	for _, job := range c.jobs {
+		if job.disabled {
+			status.Disabled = append(status.Disabled, job.ID)
+		}
+	}
+	if !c.running {
+		return status
+	}
+	for _, job := range c.jobs {
+		if job.index >= 0 && now.Sub(job.startAt()) > c.overdueTolerance {
+			status.Overdue = append(status.Overdue, job.ID)
+		}
+	}
+	status.Healthy = len(status.Overdue) == 0 && now.Sub(c.lastTick) <= maxLoopSleep+c.overdueTolerance
+	return status
+}
+
+// HealthHandler returns an http.Handler serving Health as JSON, with status
+// 200 when the scheduler is healthy and 503 otherwise, for use as a
+// Kubernetes liveness probe:
+//
+//	http.Handle("/healthz", scheduler.HealthHandler())
+func (c *CronScheduler) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		health := c.Health()
+		status := http.StatusOK
+		if !health.Healthy {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, health)
+	})
+}
@@ -0,0 +1,146 @@
+package cronjob
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultHistorySize is the number of runs kept per job unless changed with
+// WithHistorySize.
+const DefaultHistorySize = 10
+
+// RunOutcome classifies how a run ended.
+type RunOutcome int
+
+const (
+	// OutcomeSuccess means the task returned without error.
+	OutcomeSuccess RunOutcome = iota
+	// OutcomeFailure means the task returned an error.
+	OutcomeFailure
+	// OutcomePanic means the task panicked.
+	OutcomePanic
+	// OutcomeTimeout means the task exceeded the job's timeout.
+	OutcomeTimeout
+	// OutcomeSoftFailure means the task returned an error wrapping
+	// ErrSoftFailure.
+	OutcomeSoftFailure
+)
+
+func (o RunOutcome) String() string {
+	switch o {
+	case OutcomeSuccess:
+		return "success"
+	case OutcomeFailure:
+		return "failure"
+	case OutcomePanic:
+		return "panic"
+	case OutcomeTimeout:
+		return "timeout"
+	case OutcomeSoftFailure:
+		return "soft_failure"
+	default:
+		return fmt.Sprintf("RunOutcome(%d)", int(o))
+	}
+}
+
+// outcomeOf classifies the error of a finished run.
+func outcomeOf(err error) RunOutcome {
+	var panicErr *PanicError
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.As(err, &panicErr):
+		return OutcomePanic
+	case errors.Is(err, ErrJobTimeout):
+		return OutcomeTimeout
+	case errors.Is(err, ErrSoftFailure):
+		return OutcomeSoftFailure
+	default:
+		return OutcomeFailure
+	}
+}
+
+// RunRecord describes a single finished run of a job.
+type RunRecord struct {
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+	Outcome  RunOutcome
+	// Err is the run's error, or nil if it succeeded.
+	Err error
+	// Stdout and Stderr are the output captured by an ExecJob, from its
+	// last attempt.
+	Stdout string
+	Stderr string
+	// StatusCode is the response status of an HTTPJob's last attempt, or 0
+	// if it got no response.
+	StatusCode int
+}
+
+// WithHistorySize sets how many finished runs are kept per job for History.
+// Zero disables run history.
+func WithHistorySize(n int) SchedulerOption {
+	return func(c *CronScheduler) {
+		c.historySize = n
+	}
+}
+
+// WithOutputRetention keeps the captured output of only the job's n most
+// recent runs in its history, to bound the memory verbose commands use.
+// Older records keep everything else but have their Stdout and Stderr
+// cleared. Zero, the default, keeps the output of every record.
+func WithOutputRetention(n int) JobOption {
+	return func(j *Job) {
+		j.outputRetention = n
+	}
+}
+
+// History returns the job's most recent finished runs, oldest first.
+func (c *CronScheduler) History(id string) ([]RunRecord, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	job, ok := c.byID[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	return job.history.snapshot(), nil
+}
+
+// runHistory is a fixed-size ring buffer of run records.
+type runHistory struct {
+	records []RunRecord
+	next    int
+}
+
+// add appends r, overwriting the oldest record once size records are kept.
+func (h *runHistory) add(r RunRecord, size int) {
+	if size <= 0 {
+		return
+	}
+	if len(h.records) < size {
+		h.records = append(h.records, r)
+		return
+	}
+	h.records[h.next] = r
+	h.next = (h.next + 1) % len(h.records)
+}
+
+// snapshot returns a copy of the records, oldest first.
+func (h *runHistory) snapshot() []RunRecord {
+	records := make([]RunRecord, 0, len(h.records))
+	records = append(records, h.records[h.next:]...)
+	return append(records, h.records[:h.next]...)
+}
+
+// trimOutput clears the output of all but the keep most recent records. It
+// does nothing if keep is not positive.
+func (h *runHistory) trimOutput(keep int) {
+	if keep <= 0 {
+		return
+	}
+	for i := 0; i < len(h.records)-keep; i++ {
+		r := &h.records[(h.next+i)%len(h.records)]
+		r.Stdout, r.Stderr = "", ""
+	}
+}
@@ -0,0 +1,91 @@
+package cronjob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPJob sends an HTTP request as a job's task, for the common "hit this
+// endpoint every 5 minutes" jobs. The response's status code is recorded in
+// the run's RunRecord.
+type HTTPJob struct {
+	URL string
+	// Method defaults to GET.
+	Method  string
+	Headers http.Header
+	Body    string
+	// ExpectedStatus is the status code a successful response has. Zero
+	// accepts any 2xx status.
+	ExpectedStatus int
+	// Timeout, if positive, limits the request, including reading the
+	// response body.
+	Timeout time.Duration
+	// Client sends the request. If it is nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+// AddHTTPJob adds a new job sending request and returns it, with its
+// generated ID.
+// A run fails if the request cannot be sent, times out or gets a response
+// with an unexpected status.
+func (c *CronScheduler) AddHTTPJob(expr string, request HTTPJob, opts ...JobOption) (*Job, error) {
+	job, err := c.newJob(expr, request.Run, opts)
+	if err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.setID(job, c.generateID())
+	c.insertJob(job)
+	return job, nil
+}
+
+// Run sends the request once, cancelling it when ctx is done or its
+// Timeout passes. If ctx is a run's context, the response's status code is
+// recorded in the run's RunRecord, so Run can also be registered with
+// RegisterTask.
+func (h HTTPJob) Run(ctx context.Context) error {
+	if h.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+	}
+	method := h.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	var body io.Reader
+	if h.Body != "" {
+		body = strings.NewReader(h.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, h.URL, body)
+	if err != nil {
+		return err
+	}
+	for name, values := range h.Headers {
+		req.Header[name] = values
+	}
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	// Drain the body so the connection can be reused.
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if output, ok := ctx.Value(runOutputKey{}).(*runOutput); ok {
+		output.setStatus(resp.StatusCode)
+	}
+	if h.ExpectedStatus != 0 && resp.StatusCode != h.ExpectedStatus ||
+		h.ExpectedStatus == 0 && (resp.StatusCode < 200 || resp.StatusCode > 299) {
+		return fmt.Errorf("%s %s: unexpected status %s", method, h.URL, resp.Status)
+	}
+	return nil
+}
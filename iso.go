@@ -0,0 +1,203 @@
+package cronjob
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RepeatingInterval is an ISO 8601 repeating interval, such as
+// "R/2025-01-01T00:00:00Z/P1D", used as a Schedule: it fires at Start and
+// then once every period, Repetitions times or forever.
+type RepeatingInterval struct {
+	// Start is the first fire time.
+	Start time.Time
+	// Years, Months and Days are the calendar part of the period, and
+	// Duration its time part.
+	Years, Months, Days int
+	Duration            time.Duration
+	// Repetitions is how many times the schedule fires, or -1 if it
+	// repeats forever.
+	Repetitions int
+}
+
+var _ Schedule = (*RepeatingInterval)(nil)
+
+// ParseRepeatingInterval parses an ISO 8601 repeating interval:
+// "Rn/start/period" fires n times from start, "R/start/period" forever,
+// and an end time may stand in for the period, as in
+// "R/2025-01-01T00:00:00Z/2025-01-01T06:00:00Z" for every 6 hours. Start
+// and end are RFC 3339 times, with a UTC offset or "Z"; periods are ISO
+// 8601 durations such as "P1D", "PT90M" or "P1Y2M10DT2H30M", with a decimal
+// fraction allowed in the seconds only, or weeks as in "P2W".
+//
+// Schedulers accept repeating intervals wherever they take a cron
+// expression, so AddJob("R5/2025-01-01T09:00:00Z/P1W", task) runs a task
+// on five consecutive Wednesdays.
+func ParseRepeatingInterval(expr string) (*RepeatingInterval, error) {
+	parts := strings.Split(strings.TrimSpace(expr), "/")
+	if len(parts) != 3 || !strings.HasPrefix(parts[0], "R") {
+		return nil, fmt.Errorf("invalid repeating interval: %s: expected R[n]/start/period", expr)
+	}
+	interval := &RepeatingInterval{Repetitions: -1}
+	if count := parts[0][1:]; count != "" {
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid repeating interval: %s: invalid repetitions: %s", expr, count)
+		}
+		interval.Repetitions = n
+	}
+
+	start, err := time.Parse(time.RFC3339Nano, parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid repeating interval: %s: invalid start: %w", expr, err)
+	}
+	interval.Start = start
+
+	if strings.HasPrefix(parts[2], "P") {
+		err = interval.parsePeriod(parts[2])
+	} else {
+		var end time.Time
+		if end, err = time.Parse(time.RFC3339Nano, parts[2]); err == nil {
+			interval.Duration = end.Sub(start)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid repeating interval: %s: invalid period: %w", expr, err)
+	}
+	if interval.Years <= 0 && interval.Months <= 0 && interval.Days <= 0 && interval.Duration <= 0 {
+		return nil, fmt.Errorf("invalid repeating interval: %s: period must be positive", expr)
+	}
+	return interval, nil
+}
+
+// isRepeatingInterval reports whether expr is written as an ISO 8601
+// repeating interval rather than a cron expression.
+func isRepeatingInterval(expr string) bool {
+	expr = strings.TrimSpace(expr)
+	return strings.HasPrefix(expr, "R") && strings.Contains(expr, "/") && !strings.ContainsAny(expr, " \t")
+}
+
+// parsePeriod sets the interval's period from an ISO 8601 duration.
+func (r *RepeatingInterval) parsePeriod(period string) error {
+	rest := strings.TrimPrefix(period, "P")
+	if rest == "" {
+		return fmt.Errorf("empty duration: %s", period)
+	}
+	inTime := false
+	for rest != "" {
+		if rest[0] == 'T' {
+			if inTime {
+				return fmt.Errorf("invalid duration: %s", period)
+			}
+			inTime, rest = true, rest[1:]
+			continue
+		}
+		i := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != '.' && r != ',' })
+		if i <= 0 {
+			return fmt.Errorf("invalid duration: %s", period)
+		}
+		number, unit := strings.Replace(rest[:i], ",", ".", 1), rest[i]
+		rest = rest[i+1:]
+		if unit == 'S' && inTime {
+			seconds, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return fmt.Errorf("invalid duration: %s", period)
+			}
+			r.Duration += time.Duration(seconds * float64(time.Second))
+			continue
+		}
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			return fmt.Errorf("invalid duration: %s: only seconds may have a fraction", period)
+		}
+		switch {
+		case unit == 'Y' && !inTime:
+			r.Years += n
+		case unit == 'M' && !inTime:
+			r.Months += n
+		case unit == 'W' && !inTime:
+			r.Days += 7 * n
+		case unit == 'D' && !inTime:
+			r.Days += n
+		case unit == 'H' && inTime:
+			r.Duration += time.Duration(n) * time.Hour
+		case unit == 'M' && inTime:
+			r.Duration += time.Duration(n) * time.Minute
+		default:
+			return fmt.Errorf("invalid duration: %s", period)
+		}
+	}
+	return nil
+}
+
+// Next returns the first fire time after from, in from's location, or the
+// zero time if the repetitions are used up or the period is not positive.
+// The kth fire time is k whole periods after Start, so a monthly interval
+// starting on the 31st fires on the days AddDate normalizes the 31st of
+// shorter months to.
+func (r *RepeatingInterval) Next(from time.Time) time.Time {
+	// Estimate how many periods have passed, then correct the estimate.
+	approx := time.Duration(r.Years)*8766*time.Hour + time.Duration(r.Months)*730*time.Hour +
+		time.Duration(r.Days)*24*time.Hour + r.Duration
+	if approx <= 0 {
+		return time.Time{}
+	}
+	if !from.Before(r.Start) {
+		k := int(from.Sub(r.Start) / approx)
+		for k > 0 && r.at(k-1).After(from) {
+			k--
+		}
+		for !r.at(k).After(from) {
+			k++
+		}
+		if r.Repetitions >= 0 && k >= r.Repetitions {
+			return time.Time{}
+		}
+		return r.at(k).In(from.Location())
+	}
+	if r.Repetitions == 0 {
+		return time.Time{}
+	}
+	return r.Start.In(from.Location())
+}
+
+// at returns the kth fire time, counting Start as the 0th.
+func (r *RepeatingInterval) at(k int) time.Time {
+	return r.Start.AddDate(k*r.Years, k*r.Months, k*r.Days).Add(time.Duration(k) * r.Duration)
+}
+
+// String returns the interval in ISO 8601 form, e.g.
+// "R5/2025-01-01T09:00:00Z/P1W".
+func (r *RepeatingInterval) String() string {
+	var b strings.Builder
+	b.WriteString("R")
+	if r.Repetitions >= 0 {
+		b.WriteString(strconv.Itoa(r.Repetitions))
+	}
+	b.WriteString("/" + r.Start.Format(time.RFC3339Nano) + "/P")
+	for _, part := range []struct {
+		n    int
+		unit string
+	}{{r.Years, "Y"}, {r.Months, "M"}, {r.Days, "D"}} {
+		if part.n != 0 {
+			b.WriteString(strconv.Itoa(part.n) + part.unit)
+		}
+	}
+	if d := r.Duration; d != 0 {
+		b.WriteString("T")
+		if h := d / time.Hour; h != 0 {
+			b.WriteString(strconv.Itoa(int(h)) + "H")
+			d -= h * time.Hour
+		}
+		if m := d / time.Minute; m != 0 {
+			b.WriteString(strconv.Itoa(int(m)) + "M")
+			d -= m * time.Minute
+		}
+		if d != 0 {
+			b.WriteString(strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "S")
+		}
+	}
+	return b.String()
+}
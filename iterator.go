@@ -0,0 +1,80 @@
+package cronjob
+
+import "time"
+
+// Iterator yields the fire times of a schedule one at a time, computing
+// each only when asked, for calendar previews and simulations without a
+// scheduler:
+//
+//	it := expr.Iterator(time.Now())
+//	for next := it.Next(); !next.IsZero(); next = it.Next() {
+//		...
+//	}
+//
+// An Iterator is not safe for concurrent use.
+type Iterator struct {
+	next func(time.Time) time.Time
+	last time.Time
+	done bool
+}
+
+// Iterator returns an iterator over the expression's fire times after
+// from, evaluated in from's location as Next does.
+func (expr *CronExpression) Iterator(from time.Time) *Iterator {
+	return newIterator(expr.Next, from)
+}
+
+// NewIterator returns an iterator over the fire times of schedule after
+// from.
+func NewIterator(schedule Schedule, from time.Time) *Iterator {
+	return newIterator(schedule.Next, from)
+}
+
+func newIterator(next func(time.Time) time.Time, from time.Time) *Iterator {
+	return &Iterator{next: next, last: from}
+}
+
+// Next returns the next fire time, or the zero time once the schedule
+// stops firing, which it then always returns. A schedule returning a time
+// that is not after the previous one is taken to have stopped, rather than
+// yielding the same time forever.
+func (it *Iterator) Next() time.Time {
+	if it.done {
+		return time.Time{}
+	}
+	next := it.next(it.last)
+	if next.IsZero() || !next.After(it.last) {
+		it.done = true
+		return time.Time{}
+	}
+	it.last = next
+	return next
+}
+
+// Occurrences returns the expression's fire times from from to to,
+// inclusive, in from's location, at most limit of them if limit is
+// positive, for uses such as counting the runs in a billing period or
+// filling a calendar. "@every" expressions fire every interval after from,
+// and "@reboot" expressions never.
+func (expr *CronExpression) Occurrences(from, to time.Time, limit int) []time.Time {
+	start := from
+	if expr.interval == 0 {
+		// Step back so a fire time equal to from is included.
+		start = from.Add(-time.Nanosecond)
+	}
+	var times []time.Time
+	it := expr.Iterator(start)
+	for next := it.Next(); !next.IsZero() && !next.After(to); next = it.Next() {
+		times = append(times, next)
+		if len(times) == limit {
+			break
+		}
+	}
+	return times
+}
+
+// runs returns an iterator over the job's fire times after from, as
+// nextAfter gives them.
+func (j *Job) runs(from time.Time) *Iterator {
+	return newIterator(j.nextAfter, from)
+}
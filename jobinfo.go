@@ -0,0 +1,218 @@
+package cronjob
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"time"
+)
+
+// JobInfo is a snapshot of a job's schedule and run state.
+type JobInfo struct {
+	ID string
+	// Expression is the cron expression the job was added with.
+	Expression string
+	// NextRun is the job's next fire time, or zero if it will not fire again
+	// or is paused. It is computed when the job is queued, not on every
+	// snapshot; before the scheduler starts, it is when the job would fire
+	// if started now.
+	NextRun time.Time
+	// LastRun is the start time of the most recently finished run, or zero
+	// if the job has not run yet.
+	LastRun time.Time
+	// LastError is the error of the most recently finished run, or nil if
+	// it succeeded. Panics are reported as *PanicError.
+	LastError    error
+	LastDuration time.Duration
+	RunCount     int
+	// Paused reports whether the job is paused with PauseJob.
+	Paused bool
+	// Group is the name of the job's group, or empty if it has none.
+	Group string
+	// Priority is the job's WithPriority.
+	Priority int
+	// QueueDepth is the number of the job's runs queued by the QueueOne or
+	// QueueAll policy behind the ones in progress.
+	QueueDepth int
+	// Metadata is a copy of the metadata attached with WithMetadata.
+	Metadata map[string]string
+}
+
+// ListJobInfo returns a snapshot of every job in the scheduler, in the order
+// the jobs were added.
+func (c *CronScheduler) ListJobInfo() []JobInfo {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := time.Now()
+	infos := make([]JobInfo, 0, len(c.jobs))
+	for _, job := range c.jobs {
+		infos = append(infos, c.jobInfo(job, now))
+	}
+	return infos
+}
+
+// Jobs returns a snapshot of every job in the scheduler, in the order the
+// jobs were added. The snapshots are copies taken under the scheduler's
+// lock, so they can be read and changed freely while jobs run.
+func (c *CronScheduler) Jobs() []*JobInfo {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := time.Now()
+	infos := make([]*JobInfo, 0, len(c.jobs))
+	for _, job := range c.jobs {
+		info := c.jobInfo(job, now)
+		infos = append(infos, &info)
+	}
+	return infos
+}
+
+// JobInfo returns a snapshot of the job with the given ID.
+func (c *CronScheduler) JobInfo(id string) (JobInfo, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	job, ok := c.byID[id]
+	if !ok {
+		return JobInfo{}, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	return c.jobInfo(job, time.Now()), nil
+}
+
+// LastRun returns the start time and outcome of the most recently finished
+// run of the job with the given ID, so health checks can assert that a
+// critical job is firing. The time is zero if the job has not run yet or
+// does not exist, and the outcome is then meaningless.
+func (c *CronScheduler) LastRun(id string) (time.Time, RunOutcome) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	job, ok := c.byID[id]
+	if !ok || job.lastRun.IsZero() {
+		return time.Time{}, OutcomeSuccess
+	}
+	return job.lastRun, outcomeOf(job.lastError)
+}
+
+// NextRun returns the next fire time of the job with the given ID, as
+// reported by JobInfo. It is zero if the job does not exist, is paused or
+// will not fire again.
+func (c *CronScheduler) NextRun(id string) time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	job, ok := c.byID[id]
+	if !ok {
+		return time.Time{}
+	}
+	return c.jobInfo(job, time.Now()).NextRun
+}
+
+// jobInfo returns the snapshot of job. The caller must hold c.mutex.
+func (c *CronScheduler) jobInfo(job *Job, now time.Time) JobInfo {
+	info := JobInfo{
+		ID:           job.ID,
+		Expression:   job.expr,
+		LastRun:      job.lastRun,
+		LastError:    job.lastError,
+		LastDuration: job.lastDuration,
+		RunCount:     job.runCount,
+		Paused:       job.paused,
+		Group:        job.group,
+		Priority:     job.priority,
+		QueueDepth:   len(job.queued),
+		Metadata:     maps.Clone(job.metadata),
+	}
+	switch {
+	case job.paused:
+	case job.index >= 0:
+		info.NextRun = job.next
+	case !c.running:
+		// Not started yet: report when the job would fire if started now.
+		info.NextRun = job.pendingNext(now)
+	}
+	return info
+}
+
+// pendingNext returns the first fire time after now of a job that is not
+// queued, caching it in job.next until it passes, so snapshots of a stopped
+// scheduler don't recompute every job's schedule.
+func (j *Job) pendingNext(now time.Time) time.Time {
+	if !j.next.After(now) {
+		j.next = j.nextAfter(now)
+	}
+	return j.next
+}
+
+// NextRuns returns up to n upcoming fire times of the job, in the job's
+// location. Fewer are returned if the job stops firing, and none if n is
+// not positive.
+func (c *CronScheduler) NextRuns(id string, n int) ([]time.Time, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	job, ok := c.byID[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+
+	var next time.Time
+	if job.index >= 0 {
+		next = job.next.In(job.location)
+	} else {
+		next = job.pendingNext(time.Now())
+	}
+	if left := job.remainingRuns(); left >= 0 && left < n {
+		n = left
+	}
+	var runs []time.Time
+	for it := job.runs(next); len(runs) < n && !next.IsZero(); next = it.Next() {
+		runs = append(runs, next)
+	}
+	return runs, nil
+}
+
+// maxSimulatedRuns caps how many runs Simulate reports per job.
+const maxSimulatedRuns = 100000
+
+// SimulatedRun is a run Simulate predicts.
+type SimulatedRun struct {
+	JobID string
+	// Time is the fire time, in the job's location.
+	Time time.Time
+}
+
+// Simulate returns the runs every job would have in the window from from to
+// to, inclusive, without executing anything, ordered by time and then job
+// ID. It lets complex schedules be checked offline, e.g. across DST changes
+// or month ends. @every jobs are simulated as if started at from, @reboot
+// jobs never appear, and at most 100000 runs are reported per job.
+func (c *CronScheduler) Simulate(from, to time.Time) []SimulatedRun {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	var runs []SimulatedRun
+	for _, job := range c.jobs {
+		// Step back so a fire time equal to from is included.
+		start := from
+		if job.every() == 0 {
+			start = from.Add(-time.Second)
+		}
+		limit := maxSimulatedRuns
+		if left := job.remainingRuns(); left >= 0 && left < limit {
+			limit = left
+		}
+		count := 0
+		it := job.runs(start)
+		for next := it.Next(); !next.IsZero() && !next.After(to) && count < limit; next = it.Next() {
+			if !next.Before(from) {
+				runs = append(runs, SimulatedRun{JobID: job.ID, Time: next})
+				count++
+			}
+		}
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		if !runs[i].Time.Equal(runs[j].Time) {
+			return runs[i].Time.Before(runs[j].Time)
+		}
+		return runs[i].JobID < runs[j].JobID
+	})
+	return runs
+}
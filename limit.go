@@ -0,0 +1,82 @@
+package cronjob
+
+import (
+	"context"
+	"time"
+)
+
+// LimitPolicy controls what happens to a due run when the scheduler already
+// runs as many tasks as WithMaxConcurrentJobs allows, or its group as many
+// as Group.SetMaxConcurrent allows.
+type LimitPolicy int
+
+const (
+	// QueueWhenLimited makes the run wait for a free slot, in the order of
+	// the jobs' WithPriority and then of when the runs became due. This is
+	// the default.
+	QueueWhenLimited LimitPolicy = iota
+	// SkipWhenLimited drops the run.
+	SkipWhenLimited
+)
+
+// WithMaxConcurrentJobs limits the number of tasks running at the same time
+// across all jobs to n, so jobs sharing a schedule do not all start at once.
+// Runs over the limit are handled by the scheduler's LimitPolicy.
+func WithMaxConcurrentJobs(n int) SchedulerOption {
+	return func(c *CronScheduler) {
+		c.maxConcurrent = n
+	}
+}
+
+// WithLimitPolicy sets what happens to runs over the WithMaxConcurrentJobs
+// limit or a group's limit.
+func WithLimitPolicy(policy LimitPolicy) SchedulerOption {
+	return func(c *CronScheduler) {
+		c.limitPolicy = policy
+	}
+}
+
+// runLimited runs job once a slot is free in its group, if the group limits
+// how many of its tasks run at once, and then in the scheduler, if it limits
+// how many tasks run at once. Runs dropped by SkipWhenLimited, or abandoned
+// because the scheduler stopped or the job was removed while waiting, return
+// ErrJobSkipped.
+func (c *CronScheduler) runLimited(schedulerCtx context.Context, job *Job, tick time.Time) error {
+	var groupSlots *limiter
+	c.mutex.Lock()
+	if g := c.groups[job.group]; g != nil && job.group != "" {
+		groupSlots = g.slots
+	}
+	c.mutex.Unlock()
+
+	for _, slots := range []*limiter{groupSlots, c.slots} {
+		if slots == nil {
+			continue
+		}
+		if !c.acquire(schedulerCtx, job, slots) {
+			c.mutex.Lock()
+			c.emit(EventSkipped, job, nil)
+			c.mutex.Unlock()
+			return ErrJobSkipped
+		}
+		defer slots.release()
+	}
+	return c.runLocked(schedulerCtx, job, tick)
+}
+
+// acquire takes a slot of slots for a run of job, waiting for one under
+// QueueWhenLimited, and reports whether it got one.
+func (c *CronScheduler) acquire(schedulerCtx context.Context, job *Job, slots *limiter) bool {
+	if c.limitPolicy != QueueWhenLimited {
+		return slots.tryAcquire()
+	}
+	w := slots.wait(job.priority)
+	select {
+	case <-w.ready:
+		return true
+	case <-schedulerCtx.Done():
+	case <-job.ctx.Done():
+	}
+	slots.cancel(w)
+	return false
+}
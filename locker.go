@@ -0,0 +1,259 @@
+package cronjob
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultLockExpiry is how long RedisLocker and SQLLocker hold a lock when
+// no expiry is set.
+const DefaultLockExpiry = time.Minute
+
+// ErrJobLocked is returned for a manually triggered run that did not happen
+// because another instance holds the job's lock.
+var ErrJobLocked = errors.New("job locked by another instance")
+
+// Locker coordinates job runs between schedulers running the same jobs on
+// several hosts, so only the instance holding a run's lock runs it.
+// Implementations must be safe for concurrent use.
+type Locker interface {
+	// Lock tries to acquire the lock for the run of jobID scheduled at
+	// tick, or for a manual run of it if tick is zero, and reports whether
+	// it did. Locks for different ticks of a job are independent. For an
+	// "@every" job, tick is the start of the interval since the Unix epoch
+	// the run falls in, since instances queue the job at different times.
+	Lock(ctx context.Context, jobID string, tick time.Time) (bool, error)
+	// Unlock releases a lock acquired by Lock.
+	Unlock(ctx context.Context, jobID string, tick time.Time) error
+}
+
+// WithLocker makes the scheduler acquire a lock from locker before every
+// run and skip the run if another instance holds it. Scheduled runs are
+// locked by job and scheduled time, or for "@every" jobs by the interval
+// since the Unix epoch they fall in, and their locks are not released but
+// left to expire, so an instance that fires late, because of clock skew or
+// WithJitter, still finds the occurrence taken; the locker's expiry should
+// exceed the largest such lateness. Manual runs are locked by job alone
+// and release the lock when they finish.
+func WithLocker(locker Locker) SchedulerOption {
+	return func(c *CronScheduler) {
+		c.locker = locker
+	}
+}
+
+// runLocked runs job while holding its lock, if the scheduler has a Locker.
+func (c *CronScheduler) runLocked(schedulerCtx context.Context, job *Job, tick time.Time) error {
+	if c.locker == nil {
+		return c.runJob(schedulerCtx, job, tick)
+	}
+
+	c.mutex.Lock()
+	onError := c.onError
+	lockTick := job.lockTick(tick)
+	c.mutex.Unlock()
+
+	ok, err := c.locker.Lock(schedulerCtx, job.ID, lockTick)
+	if err != nil {
+		err = fmt.Errorf("locking job %s: %w", job.ID, err)
+		if onError != nil {
+			onError(job.ID, err)
+		}
+		return err
+	}
+	if !ok {
+		c.mutex.Lock()
+		c.emit(EventSkipped, job, nil)
+		c.mutex.Unlock()
+		return ErrJobLocked
+	}
+
+	runErr := c.runJob(schedulerCtx, job, tick)
+	if !tick.IsZero() {
+		return runErr
+	}
+	if err := c.locker.Unlock(context.WithoutCancel(schedulerCtx), job.ID, lockTick); err != nil && onError != nil {
+		onError(job.ID, fmt.Errorf("unlocking job %s: %w", job.ID, err))
+	}
+	return runErr
+}
+
+// lockTick returns the time the run of job scheduled at tick is locked by:
+// the tick itself or, for "@every" jobs, whose ticks count from when each
+// instance queued the job, the start of the interval since the Unix epoch
+// the tick falls in, so every instance locks the same occurrences. The
+// caller must hold c.mutex.
+func (j *Job) lockTick(tick time.Time) time.Time {
+	interval := j.every()
+	if interval <= 0 || tick.IsZero() {
+		return tick
+	}
+	epoch := time.Unix(0, 0)
+	elapsed := tick.Sub(epoch)
+	return epoch.Add(elapsed - elapsed%interval)
+}
+
+// lockName returns the name of the lock for the run of jobID at tick.
+func lockName(jobID string, tick time.Time) string {
+	if tick.IsZero() {
+		return jobID
+	}
+	return jobID + "@" + tick.UTC().Format(time.RFC3339Nano)
+}
+
+// newLockToken returns a random value identifying one locker instance.
+func newLockToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RedisClient is the part of a Redis client RedisLocker needs: it sends one
+// command and returns the reply. A go-redis client can be adapted with
+//
+//	cronjob.RedisClientFunc(func(ctx context.Context, args ...any) (any, error) {
+//		return client.Do(ctx, args...).Result()
+//	})
+type RedisClient interface {
+	Do(ctx context.Context, args ...any) (any, error)
+}
+
+// RedisClientFunc adapts a function to the RedisClient interface.
+type RedisClientFunc func(ctx context.Context, args ...any) (any, error)
+
+// Do calls f(ctx, args...).
+func (f RedisClientFunc) Do(ctx context.Context, args ...any) (any, error) {
+	return f(ctx, args...)
+}
+
+// The scripts reply with an integer, so a missing key never surfaces as a
+// client-specific nil error.
+const (
+	redisLockScript   = `if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then return 1 else return 0 end`
+	redisUnlockScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) else return 0 end`
+)
+
+// RedisLocker is a Locker backed by Redis keys set with SET NX, named by
+// the prefix, the job ID and, for scheduled runs, "@" and the run's
+// scheduled time in RFC 3339. Each lock expires after Expiry, and is only
+// released by the instance holding it.
+type RedisLocker struct {
+	client RedisClient
+	prefix string
+	token  string
+
+	// Expiry is how long a lock is held if it is not released. It
+	// defaults to DefaultLockExpiry.
+	Expiry time.Duration
+}
+
+// NewRedisLocker returns a RedisLocker that stores locks in client under
+// keys starting with prefix.
+func NewRedisLocker(client RedisClient, prefix string) *RedisLocker {
+	return &RedisLocker{client: client, prefix: prefix, token: newLockToken()}
+}
+
+// Lock implements Locker.
+func (l *RedisLocker) Lock(ctx context.Context, jobID string, tick time.Time) (bool, error) {
+	expiry := l.Expiry
+	if expiry <= 0 {
+		expiry = DefaultLockExpiry
+	}
+	reply, err := l.client.Do(ctx, "EVAL", redisLockScript, 1, l.prefix+lockName(jobID, tick), l.token, expiry.Milliseconds())
+	if err != nil {
+		return false, err
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return false, fmt.Errorf("unexpected redis reply %v", reply)
+	}
+	return n == 1, nil
+}
+
+// Unlock implements Locker.
+func (l *RedisLocker) Unlock(ctx context.Context, jobID string, tick time.Time) error {
+	_, err := l.client.Do(ctx, "EVAL", redisUnlockScript, 1, l.prefix+lockName(jobID, tick), l.token)
+	return err
+}
+
+// SQLLocker is a Locker backed by a database table, created by the caller,
+// with a unique name column, holding the lock names RedisLocker uses
+// without the prefix:
+//
+//	CREATE TABLE cronjob_locks (
+//		name       VARCHAR(255) PRIMARY KEY,
+//		owner      VARCHAR(64) NOT NULL,
+//		expires_at TIMESTAMP NOT NULL
+//	)
+type SQLLocker struct {
+	db    *sql.DB
+	table string
+	token string
+
+	// Expiry is how long a lock is held if it is not released. It
+	// defaults to DefaultLockExpiry.
+	Expiry time.Duration
+	// Numbered makes queries use $1-style placeholders, as PostgreSQL
+	// requires, instead of ?.
+	Numbered bool
+}
+
+// NewSQLLocker returns a SQLLocker that stores locks in table.
+func NewSQLLocker(db *sql.DB, table string) *SQLLocker {
+	return &SQLLocker{db: db, table: table, token: newLockToken()}
+}
+
+// query substitutes the placeholders in query to match the driver.
+func (l *SQLLocker) query(query string) string {
+	if !l.Numbered {
+		return query
+	}
+	var b []byte
+	n := 0
+	for i := 0; i < len(query); i++ {
+		if query[i] == '?' {
+			n++
+			b = fmt.Appendf(b, "$%d", n)
+			continue
+		}
+		b = append(b, query[i])
+	}
+	return string(b)
+}
+
+// Lock implements Locker. Expired locks, of any job, are deleted first;
+// then the unique name column makes the insert fail while another instance
+// holds the lock.
+func (l *SQLLocker) Lock(ctx context.Context, jobID string, tick time.Time) (bool, error) {
+	expiry := l.Expiry
+	if expiry <= 0 {
+		expiry = DefaultLockExpiry
+	}
+	name := lockName(jobID, tick)
+	now := time.Now().UTC()
+	_, err := l.db.ExecContext(ctx, l.query("DELETE FROM "+l.table+" WHERE expires_at < ?"), now)
+	if err != nil {
+		return false, err
+	}
+	_, err = l.db.ExecContext(ctx, l.query("INSERT INTO "+l.table+" (name, owner, expires_at) VALUES (?, ?, ?)"), name, l.token, now.Add(expiry))
+	if err == nil {
+		return true, nil
+	}
+
+	// Tell a held lock apart from a failing database.
+	var owner string
+	if qerr := l.db.QueryRowContext(ctx, l.query("SELECT owner FROM "+l.table+" WHERE name = ?"), name).Scan(&owner); qerr == nil {
+		return false, nil
+	}
+	return false, err
+}
+
+// Unlock implements Locker.
+func (l *SQLLocker) Unlock(ctx context.Context, jobID string, tick time.Time) error {
+	_, err := l.db.ExecContext(ctx, l.query("DELETE FROM "+l.table+" WHERE name = ? AND owner = ?"), lockName(jobID, tick), l.token)
+	return err
+}
@@ -0,0 +1,68 @@
+package cronjob
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// WithLogger makes the scheduler write a structured record to logger for
+// every finished run, with the job's ID, the run's scheduled time (for
+// scheduled runs), start, duration, outcome and error, and send task panics
+// to it instead of slog.Default(). Successful runs are logged at Info and
+// failed, timed out or panicking ones at Error, unless changed with
+// WithLogLevels.
+func WithLogger(logger *slog.Logger) SchedulerOption {
+	return func(c *CronScheduler) {
+		c.logger = logger
+	}
+}
+
+// WithLogLevels sets the levels of the run records written to the
+// WithLogger logger: success for runs that succeed, and failure for runs
+// that fail, time out or panic.
+func WithLogLevels(success, failure slog.Level) SchedulerOption {
+	return func(c *CronScheduler) {
+		c.successLevel = success
+		c.failureLevel = failure
+	}
+}
+
+// logRun writes the record of a finished run of job to the scheduler's
+// logger, if it has one.
+func (c *CronScheduler) logRun(job *Job, tick, start time.Time, duration time.Duration, err error) {
+	level := c.successLevel
+	if err != nil {
+		level = c.failureLevel
+	}
+	ctx := context.Background()
+	if c.logger == nil || !c.logger.Enabled(ctx, level) {
+		return
+	}
+	attrs := make([]slog.Attr, 0, 6)
+	attrs = append(attrs, slog.String("job", job.ID))
+	if !tick.IsZero() {
+		attrs = append(attrs, slog.Time("scheduled_time", tick))
+	}
+	attrs = append(attrs,
+		slog.Time("start", start),
+		slog.Duration("duration", duration),
+		slog.String("outcome", outcomeOf(err).String()))
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	c.logger.LogAttrs(ctx, level, "job run", attrs...)
+}
+
+// logPanic writes a recovered task panic, with its stack trace, to the
+// scheduler's logger or, without one, to slog.Default().
+func (c *CronScheduler) logPanic(jobID string, recovered any, stack []byte) {
+	logger := c.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Error("task panicked",
+		slog.String("job", jobID),
+		slog.Any("panic", recovered),
+		slog.String("stack", string(stack)))
+}
@@ -0,0 +1,76 @@
+package cronjob
+
+import (
+	"context"
+	"time"
+)
+
+// RunInfo describes the attempt of a job's task that a JobHandler executes.
+type RunInfo struct {
+	JobID string
+	// Expression is the cron expression the job was added with.
+	Expression string
+	// Scheduled is the fire time the run is for, or zero for a manual run
+	// or one queued by the QueueOne or QueueAll policy.
+	Scheduled time.Time
+	// Attempt is the attempt number within the run, starting at 1.
+	Attempt int
+	Group   string
+	// Metadata is the metadata attached with WithMetadata.
+	Metadata map[string]string
+}
+
+// JobHandler executes one attempt of a job's task.
+type JobHandler func(ctx context.Context, run RunInfo) error
+
+// JobMiddleware wraps a JobHandler with cross-cutting behaviour, such as
+// logging, tracing, metrics or recovery, like HTTP middleware:
+//
+//	func logRuns(next cronjob.JobHandler) cronjob.JobHandler {
+//		return func(ctx context.Context, run cronjob.RunInfo) error {
+//			err := next(ctx, run)
+//			log.Printf("job %s: %v", run.JobID, err)
+//			return err
+//		}
+//	}
+//
+// Middleware runs once per attempt, inside the job's timeout and the
+// scheduler's panic recovery, so the context carries the attempt's deadline
+// and a panic that middleware does not recover is still reported as a
+// *PanicError.
+type JobMiddleware func(next JobHandler) JobHandler
+
+// Use adds middleware wrapping the runs of every job, including jobs added
+// before the call. The first middleware is the outermost, and scheduler
+// middleware wraps the middleware set per job with WithMiddleware.
+func (c *CronScheduler) Use(middleware ...JobMiddleware) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.middleware = append(c.middleware, middleware...)
+}
+
+// WithMiddleware adds middleware wrapping the job's runs, inside any
+// scheduler middleware added with Use. The first middleware is the
+// outermost.
+func WithMiddleware(middleware ...JobMiddleware) JobOption {
+	return func(j *Job) {
+		j.middleware = append(j.middleware, middleware...)
+	}
+}
+
+// handler returns job's task wrapped in the scheduler's and the job's
+// middleware. The caller must hold c.mutex.
+func (c *CronScheduler) handler(job *Job) JobHandler {
+	run := job.run
+	h := JobHandler(func(ctx context.Context, _ RunInfo) error { return run(ctx) })
+	if len(c.middleware) == 0 && len(job.middleware) == 0 {
+		return h
+	}
+	for i := len(job.middleware) - 1; i >= 0; i-- {
+		h = job.middleware[i](h)
+	}
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		h = c.middleware[i](h)
+	}
+	return h
+}
@@ -0,0 +1,118 @@
+// Package otelcronjob traces cronjob runs with OpenTelemetry, so they show
+// up in distributed traces:
+//
+//	scheduler.Use(otelcronjob.Middleware())
+//
+// Every attempt of a job's task gets a span, started as a new root since
+// runs are not caused by a request, and the task's context carries it, so
+// spans the task starts are its children.
+//
+// It lives in its own module so the cronjob package does not depend on
+// OpenTelemetry.
+package otelcronjob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	cronjob "github.com/flyzard/go-cronjob"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope name of the tracer.
+const ScopeName = "github.com/flyzard/go-cronjob/otelcronjob"
+
+// Attribute keys set on run spans.
+const (
+	JobIDKey         = attribute.Key("cronjob.job.id")
+	ExpressionKey    = attribute.Key("cronjob.job.expression")
+	GroupKey         = attribute.Key("cronjob.job.group")
+	ScheduledTimeKey = attribute.Key("cronjob.run.scheduled_time")
+	AttemptKey       = attribute.Key("cronjob.run.attempt")
+	OutcomeKey       = attribute.Key("cronjob.run.outcome")
+)
+
+// Option configures Middleware.
+type Option func(*config)
+
+type config struct {
+	provider trace.TracerProvider
+}
+
+// WithTracerProvider makes Middleware create spans with provider instead of
+// the global one.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.provider = provider
+	}
+}
+
+// Middleware returns a cronjob.JobMiddleware starting a span named
+// "cronjob <job ID>" for every attempt of a job's task. The span carries the
+// job's ID, expression and group, the run's scheduled time, if it was
+// scheduled, its attempt number and its outcome: "success", "failure",
+// "timeout" or "panic". Failed attempts record their error and set the
+// span's status to Error; panics are recorded and then re-raised, for the
+// scheduler to recover.
+func Middleware(opts ...Option) cronjob.JobMiddleware {
+	cfg := config{provider: otel.GetTracerProvider()}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	tracer := cfg.provider.Tracer(ScopeName)
+
+	return func(next cronjob.JobHandler) cronjob.JobHandler {
+		return func(ctx context.Context, run cronjob.RunInfo) (err error) {
+			attrs := []attribute.KeyValue{
+				JobIDKey.String(run.JobID),
+				ExpressionKey.String(run.Expression),
+				AttemptKey.Int(run.Attempt),
+			}
+			if run.Group != "" {
+				attrs = append(attrs, GroupKey.String(run.Group))
+			}
+			if !run.Scheduled.IsZero() {
+				attrs = append(attrs, ScheduledTimeKey.String(run.Scheduled.Format(time.RFC3339Nano)))
+			}
+			ctx, span := tracer.Start(ctx, "cronjob "+run.JobID,
+				trace.WithNewRoot(),
+				trace.WithSpanKind(trace.SpanKindInternal),
+				trace.WithAttributes(attrs...))
+			defer span.End()
+
+			defer func() {
+				if r := recover(); r != nil {
+					span.SetAttributes(OutcomeKey.String("panic"))
+					span.RecordError(fmt.Errorf("panic: %v", r))
+					span.SetStatus(codes.Error, "task panicked")
+					panic(r)
+				}
+			}()
+
+			err = next(ctx, run)
+			span.SetAttributes(OutcomeKey.String(outcome(ctx, err)))
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return err
+		}
+	}
+}
+
+// outcome classifies an attempt's error like the scheduler's run history.
+func outcome(ctx context.Context, err error) string {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded), errors.Is(err, cronjob.ErrJobTimeout):
+		return "timeout"
+	case err != nil:
+		return "failure"
+	default:
+		return "success"
+	}
+}
@@ -0,0 +1,79 @@
+package otelcronjob
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	cronjob "github.com/flyzard/go-cronjob"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	scheduler := cronjob.NewCronScheduler()
+	scheduler.Use(Middleware(WithTracerProvider(provider)))
+
+	var taskSpan trace.SpanContext
+	okJob, _ := scheduler.AddJobContext("0 6 * * *", func(ctx context.Context) {
+		taskSpan = trace.SpanContextFromContext(ctx)
+	}, cronjob.WithGroup("reports"))
+	okID := okJob.ID
+	errBoom := errors.New("boom")
+	failJob, _ := scheduler.AddJobWithError("@daily", func() error { return errBoom })
+	failID := failJob.ID
+	panicJob, _ := scheduler.AddJob("@daily", func() { panic("oops") })
+	panicID := panicJob.ID
+	scheduler.SetPanicHandler(func(string, any, []byte) {})
+
+	_ = scheduler.RunNowAndWait(context.Background(), okID)
+	_ = scheduler.RunNowAndWait(context.Background(), failID)
+	_ = scheduler.RunNowAndWait(context.Background(), panicID)
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(spans))
+	}
+	tests := []struct {
+		name    string
+		outcome string
+		status  codes.Code
+	}{
+		{"cronjob " + okID, "success", codes.Unset},
+		{"cronjob " + failID, "failure", codes.Error},
+		{"cronjob " + panicID, "panic", codes.Error},
+	}
+	for i, tc := range tests {
+		span := spans[i]
+		if span.Name() != tc.name {
+			t.Errorf("Span %d: expected name %q, got %q", i, tc.name, span.Name())
+		}
+		attrs := attribute.NewSet(span.Attributes()...)
+		if v, _ := attrs.Value(OutcomeKey); v.AsString() != tc.outcome {
+			t.Errorf("Span %s: expected outcome %q, got %q", tc.name, tc.outcome, v.AsString())
+		}
+		if span.Status().Code != tc.status {
+			t.Errorf("Span %s: expected status %v, got %v", tc.name, tc.status, span.Status().Code)
+		}
+	}
+
+	attrs := attribute.NewSet(spans[0].Attributes()...)
+	if v, _ := attrs.Value(ExpressionKey); v.AsString() != "0 6 * * *" {
+		t.Errorf("Expected the expression attribute, got %q", v.AsString())
+	}
+	if v, _ := attrs.Value(GroupKey); v.AsString() != "reports" {
+		t.Errorf("Expected the group attribute, got %q", v.AsString())
+	}
+	if v, _ := attrs.Value(AttemptKey); v.AsInt64() != 1 {
+		t.Errorf("Expected attempt 1, got %d", v.AsInt64())
+	}
+	if taskSpan.SpanID() != spans[0].SpanContext().SpanID() {
+		t.Errorf("Expected the task's context to carry the run's span")
+	}
+}
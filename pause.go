@@ -0,0 +1,50 @@
+package cronjob
+
+// Pause suspends scheduled runs of every job without stopping the
+// scheduler, for deployments or maintenance windows. Jobs stay queued and
+// their fire times keep advancing, but runs coming due are held instead of
+// started; runs already in progress are not affected, and RunNow still
+// starts runs. Pausing a scheduler that is not running keeps it paused once
+// started, and jobs paused with PauseJob stay paused after Resume.
+func (c *CronScheduler) Pause() {
+	c.mutex.Lock()
+	c.paused = true
+	c.mutex.Unlock()
+}
+
+// Resume ends a Pause. The runs held while the scheduler was paused are
+// made up according to each job's CatchUpPolicy: with the default
+// IgnoreMissed they are dropped, and each job simply fires at its next fire
+// time.
+func (c *CronScheduler) Resume() {
+	c.mutex.Lock()
+	if !c.paused {
+		c.mutex.Unlock()
+		return
+	}
+	c.paused = false
+	held := make(map[*Job]int)
+	for _, job := range c.jobs {
+		n := job.held
+		job.held = 0
+		switch {
+		case n == 0 || job.catchUp == IgnoreMissed:
+			continue
+		case job.catchUp == RunOnceOnStartupIfMissed:
+			n = 1
+		}
+		held[job] = min(n, maxCatchUpRuns)
+	}
+	c.mutex.Unlock()
+
+	for job, n := range held {
+		go c.catchUp(job, n)
+	}
+}
+
+// Paused reports whether the scheduler is paused with Pause.
+func (c *CronScheduler) Paused() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.paused
+}
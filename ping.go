@@ -0,0 +1,127 @@
+package cronjob
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// PingService selects the URL layout a Pinger uses for its pings.
+type PingService int
+
+const (
+	// Healthchecks pings Healthchecks.io style URLs: the URL itself on
+	// success, with "/start" appended when a run starts and "/fail" when it
+	// fails, the error being sent as the body.
+	Healthchecks PingService = iota
+	// Cronitor pings Cronitor telemetry URLs, with a "state" query
+	// parameter of "run", "complete" or "fail", and the error as the
+	// "message" parameter.
+	Cronitor
+)
+
+// pingEventBuffer is how many events a Pinger holds while its pings are
+// being sent.
+const pingEventBuffer = 256
+
+// Pinger is a dead man's switch notifier: it pings an external cron
+// monitoring service when each run of a job starts, succeeds or fails, so
+// the service alerts when a job stops running on time.
+//
+//	pinger := &cronjob.Pinger{URL: "https://hc-ping.com/<ping-key>/{job}"}
+//	stop := pinger.Watch(scheduler)
+//	defer stop()
+type Pinger struct {
+	// URL is the job's ping URL, with "{job}" replaced by its slug.
+	URL     string
+	Service PingService
+	// Slug returns the slug of a job, or "" for jobs that are not
+	// monitored. If it is nil, every job is monitored under its ID.
+	Slug func(jobID string) string
+	// Client sends the pings. If it is nil, a client with a 10 second
+	// timeout is used.
+	Client *http.Client
+	// OnError, if set, is called when a ping fails.
+	OnError func(jobID string, err error)
+}
+
+// Watch subscribes the pinger to the scheduler's events and starts pinging
+// for them. Pings are sent in the background, so a slow service never
+// delays a run. The returned function stops it.
+func (p *Pinger) Watch(c *CronScheduler) (stop func()) {
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return c.watchEvents(pingEventBuffer, func(event JobEvent) { p.ping(client, event) })
+}
+
+// ping sends the ping for event, if it is one the service is told about.
+func (p *Pinger) ping(client *http.Client, event JobEvent) {
+	var state string
+	switch event.Type {
+	case EventStarted:
+		state = "start"
+	case EventSucceeded:
+		state = "success"
+	case EventFailed, EventPanicked:
+		state = "fail"
+	default:
+		return
+	}
+	slug := event.JobID
+	if p.Slug != nil {
+		slug = p.Slug(event.JobID)
+	}
+	if slug == "" {
+		return
+	}
+	req, err := p.request(slug, state, event.Err)
+	if err == nil {
+		go func() {
+			resp, err := client.Do(req)
+			if err == nil {
+				resp.Body.Close()
+				if resp.StatusCode >= 300 {
+					err = fmt.Errorf("unexpected status %s", resp.Status)
+				}
+			}
+			if err != nil && p.OnError != nil {
+				p.OnError(event.JobID, fmt.Errorf("pinging %s: %w", req.URL.Redacted(), err))
+			}
+		}()
+	} else if p.OnError != nil {
+		p.OnError(event.JobID, fmt.Errorf("building ping: %w", err))
+	}
+}
+
+// request returns the ping request for a run of the job with the given slug
+// in state, which failed with runErr if state is "fail".
+func (p *Pinger) request(slug, state string, runErr error) (*http.Request, error) {
+	u, err := url.Parse(strings.ReplaceAll(p.URL, "{job}", url.PathEscape(slug)))
+	if err != nil {
+		return nil, err
+	}
+	var message string
+	if runErr != nil {
+		message = runErr.Error()
+	}
+	if p.Service == Cronitor {
+		q := u.Query()
+		q.Set("state", map[string]string{"start": "run", "success": "complete", "fail": "fail"}[state])
+		if message != "" {
+			q.Set("message", message)
+		}
+		u.RawQuery = q.Encode()
+		return http.NewRequest(http.MethodGet, u.String(), nil)
+	}
+	switch state {
+	case "start":
+		u = u.JoinPath("start")
+	case "fail":
+		return http.NewRequest(http.MethodPost, u.JoinPath("fail").String(), strings.NewReader(message))
+	}
+	return http.NewRequest(http.MethodGet, u.String(), nil)
+}
@@ -0,0 +1,95 @@
+package cronjob
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// WithWorkers makes the scheduler run tasks on a fixed pool of n goroutines
+// pulling due runs from a queue, instead of starting a goroutine per run.
+// This reduces goroutine churn when many jobs fire in the same second; runs
+// wait in the queue while every worker is busy, and the next free worker
+// takes the waiting run of the highest WithPriority.
+func WithWorkers(n int) SchedulerOption {
+	return func(c *CronScheduler) {
+		c.workers = n
+	}
+}
+
+// workerPool runs submitted functions on a fixed set of goroutines. A nil
+// or stopped pool runs each function on its own goroutine instead.
+type workerPool struct {
+	mu         sync.Mutex
+	cond       *sync.Cond
+	queue      []queuedRun
+	stopped    bool
+	starvation time.Duration
+}
+
+// queuedRun is a function waiting in a workerPool's queue.
+type queuedRun struct {
+	rank
+	run func()
+}
+
+// newWorkerPool starts a pool of n workers, which pick queued functions by
+// nextWaiting with the starvation limit, or returns nil if n is not
+// positive.
+func newWorkerPool(n int, starvation time.Duration) *workerPool {
+	if n <= 0 {
+		return nil
+	}
+	p := &workerPool{starvation: starvation}
+	p.cond = sync.NewCond(&p.mu)
+	for i := 0; i < n; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// submit queues f to run on the next free worker, ahead of functions of
+// lower priority.
+func (p *workerPool) submit(priority int, f func()) {
+	if p == nil {
+		go f()
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		go f()
+		return
+	}
+	p.queue = append(p.queue, queuedRun{rank: rank{priority: priority, since: time.Now()}, run: f})
+	p.cond.Signal()
+}
+
+// stop makes the workers exit once the queue is drained.
+func (p *workerPool) stop() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stopped = true
+	p.cond.Broadcast()
+}
+
+func (p *workerPool) work() {
+	for {
+		p.mu.Lock()
+		for len(p.queue) == 0 && !p.stopped {
+			p.cond.Wait()
+		}
+		if len(p.queue) == 0 {
+			p.mu.Unlock()
+			return
+		}
+		i := nextWaiting(func(i int) rank { return p.queue[i].rank }, len(p.queue), p.starvation, time.Now())
+		f := p.queue[i].run
+		p.queue = slices.Delete(p.queue, i, i+1)
+		p.mu.Unlock()
+		f()
+	}
+}
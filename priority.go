@@ -0,0 +1,130 @@
+package cronjob
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// WithPriority sets the job's priority, zero by default. When runs wait for
+// a free worker of WithWorkers or a free slot of WithMaxConcurrentJobs or
+// Group.SetMaxConcurrent, the waiting run with the highest priority starts
+// first, and runs of equal priority start in the order they became due.
+// Priorities may be negative, to run behind default jobs.
+func WithPriority(priority int) JobOption {
+	return func(j *Job) {
+		j.priority = priority
+	}
+}
+
+// DefaultStarvationLimit is how long a run may wait behind runs of higher
+// priority before it starts ahead of them, unless changed with
+// WithStarvationLimit.
+const DefaultStarvationLimit = time.Minute
+
+// WithStarvationLimit sets how long a run may wait for a worker or a
+// concurrency slot behind runs of higher priority. Once the longest-waiting
+// run has waited that long, it starts next whatever its priority, so busy
+// high-priority jobs cannot starve low-priority ones. Zero or a negative d
+// disables the protection.
+func WithStarvationLimit(d time.Duration) SchedulerOption {
+	return func(c *CronScheduler) {
+		c.starvationLimit = d
+	}
+}
+
+// rank is what orders a run waiting for a worker or a slot: its job's
+// priority and when it started waiting.
+type rank struct {
+	priority int
+	since    time.Time
+}
+
+// nextWaiting returns the index of the run to start next among the ranks of
+// the waiting runs, in the order they started waiting: the first one if it
+// has waited for starvation or longer, and otherwise the first one of the
+// highest priority.
+func nextWaiting(ranks func(i int) rank, n int, starvation time.Duration, now time.Time) int {
+	if starvation > 0 && now.Sub(ranks(0).since) >= starvation {
+		return 0
+	}
+	next := 0
+	for i := 1; i < n; i++ {
+		if ranks(i).priority > ranks(next).priority {
+			next = i
+		}
+	}
+	return next
+}
+
+// limiter limits how many runs hold one of its slots at once. A released
+// slot goes to the waiting run picked by nextWaiting.
+type limiter struct {
+	mu         sync.Mutex
+	free       int
+	waiters    []*slotWaiter
+	starvation time.Duration
+}
+
+// slotWaiter is a run waiting for a slot. ready is closed once it holds one.
+type slotWaiter struct {
+	rank
+	ready chan struct{}
+}
+
+func newLimiter(n int, starvation time.Duration) *limiter {
+	return &limiter{free: n, starvation: starvation}
+}
+
+// tryAcquire takes a slot if one is free and no run is waiting for one.
+func (l *limiter) tryAcquire() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.free == 0 || len(l.waiters) > 0 {
+		return false
+	}
+	l.free--
+	return true
+}
+
+// wait queues a run of the given priority for a slot. The returned waiter's
+// ready channel is closed once the run holds the slot, straight away if one
+// is free.
+func (l *limiter) wait(priority int) *slotWaiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	w := &slotWaiter{rank: rank{priority: priority, since: time.Now()}, ready: make(chan struct{})}
+	if l.free > 0 && len(l.waiters) == 0 {
+		l.free--
+		close(w.ready)
+		return w
+	}
+	l.waiters = append(l.waiters, w)
+	return w
+}
+
+// cancel stops w from waiting, giving back the slot if it got one already.
+func (l *limiter) cancel(w *slotWaiter) {
+	l.mu.Lock()
+	if i := slices.Index(l.waiters, w); i >= 0 {
+		l.waiters = slices.Delete(l.waiters, i, i+1)
+		l.mu.Unlock()
+		return
+	}
+	l.mu.Unlock()
+	l.release()
+}
+
+// release gives back a slot, handing it to the next waiting run if any.
+func (l *limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.waiters) == 0 {
+		l.free++
+		return
+	}
+	i := nextWaiting(func(i int) rank { return l.waiters[i].rank }, len(l.waiters), l.starvation, time.Now())
+	w := l.waiters[i]
+	l.waiters = slices.Delete(l.waiters, i, i+1)
+	close(w.ready)
+}
@@ -0,0 +1,48 @@
+package cronjob
+
+import "container/heap"
+
+// lane is a queue of jobs and the channel that wakes the loop running them.
+// The scheduler runs every job from one shared lane, or, with
+// WithIsolatedScheduling, each job from a lane of its own.
+type lane struct {
+	queue jobQueue
+	// wake interrupts the lane's loop when its queue changes.
+	wake chan struct{}
+}
+
+func newLane() *lane {
+	return &lane{wake: make(chan struct{}, 1)}
+}
+
+// jobQueue is a min-heap of jobs ordered by their next start time, so the
+// scheduler only has to look at the head to know when to wake up.
+type jobQueue []*Job
+
+var _ heap.Interface = (*jobQueue)(nil)
+
+func (q jobQueue) Len() int { return len(q) }
+
+func (q jobQueue) Less(i, j int) bool { return q[i].startAt().Before(q[j].startAt()) }
+
+func (q jobQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *jobQueue) Push(x any) {
+	job := x.(*Job)
+	job.index = len(*q)
+	*q = append(*q, job)
+}
+
+func (q *jobQueue) Pop() any {
+	old := *q
+	n := len(old)
+	job := old[n-1]
+	old[n-1] = nil
+	job.index = -1
+	*q = old[:n-1]
+	return job
+}
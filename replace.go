@@ -0,0 +1,177 @@
+package cronjob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// JobSpec describes a job of the desired set passed to ReplaceJobs.
+type JobSpec struct {
+	// ID identifies the job across calls.
+	ID         string
+	Expression string
+	Task       func(ctx context.Context) error
+	// Options apply when the job is added or replaced. A job whose options
+	// give different settings than before is replaced.
+	Options []JobOption
+}
+
+// ReplaceJobs makes the scheduler's jobs match specs without stopping it,
+// for reconciliation loops driven by configuration: jobs with IDs new to
+// the scheduler are added, jobs whose schedule or options changed are
+// replaced as by UpsertJob, and jobs missing from specs are removed as by
+// RemoveJob. Jobs whose expression is unchanged, or written differently but
+// Equal, and whose options give the same settings keep their run state and
+// statistics, and run the task, middleware and WithOnComplete callback of
+// their spec from then on. The whole change is made in one step, so
+// observers never see part of it. If any spec is invalid,
+// nothing is changed. If the scheduler has a JobStore, added and replaced
+// jobs are saved to it and removed ones deleted.
+func (c *CronScheduler) ReplaceJobs(specs []JobSpec) error {
+	c.mutex.Lock()
+	store := c.store
+	c.mutex.Unlock()
+	saved := make(map[string]JobRecord)
+	if store != nil {
+		records, err := store.Load()
+		if err != nil {
+			return fmt.Errorf("loading jobs: %w", err)
+		}
+		for _, record := range records {
+			saved[record.Name] = record
+		}
+	}
+
+	jobs := make([]*Job, 0, len(specs))
+	wanted := make(map[string]bool)
+	var errs []error
+	for _, spec := range specs {
+		switch {
+		case spec.ID == "":
+			errs = append(errs, errors.New("job ID must not be empty"))
+			continue
+		case wanted[spec.ID]:
+			errs = append(errs, fmt.Errorf("%w: %s", ErrDuplicateJobID, spec.ID))
+			continue
+		case spec.Task == nil:
+			errs = append(errs, fmt.Errorf("job %s: task must not be nil", spec.ID))
+			continue
+		}
+		wanted[spec.ID] = true
+		job, err := c.newJob(spec.Expression, spec.Task, spec.Options)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("job %s: %w", spec.ID, err))
+			continue
+		}
+		c.setID(job, spec.ID)
+		job.restoreRecord(saved[spec.ID])
+		jobs = append(jobs, job)
+	}
+	if len(errs) > 0 {
+		for _, job := range jobs {
+			job.cancel()
+		}
+		return errors.Join(errs...)
+	}
+
+	c.mutex.Lock()
+	var removed, changed []*Job
+	interrupted := make(map[*Job][]time.Time)
+	for i := len(c.jobs) - 1; i >= 0; i-- {
+		if job := c.jobs[i]; !wanted[job.ID] {
+			removed = append(removed, job)
+			c.removeJob(job)
+		}
+	}
+	for _, job := range jobs {
+		old, ok := c.byID[job.ID]
+		if ok && sameSchedule(old, job) && sameSettings(old, job) {
+			// Runs started from now on pick up the new task and callbacks.
+			old.run, old.taskFunc = job.run, nil
+			old.middleware, old.onComplete = job.middleware, job.onComplete
+			job.cancel()
+			continue
+		}
+		if ok {
+			c.removeJob(old)
+			// The saved runs were the replaced job's, not a previous
+			// process's.
+			job.pending, job.interrupted = nil, nil
+		}
+		c.insertJob(job)
+		job.persisted = store != nil
+		if ticks := c.takeInterrupted(job); ticks != nil {
+			interrupted[job] = ticks
+		}
+		changed = append(changed, job)
+	}
+	c.mutex.Unlock()
+	for job, ticks := range interrupted {
+		go c.recoverRuns(job, ticks)
+	}
+
+	if store == nil {
+		return nil
+	}
+	for _, job := range removed {
+		if job.persisted {
+			if err := store.Delete(job.ID); err != nil {
+				errs = append(errs, fmt.Errorf("deleting job %s: %w", job.ID, err))
+			}
+		}
+	}
+	for _, job := range changed {
+		if err := c.saveRecord(job); err != nil {
+			errs = append(errs, fmt.Errorf("saving job %s: %w", job.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sameSchedule reports whether job has the same schedule as the job
+// replacing it.
+func sameSchedule(job, replacement *Job) bool {
+	if job.expr == replacement.expr {
+		return true
+	}
+	a, b := job.cron(), replacement.cron()
+	return a != nil && b != nil && a.Equal(b)
+}
+
+// sameSettings reports whether job has the same settings from its options
+// as the job replacing it. Middleware and WithOnComplete callbacks cannot
+// be compared, and are taken from the replacement like its task.
+func sameSettings(job, replacement *Job) bool {
+	a, b := job, replacement
+	return a.location.String() == b.location.String() &&
+		a.exprEnv == b.exprEnv &&
+		a.jitter == b.jitter &&
+		a.priority == b.priority &&
+		a.overlap == b.overlap &&
+		a.retry == b.retry &&
+		a.timeout == b.timeout &&
+		a.dropQueuedOnTimeout == b.dropQueuedOnTimeout &&
+		a.outputRetention == b.outputRetention &&
+		maps.Equal(a.metadata, b.metadata) &&
+		a.catchUp == b.catchUp &&
+		a.atLeastOnce == b.atLeastOnce &&
+		a.recovery == b.recovery &&
+		a.breaker == b.breaker &&
+		a.panicLimit == b.panicLimit &&
+		a.panicWindow == b.panicWindow &&
+		a.runOnStart == b.runOnStart &&
+		a.stuckAfter == b.stuckAfter &&
+		a.cancelStuck == b.cancelStuck &&
+		a.group == b.group &&
+		a.task == b.task &&
+		a.startDate.Equal(b.startDate) &&
+		a.endDate.Equal(b.endDate) &&
+		a.maxRuns == b.maxRuns &&
+		slices.Equal(a.dependsOn, b.dependsOn) &&
+		reflect.DeepEqual(a.calendars, b.calendars)
+}
@@ -0,0 +1,62 @@
+package cronjob
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff selects how the delay between retry attempts grows.
+type Backoff int
+
+const (
+	// FixedBackoff waits RetryPolicy.Delay between every attempt.
+	FixedBackoff Backoff = iota
+	// ExponentialBackoff doubles the delay after every failed attempt,
+	// starting at RetryPolicy.Delay and capped at RetryPolicy.MaxDelay.
+	ExponentialBackoff
+)
+
+// RetryPolicy configures how a failing task is retried within a single run.
+// A task fails when it returns an error or panics.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	Backoff     Backoff
+	// Delay is the wait before the first retry.
+	Delay time.Duration
+	// MaxDelay caps exponential backoff. Zero means no cap.
+	MaxDelay time.Duration
+	// Jitter adds a random extra wait in [0, Jitter) to every retry.
+	Jitter time.Duration
+}
+
+// WithRetry makes the scheduler retry the job's task according to policy
+// before giving up on a run. Only the final failure is reported to OnError.
+func WithRetry(policy RetryPolicy) JobOption {
+	return func(j *Job) {
+		j.retry = policy
+	}
+}
+
+// delay returns the wait before retry number attempt, where attempt 1 is the
+// first retry.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.Delay
+	if p.Backoff == ExponentialBackoff {
+		for i := 1; i < attempt; i++ {
+			d *= 2
+			if p.MaxDelay > 0 && d >= p.MaxDelay {
+				d = p.MaxDelay
+				break
+			}
+		}
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		d += rand.N(p.Jitter)
+	}
+	return d
+}
@@ -0,0 +1,108 @@
+package cronjob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Schedule tells the scheduler when a job fires. CronExpression and
+// IntervalSchedule are Schedules.
+type Schedule interface {
+	// Next returns the first fire time after from, or the zero time if
+	// there is none.
+	Next(from time.Time) time.Time
+}
+
+var (
+	_ Schedule = (*CronExpression)(nil)
+	_ Schedule = (*IntervalSchedule)(nil)
+)
+
+// IntervalSchedule fires at a fixed interval, for periods like 90 seconds or
+// 7 hours that cron fields cannot express. Unlike "@every", which counts
+// from when the scheduler queues the job, its fire times are the multiples
+// of Interval since the Unix epoch, shifted by Offset, so they are the same
+// whenever the job is added or the process restarts. Intervals dividing a
+// day are aligned to UTC midnight: Every(6*time.Hour) fires at 00:00, 06:00,
+// 12:00 and 18:00 UTC.
+type IntervalSchedule struct {
+	Interval time.Duration
+	Offset   time.Duration
+}
+
+// Every returns a schedule firing every d, at the multiples of d since the
+// Unix epoch.
+func Every(d time.Duration) *IntervalSchedule {
+	return &IntervalSchedule{Interval: d}
+}
+
+// EveryWithOffset returns a schedule firing every d, offset from the
+// multiples of d since the Unix epoch, so EveryWithOffset(time.Hour,
+// 15*time.Minute) fires at a quarter past every hour. Offsets longer than d
+// wrap around.
+func EveryWithOffset(d, offset time.Duration) *IntervalSchedule {
+	return &IntervalSchedule{Interval: d, Offset: offset}
+}
+
+// Next returns the first fire time after from, in from's location, or the
+// zero time if Interval is not positive.
+func (s *IntervalSchedule) Next(from time.Time) time.Time {
+	if s.Interval <= 0 {
+		return time.Time{}
+	}
+	base := time.Unix(0, 0).Add(s.Offset % s.Interval)
+	elapsed := from.Sub(base)
+	steps := elapsed / s.Interval
+	if elapsed < 0 && elapsed%s.Interval != 0 {
+		steps--
+	}
+	return base.Add((steps + 1) * s.Interval).In(from.Location())
+}
+
+// String describes the schedule, e.g. "every 1m30s" or "every 1h0m0s
+// offset 15m0s".
+func (s *IntervalSchedule) String() string {
+	if s.Interval <= 0 || s.Offset%s.Interval == 0 {
+		return fmt.Sprintf("every %s", s.Interval)
+	}
+	return fmt.Sprintf("every %s offset %s", s.Interval, s.Offset)
+}
+
+// AddScheduledJob adds a new job running on schedule, such as a
+// CronExpression returned by ScheduleBuilder.Build or an IntervalSchedule,
+// and returns it, with its generated ID. The scheduler's day matching and
+// DST policy apply to a CronExpression as to parsed expressions. The job's
+// Expression is the schedule's String, if it has one.
+func (c *CronScheduler) AddScheduledJob(schedule Schedule, task func(), opts ...JobOption) (*Job, error) {
+	if schedule == nil {
+		return nil, errors.New("schedule must not be nil")
+	}
+	job := &Job{
+		run:      func(context.Context) error { task(); return nil },
+		index:    -1,
+		taskFunc: task,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+	if expr, ok := schedule.(*CronExpression); ok {
+		s := *expr
+		// Any "H" fields were resolved when the schedule was parsed.
+		s.hashed = false
+		s.DayMatching = c.dayMatching
+		s.DSTPolicy = c.dstPolicy
+		schedule = &s
+	}
+	job.expr, job.exprEnv = "", ""
+	if stringer, ok := schedule.(fmt.Stringer); ok {
+		job.expr = stringer.String()
+	}
+	c.initJob(job, schedule)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.setID(job, c.generateID())
+	c.insertJob(job)
+	return job, nil
+}
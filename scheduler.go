@@ -1,165 +1,1941 @@
 package cronjob
 
 import (
+	"container/heap"
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"os"
 	"runtime/debug"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
 
-// Job represents a job to be run.
+// ErrJobNotFound is returned when a job ID does not match any scheduled job.
+var ErrJobNotFound = errors.New("job not found")
+
+// ErrDuplicateJobID is returned when adding a job whose ID is already in use.
+var ErrDuplicateJobID = errors.New("duplicate job ID")
+
+// ErrTaskNotFound is returned when a task name was not registered with
+// RegisterTask.
+var ErrTaskNotFound = errors.New("task not registered")
+
+// ErrJobTimeout is recorded as a run's error when an attempt exceeds the
+// job's timeout.
+var ErrJobTimeout = errors.New("job timed out")
+
+// ErrJobStuck is recorded as a run's error when the run was cancelled for
+// exceeding the job's WithStuckThreshold, with WithCancelStuck.
+var ErrJobStuck = errors.New("job stuck")
+
+// ErrJobSkipped is returned for a manually triggered run that did not happen,
+// either because the job's overlap policy skipped it or because a queued run
+// was dropped when the scheduler stopped or the job was removed.
+var ErrJobSkipped = errors.New("job run skipped")
+
+// ErrSoftFailure marks a run's error as a soft failure, such as an ExecJob
+// exiting with one of its SoftExitCodes. A task returning an error wrapping
+// it has its run recorded as OutcomeSoftFailure, with the error, but the run
+// otherwise counts as a success: it is not retried, reported to OnError or
+// counted by circuit breakers, and its dependents run.
+var ErrSoftFailure = errors.New("soft failure")
+
+// StillRunningError is returned by StopAndWait when its context ends before
+// every running task has finished.
+type StillRunningError struct {
+	// JobIDs lists the jobs that were still running, sorted.
+	JobIDs []string
+	// Err is the context's error.
+	Err error
+}
+
+func (e *StillRunningError) Error() string {
+	return fmt.Sprintf("jobs still running: %s: %v", strings.Join(e.JobIDs, ", "), e.Err)
+}
+
+func (e *StillRunningError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError is recorded as a run's error when its task panics.
+type PanicError struct {
+	// Recovered is the value passed to panic.
+	Recovered any
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("task panicked: %v", e.Recovered)
+}
+
+// Task is the work a job added with AddJob runs.
+type Task func()
+
+// Job represents a job to be run. Its ID never changes; the rest of its
+// state is read through its methods, which are safe to call while the
+// scheduler runs, or through JobInfo.
 type Job struct {
-	Schedule *CronExpression
-	Task     func()
+	ID string
+	// schedule gives the job's fire times, and taskFunc is the function
+	// passed to AddJob, if any. Both are replaced by UpdateSchedule and
+	// ReplaceJobs, under lock, the scheduler's mutex.
+	schedule Schedule
+	taskFunc Task
+	lock     *sync.Mutex
+
+	run      func(ctx context.Context) error
+	ctx      context.Context
+	cancel   context.CancelFunc
+	location *time.Location
+	// next is the job's next fire time, and index is its position in the
+	// queue of lane, or -1 if the job is not queued. For a job that is not
+	// queued, next caches the fire time reported by JobInfo until it
+	// passes. delay is the random start delay of that run, drawn from
+	// jitter.
+	next   time.Time
+	index  int
+	lane   *lane
+	delay  time.Duration
+	jitter time.Duration
+	// resolution is the scheduler's WithResolution.
+	resolution time.Duration
+	// priority orders the job's runs waiting for a worker or a slot.
+	priority int
+	// seq numbers the job in the order it was added to the scheduler, which
+	// keeps the scheduler's jobs sorted by it.
+	seq uint64
+
+	overlap OverlapPolicy
+	retry   RetryPolicy
+	timeout time.Duration
+	// dropQueuedOnTimeout discards a queued run when the current one times out.
+	dropQueuedOnTimeout bool
+	// running counts the job's in-flight runs, and queued holds the runs
+	// queued by the QueueOne or QueueAll policy, oldest first, each as the
+	// channels waiting for its result.
+	running int
+	queued  [][]chan<- error
+
+	// expr is the expression the job was added with, and exprEnv the
+	// environment variable it was read from, if any.
+	expr    string
+	exprEnv string
+	// Outcome of the most recently finished run.
+	lastRun      time.Time
+	lastError    error
+	lastDuration time.Duration
+	runCount     int
+	history      runHistory
+	stats        jobStats
+
+	// outputRetention is how many of the most recent runs in history keep
+	// their captured output, or 0 for all of them.
+	outputRetention int
+
+	// metadata is saved with the job's definition, and persisted reports
+	// whether that definition is kept in the scheduler's JobStore.
+	metadata  map[string]string
+	persisted bool
+	// lastSuccess is the start time of the last successful run, saved to
+	// the JobStore to detect missed runs on the next Start.
+	lastSuccess time.Time
+	catchUp     CatchUpPolicy
+	// atLeastOnce is set by WithAtLeastOnce. pending holds the scheduled
+	// times of the runs dispatched but not finished, saved to the JobStore,
+	// and interrupted the ones a previous process left pending, which are
+	// handled by recovery on the next Start. recovering is the scheduled
+	// time of the rerun in progress.
+	atLeastOnce bool
+	recovery    RecoveryPolicy
+	pending     []time.Time
+	interrupted []time.Time
+	recovering  time.Time
+
+	// paused keeps the job out of the queue.
+	paused bool
+	// breaker pauses the job after repeated failures, and circuitOpen is
+	// set while it keeps the job paused. cooldown is the timer that closes
+	// the open circuit after the breaker's Cooldown.
+	breaker     CircuitBreaker
+	circuitOpen bool
+	cooldown    *time.Timer
+	// panicLimit panics within panicWindow disable the job, pausing it
+	// and setting disabled; panics holds the times of recent panics.
+	panicLimit  int
+	panicWindow time.Duration
+	panics      []time.Time
+	disabled    bool
+	// runOnStart runs the job once when the scheduler starts.
+	runOnStart bool
+	// stuckAfter, if positive, is how long a run may go before it is
+	// flagged as stuck, and cancelStuck cancels such runs.
+	stuckAfter  time.Duration
+	cancelStuck bool
+	// group is the name of the job's group, if any.
+	group string
+	// task is the name of the registered task the job runs, if any.
+	task string
+	// middleware wraps the job's task.
+	middleware []JobMiddleware
+	// onComplete, if set, is called with the result of every run.
+	onComplete func(RunResult)
+	// startDate and endDate, if set, bound the job's fire times.
+	startDate time.Time
+	endDate   time.Time
+	// maxRuns, if positive, caps scheduledRuns, the number of scheduled
+	// occurrences the job has started.
+	maxRuns       int
+	scheduledRuns int
+	// lastFired is the latest occurrence the scheduler has handled, so
+	// none is started twice.
+	lastFired time.Time
+	// held counts the runs that came due while the scheduler was paused.
+	held int
+	// calendars exclude fire times from the job's schedule.
+	calendars []Calendar
+
+	// dependsOn lists the jobs whose runs must succeed before this job's
+	// run of the same tick starts. awaiting is the tick a run is waiting
+	// on, settledAt the tick of the job's last scheduled run that finished
+	// or was dropped, and succeededAt that of its last successful one.
+	dependsOn   []string
+	awaiting    time.Time
+	settledAt   time.Time
+	succeededAt time.Time
+}
+
+// Expression returns the cron expression the job was added with, as
+// written, or the String of a Schedule it was added with. For expressions,
+// Job.Schedule().String() gives their canonical form.
+func (j *Job) Expression() string {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.expr
+}
+
+// Schedule returns the Schedule giving the job's fire times. It is a
+// *CronExpression for jobs added with an expression.
+func (j *Job) Schedule() Schedule {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.schedule
+}
+
+// Task returns the function passed to AddJob, AddNamedJob or UpsertJob. It
+// is nil for jobs added with AddJobContext, AddJobWithError and the like.
+func (j *Job) Task() Task {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.taskFunc
+}
+
+// OverlapPolicy controls what happens when a job becomes due while a
+// previous run of the same job is still in progress.
+type OverlapPolicy int
+
+const (
+	// AllowConcurrent starts a new run alongside the ones in progress.
+	// This is the default.
+	AllowConcurrent OverlapPolicy = iota
+	// SkipIfRunning drops the new run.
+	SkipIfRunning
+	// QueueOne defers the new run until the current one finishes. At most
+	// one run is kept waiting; further due runs are dropped.
+	QueueOne
+	// QueueAll defers every new run until the ones before it finish, so no
+	// run is dropped. A job that keeps running longer than its interval
+	// queues runs without bound; see WithBackpressureThreshold.
+	QueueAll
+)
+
+// JobOption configures a job when it is added to the scheduler.
+type JobOption func(*Job)
+
+// WithTimeout limits each attempt of the job's task to d. The task's context
+// gets a deadline, and an attempt that runs past it fails with ErrJobTimeout
+// even if the task ignores the context and returns later.
+func WithTimeout(d time.Duration) JobOption {
+	return func(j *Job) {
+		j.timeout = d
+	}
+}
+
+// WithDropQueuedOnTimeout makes a run that times out discard the runs
+// queued behind it by the QueueOne or QueueAll policy, so a hung dependency
+// isn't hit again straight away.
+func WithDropQueuedOnTimeout() JobOption {
+	return func(j *Job) {
+		j.dropQueuedOnTimeout = true
+	}
+}
+
+// WithDependsOn makes each scheduled run of the job wait for the runs of
+// the jobs with the given IDs due at the same time, and start only once all
+// of them have succeeded. If one of them fails, is dropped by its overlap
+// policy or is not due at that time, the job skips the run, emitting
+// EventSkipped, and the run does not count towards WithMaxRuns. Chaining
+// jobs this way builds simple pipelines.
+func WithDependsOn(jobIDs ...string) JobOption {
+	return func(j *Job) {
+		j.dependsOn = jobIDs
+	}
+}
+
+// WithOverlapPolicy sets how the job behaves when it is due while a previous
+// run is still in progress.
+func WithOverlapPolicy(policy OverlapPolicy) JobOption {
+	return func(j *Job) {
+		j.overlap = policy
+	}
+}
+
+// WithLocation makes the job's cron expression be evaluated in loc instead of
+// the scheduler's location, so "0 9 * * Mon" fires at 9 AM local time in loc,
+// following its DST transitions.
+func WithLocation(loc *time.Location) JobOption {
+	return func(j *Job) {
+		j.location = loc
+	}
+}
+
+// WithStartDate keeps the job from firing before t. The job's first run is
+// its first fire time at or after t; an @every job first runs at t, and an
+// @reboot job only runs if the scheduler starts at or after t.
+func WithStartDate(t time.Time) JobOption {
+	return func(j *Job) {
+		j.startDate = t
+	}
+}
+
+// WithEndDate keeps the job from firing after t. Once its last fire time
+// has passed the job stays in the scheduler, inactive, with a zero
+// JobInfo.NextRun, until it is removed. Manual runs are still allowed.
+func WithEndDate(t time.Time) JobOption {
+	return func(j *Job) {
+		j.endDate = t
+	}
+}
+
+// WithMaxRuns deactivates the job once n of its scheduled runs have
+// started, so "0 9 * * MON" with WithMaxRuns(3) runs on the next three
+// Mondays only. Like a job past its end date, it then stays in the scheduler
+// with a zero JobInfo.NextRun. Manual runs are not counted.
+func WithMaxRuns(n int) JobOption {
+	return func(j *Job) {
+		j.maxRuns = n
+	}
+}
+
+// WithRunOnStart runs the job once as soon as the scheduler starts, or as
+// soon as it is added if the scheduler is already running, then on its
+// schedule as usual. This suits cache warmers that must not wait for their
+// first fire time. The run counts as a manual one: it is not delayed by
+// WithJitter nor counted by WithMaxRuns. Paused jobs are not run.
+func WithRunOnStart() JobOption {
+	return func(j *Job) {
+		j.runOnStart = true
+	}
+}
+
+// WithJitter delays each scheduled run of the job by a random amount in
+// [0, maxDelay), so instances sharing a schedule don't all start at the same
+// instant. The run's scheduled time is unchanged in events and JobInfo.
+// Manual runs are not delayed. Keep maxDelay below the schedule's interval:
+// occurrences whose delayed start falls after the next one are skipped.
+func WithJitter(maxDelay time.Duration) JobOption {
+	return func(j *Job) {
+		j.jitter = maxDelay
+	}
+}
+
+// WithExprFromEnv makes the job use the cron expression in the environment
+// variable name, or fallback if the variable is unset or empty, instead of
+// the expression it is added with. An invalid expression fails the add with
+// an error naming the variable, so misconfigured schedules are caught at
+// startup.
+func WithExprFromEnv(name, fallback string) JobOption {
+	return func(j *Job) {
+		j.expr = fallback
+		if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+			j.expr = value
+		}
+		j.exprEnv = name
+	}
+}
+
+// CronScheduler represents a cron job scheduler.
+type CronScheduler struct {
+	jobs []*Job
+	// byID indexes jobs by ID, and dependents indexes the jobs added with
+	// WithDependsOn by the IDs they depend on, so that looking up a job or
+	// settling the dependents of a finished run does not scan every job.
+	// lastSeq is the seq of the last job added.
+	byID       map[string]*Job
+	dependents map[string][]*Job
+	lastSeq    uint64
+	mutex      sync.Mutex
+	running    bool
+	stop       chan struct{}
+	lastID     int
+	// initialDelay postpones every Start, and pendingStart fires the start
+	// delayed by it or by StartAt.
+	initialDelay time.Duration
+	pendingStart *time.Timer
+	// paused holds scheduled runs, with Pause, instead of starting them.
+	paused bool
+
+	// ctx is cancelled when the scheduler is stopped, which in turn
+	// cancels the context of every running task.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// lane queues the jobs with an upcoming fire time while the scheduler
+	// is running, unless isolated gives each job a lane of its own. Its
+	// loop also watches for clock jumps.
+	lane     *lane
+	isolated bool
+	// resolution, if positive, is what every fire time is rounded up to.
+	resolution time.Duration
+	// lastTick is when the shared lane's loop last woke up, and
+	// overdueTolerance how late a run may be before Health flags it.
+	lastTick         time.Time
+	overdueTolerance time.Duration
+	// latenessTolerance is how late a run may start before an
+	// EventMissedDeadline is sent.
+	latenessTolerance time.Duration
+
+	// inflight tracks running execute goroutines and active holds the
+	// jobs, including removed ones, that have at least one run in flight.
+	inflight sync.WaitGroup
+	active   map[*Job]struct{}
+
+	// onError is called with the error returned by a failed task.
+	onError func(jobID string, err error)
+	// panicHandler is called with the value recovered from a panicking
+	// task. When nil, panics are logged.
+	panicHandler func(jobID string, recovered any, stack []byte)
+	// logger, if set, receives a record of every finished run, at
+	// successLevel or failureLevel.
+	logger       *slog.Logger
+	successLevel slog.Level
+	failureLevel slog.Level
+
+	// location is used to evaluate jobs that don't set their own location.
+	location *time.Location
+	// parseMode selects the cron expression layouts accepted by AddJob,
+	// strictness how strictly their fields are read, weekdays how their
+	// days of the week are numbered, and dayMatching how their day fields
+	// combine.
+	parseMode   ParseMode
+	strictness  Strictness
+	weekdays    WeekdayNumbering
+	dayMatching DayMatching
+	// dstPolicy handles fire times in hours skipped by DST changes.
+	dstPolicy DSTPolicy
+	// historySize is the number of finished runs kept per job.
+	historySize int
+
+	// store persists named jobs, and tasks resolves persisted jobs back to
+	// their task functions when the scheduler starts. recordMutex
+	// serializes the saves of run state to the store, so a record never
+	// overwrites a newer one.
+	store       JobStore
+	recordMutex sync.Mutex
+	tasks       TaskRegistry
+	taskOptions map[string][]JobOption
+	// configJobs holds the jobs added by ApplyConfig, and configMutex
+	// serializes config reloads.
+	configJobs  map[string]configuredJob
+	configMutex sync.Mutex
+
+	// locker, if set, coordinates runs with other instances.
+	locker Locker
+	// subscribers receive job events.
+	subscribers []chan<- JobEvent
+
+	// slots, if set, limits how many tasks run at once to maxConcurrent;
+	// limitPolicy handles runs over the limit. starvationLimit is the
+	// longest a waiting run stays behind runs of higher priority.
+	slots           *limiter
+	maxConcurrent   int
+	limitPolicy     LimitPolicy
+	starvationLimit time.Duration
+	// queuedRuns counts the runs queued by QueueOne and QueueAll across all
+	// jobs, and an EventBackpressure is sent when it reaches
+	// backpressureThreshold.
+	queuedRuns            int
+	backpressureThreshold int
+	// groups holds the groups returned by Group, by name.
+	groups map[string]*Group
+	// middleware wraps the task of every job.
+	middleware []JobMiddleware
+
+	// pool runs tasks while the scheduler is running, if it has workers.
+	workers int
+	pool    *workerPool
+}
+
+// SchedulerOption configures a CronScheduler when it is created.
+type SchedulerOption func(*CronScheduler)
+
+// WithParseMode selects which cron expression layouts the scheduler accepts
+// when adding jobs. The default, ParseAuto, accepts both 5-field and 6-field
+// expressions; ParseStandard makes the scheduler read expressions like
+// crontab does, rejecting ones with a seconds field.
+func WithParseMode(mode ParseMode) SchedulerOption {
+	return func(c *CronScheduler) {
+		c.parseMode = mode
+	}
+}
+
+// WithParseStrictness sets how strictly the scheduler reads the fields of
+// cron expressions when adding jobs, as WithStrictness does for
+// ParseCronExpression. The default is Lenient.
+func WithParseStrictness(strictness Strictness) SchedulerOption {
+	return func(c *CronScheduler) {
+		c.strictness = strictness
+	}
+}
+
+// WithParseWeekdayNumbering sets how the day-of-week field of cron
+// expressions numbers the days when adding jobs, as WithWeekdayNumbering
+// does for ParseCronExpression. The default is CronWeekdays; QuartzWeekdays
+// suits schedules carried over from Quartz.
+func WithParseWeekdayNumbering(numbering WeekdayNumbering) SchedulerOption {
+	return func(c *CronScheduler) {
+		c.weekdays = numbering
+	}
+}
+
+// WithResolution rounds every fire time up to a whole multiple of d, such
+// as time.Minute, for schedulers that do not need second precision. Seconds
+// in expressions are then ignored in effect: a job fires at most once per
+// d, at the start of it, and the scheduling loop only wakes on those
+// boundaries, cutting wakeups on battery-powered and edge devices. Jitter
+// delays are rounded up too. Zero, the default, keeps fire times exact.
+func WithResolution(d time.Duration) SchedulerOption {
+	return func(c *CronScheduler) {
+		c.resolution = d
+	}
+}
+
+// WithInitialDelay makes Start wait d before the scheduler starts, without
+// blocking the caller, so the application can finish initializing before
+// jobs fire.
+func WithInitialDelay(d time.Duration) SchedulerOption {
+	return func(c *CronScheduler) {
+		c.initialDelay = d
+	}
+}
+
+// WithIsolatedScheduling gives every job its own scheduling loop, with a
+// timer set to the job's own next run, instead of running all jobs from one
+// shared queue. A job firing every second then never wakes the loops of the
+// others, at the cost of a goroutine per job.
+func WithIsolatedScheduling() SchedulerOption {
+	return func(c *CronScheduler) {
+		c.isolated = true
+	}
+}
+
+// WithDayMatching sets how the day-of-month and day-of-week fields of the
+// scheduler's jobs combine when both are restricted. The default, DayOr,
+// follows standard cron and fires on days matching either field; DayAnd
+// requires both to match.
+func WithDayMatching(matching DayMatching) SchedulerOption {
+	return func(c *CronScheduler) {
+		c.dayMatching = matching
+	}
+}
+
+// NewCronScheduler creates a new CronScheduler that evaluates cron
+// expressions in the local time zone.
+func NewCronScheduler(opts ...SchedulerOption) *CronScheduler {
+	return NewCronSchedulerWithLocation(time.Local, opts...)
+}
+
+// NewCronSchedulerWithLocation creates a new CronScheduler that evaluates
+// cron expressions in loc. A nil loc means the local time zone.
+func NewCronSchedulerWithLocation(loc *time.Location, opts ...SchedulerOption) *CronScheduler {
+	if loc == nil {
+		loc = time.Local
+	}
+	c := &CronScheduler{
+		jobs:       make([]*Job, 0),
+		byID:       make(map[string]*Job),
+		dependents: make(map[string][]*Job),
+		location:   loc,
+		lane:       newLane(),
+		active:     make(map[*Job]struct{}),
+		groups:     make(map[string]*Group),
+
+		historySize:       DefaultHistorySize,
+		overdueTolerance:  DefaultOverdueTolerance,
+		latenessTolerance: DefaultLatenessTolerance,
+		starvationLimit:   DefaultStarvationLimit,
+
+		backpressureThreshold: DefaultBackpressureThreshold,
+		successLevel:          slog.LevelInfo,
+		failureLevel:          slog.LevelError,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.maxConcurrent > 0 {
+		c.slots = newLimiter(c.maxConcurrent, c.starvationLimit)
+	}
+	return c
+}
+
+// AddJob adds a new job running task to the scheduler and returns it, with
+// its generated ID. Everything but the expression and the task, such as the
+// job's time zone, timeout or retries, is set with opts.
+func (c *CronScheduler) AddJob(expr string, task Task, opts ...JobOption) (*Job, error) {
+	job, err := c.newJob(expr, func(context.Context) error { task(); return nil }, opts)
+	if err != nil {
+		return nil, err
+	}
+	job.taskFunc = task
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.setID(job, c.generateID())
+	c.insertJob(job)
+	return job, nil
+}
+
+// AddJobContext adds a new context-aware job to the scheduler and returns
+// it, with its generated ID. The context passed to the task is cancelled
+// when the scheduler is stopped or the job is removed, so long-running
+// tasks can shut down cleanly.
+func (c *CronScheduler) AddJobContext(expr string, task func(ctx context.Context), opts ...JobOption) (*Job, error) {
+	job, err := c.newJob(expr, func(ctx context.Context) error { task(ctx); return nil }, opts)
+	if err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.setID(job, c.generateID())
+	c.insertJob(job)
+	return job, nil
+}
+
+// AddJobWithError adds a new job whose task can fail and returns it, with
+// its generated ID. Errors returned by the task are passed to the handler
+// set with OnError.
+func (c *CronScheduler) AddJobWithError(expr string, task func() error, opts ...JobOption) (*Job, error) {
+	job, err := c.newJob(expr, func(context.Context) error { return task() }, opts)
+	if err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.setID(job, c.generateID())
+	c.insertJob(job)
+	return job, nil
+}
+
+// OnError sets the handler called when a task returns an error, so
+// applications can centralize error reporting. A nil handler discards errors.
+func (c *CronScheduler) OnError(handler func(jobID string, err error)) {
+	c.mutex.Lock()
+	c.onError = handler
+	c.mutex.Unlock()
+}
+
+// SetPanicHandler sets the handler called when a task panics, with the
+// recovered value and the stack trace of the panicking goroutine, so panics
+// can be routed into logging, metrics or alerting. A nil handler restores the
+// default of logging the panic to the WithLogger logger or slog.Default().
+func (c *CronScheduler) SetPanicHandler(handler func(jobID string, recovered any, stack []byte)) {
+	c.mutex.Lock()
+	c.panicHandler = handler
+	c.mutex.Unlock()
+}
+
+// AddNamedJob adds a new job to the scheduler under a user-supplied ID and
+// returns it. It returns ErrDuplicateJobID if the ID is already in use. If
+// the scheduler has a JobStore, the job's definition is saved to it.
+func (c *CronScheduler) AddNamedJob(id, expr string, task func(), opts ...JobOption) (*Job, error) {
+	job, err := c.addNamedJob(id, expr, func(context.Context) error { task(); return nil }, opts, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	job.taskFunc = task
+	c.mutex.Unlock()
+	return job, nil
+}
+
+// UpsertJob adds a job under a user-supplied ID and returns it like
+// AddNamedJob, replacing any job with that ID in one step, so re-registering the same logical job,
+// as on a config reload, never leaves two of it or a moment with none. The
+// replaced job is removed as with RemoveJob, cancelling its runs in
+// progress, and the new job starts with fresh run state. If the scheduler
+// has a JobStore, the new definition overwrites the saved one.
+func (c *CronScheduler) UpsertJob(id, expr string, task func(), opts ...JobOption) (*Job, error) {
+	job, err := c.addNamedJob(id, expr, func(context.Context) error { task(); return nil }, opts, true, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	job.taskFunc = task
+	c.mutex.Unlock()
+	return job, nil
+}
+
+// addNamedJob adds a job under id and persists it to the store, if any. A
+// job already using id is replaced if replace is set, and otherwise fails
+// the add with ErrDuplicateJobID. saved, if not nil, is the job's record
+// already loaded from the store, which is otherwise looked up.
+func (c *CronScheduler) addNamedJob(id, expr string, run func(ctx context.Context) error, opts []JobOption, replace bool, saved *JobRecord) (*Job, error) {
+	if id == "" {
+		return nil, fmt.Errorf("job ID must not be empty")
+	}
+	job, err := c.newJob(expr, run, opts)
+	if err != nil {
+		return nil, err
+	}
+	c.setID(job, id)
+	c.mutex.Lock()
+	store := c.store
+	c.mutex.Unlock()
+	if store != nil && saved == nil {
+		record, ok, err := findRecord(store, id)
+		if err != nil {
+			job.cancel()
+			return nil, fmt.Errorf("loading job %s: %w", id, err)
+		}
+		if ok {
+			saved = &record
+		}
+	}
+	if saved != nil {
+		// Keep the last successful run of a job saved by a previous process.
+		job.restoreRecord(*saved)
+	}
+
+	c.mutex.Lock()
+	if old, ok := c.byID[id]; ok {
+		if !replace {
+			c.mutex.Unlock()
+			job.cancel()
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateJobID, id)
+		}
+		c.removeJob(old)
+		// The saved runs were the replaced job's, not a previous process's.
+		job.pending, job.interrupted = nil, nil
+	}
+	c.insertJob(job)
+	job.persisted = store != nil
+	interrupted := c.takeInterrupted(job)
+	c.mutex.Unlock()
+
+	if store != nil {
+		if err := c.saveRecord(job); err != nil {
+			c.mutex.Lock()
+			if c.byID[id] == job {
+				c.removeJob(job)
+			}
+			c.mutex.Unlock()
+			return nil, fmt.Errorf("saving job %s: %w", id, err)
+		}
+	}
+	if interrupted != nil {
+		go c.recoverRuns(job, interrupted)
+	}
+	return job, nil
+}
+
+// newJob applies opts, parses the job's expression and returns a job with
+// its own cancellable context.
+func (c *CronScheduler) newJob(expr string, run func(ctx context.Context) error, opts []JobOption) (*Job, error) {
+	job := &Job{
+		run:   run,
+		index: -1,
+		expr:  expr,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+	schedule, err := c.parseSchedule(job.expr, "")
+	if err != nil {
+		if job.exprEnv != "" {
+			return nil, fmt.Errorf("%s: %w", job.exprEnv, err)
+		}
+		return nil, err
+	}
+	c.logWarnings(job.expr, schedule)
+	c.initJob(job, schedule)
+	return job, nil
+}
+
+// initJob gives a new job its schedule, its location if its options did
+// not set one, and its own cancellable context.
+func (c *CronScheduler) initJob(job *Job, schedule Schedule) {
+	job.schedule = schedule
+	job.lock = &c.mutex
+	if job.location == nil {
+		job.location = c.location
+	}
+	job.resolution = c.resolution
+	job.ctx, job.cancel = context.WithCancel(context.Background())
+}
+
+// parseSchedule parses expr as an ISO 8601 repeating interval, or as a cron
+// expression with the scheduler's parse mode, day matching and DST policy,
+// hashing "H" fields from id.
+func (c *CronScheduler) parseSchedule(expr, id string) (Schedule, error) {
+	if isRepeatingInterval(expr) {
+		return ParseRepeatingInterval(expr)
+	}
+	schedule, err := parseExpression(expr, parseOptions{mode: c.parseMode, key: id, strictness: c.strictness, weekdays: c.weekdays})
+	if err != nil {
+		return nil, err
+	}
+	schedule.DayMatching = c.dayMatching
+	schedule.DSTPolicy = c.dstPolicy
+	return schedule, nil
+}
+
+// Validate reports whether expr is a valid schedule for the scheduler's
+// jobs: a cron expression as read with its parse mode, or an ISO 8601
+// repeating interval.
+func (c *CronScheduler) Validate(expr string) error {
+	_, err := c.parseSchedule(expr, "")
+	return err
+}
+
+// GetJob returns the job with the given ID.
+func (c *CronScheduler) GetJob(id string) (*Job, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	job, ok := c.byID[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	return job, nil
+}
+
+// RemoveJob removes the job with the given ID from the scheduler, and from
+// the scheduler's JobStore if it was saved there.
+func (c *CronScheduler) RemoveJob(id string) error {
+	c.mutex.Lock()
+	job, ok := c.byID[id]
+	if !ok {
+		c.mutex.Unlock()
+		return fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	c.removeJob(job)
+	store := c.store
+	c.mutex.Unlock()
+
+	if store != nil && job.persisted {
+		if err := store.Delete(id); err != nil {
+			return fmt.Errorf("deleting job %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// PauseJob stops the job from firing on its schedule until ResumeJob is
+// called. Runs in progress are not affected, and RunNow still runs it.
+func (c *CronScheduler) PauseJob(id string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	job, ok := c.byID[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	c.pauseJob(job)
+	return nil
+}
+
+// ResumeJob makes a paused job fire on its schedule again, from its next
+// fire time after now. Runs missed while it was paused are not made up.
+func (c *CronScheduler) ResumeJob(id string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	job, ok := c.byID[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	c.resumeJob(job)
+	return nil
+}
+
+// UpdateSchedule changes the cron expression of the job with the given ID in
+// place, keeping its run history, statistics and pause state. Its next run
+// is recomputed from now; runs in progress are not affected. If the job is
+// saved in the scheduler's JobStore, the saved expression is updated too.
+func (c *CronScheduler) UpdateSchedule(id, expr string) error {
+	schedule, err := c.parseSchedule(expr, id)
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	job, ok := c.byID[id]
+	if !ok {
+		c.mutex.Unlock()
+		return fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	job.schedule = schedule
+	job.expr = expr
+	job.exprEnv = ""
+	job.next = time.Time{}
+	if job.index >= 0 {
+		heap.Remove(&job.lane.queue, job.index)
+		job.lane.notify()
+	}
+	if c.running {
+		c.enqueue(job, time.Now())
+	}
+	store := c.store
+	persisted := store != nil && job.persisted
+	record := job.record()
+	c.mutex.Unlock()
+
+	if persisted {
+		if err := store.Save(record); err != nil {
+			return fmt.Errorf("saving job %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// pauseJob takes job out of the queue until it is resumed. The caller must
+// hold c.mutex.
+func (c *CronScheduler) pauseJob(job *Job) {
+	job.paused = true
+	if job.index >= 0 {
+		heap.Remove(&job.lane.queue, job.index)
+		job.lane.notify()
+	}
+}
+
+// resumeJob queues a paused job again. The caller must hold c.mutex.
+func (c *CronScheduler) resumeJob(job *Job) {
+	if !job.paused {
+		return
+	}
+	job.paused = false
+	job.circuitOpen = false
+	job.stopCooldown()
+	job.disabled, job.panics = false, nil
+	if c.running && job.index < 0 {
+		c.enqueue(job, time.Now())
+	}
+}
+
+// removeJob removes job from the scheduler and cancels its context. The
+// caller must hold c.mutex.
+func (c *CronScheduler) removeJob(job *Job) {
+	job.cancel()
+	job.stopCooldown()
+	c.emit(EventRemoved, job, nil)
+	if job.index >= 0 {
+		heap.Remove(&job.lane.queue, job.index)
+		job.lane.notify()
+	}
+	delete(c.byID, job.ID)
+	for _, id := range job.dependsOn {
+		c.dependents[id] = slices.DeleteFunc(c.dependents[id], func(j *Job) bool { return j == job })
+		if len(c.dependents[id]) == 0 {
+			delete(c.dependents, id)
+		}
+	}
+	i, _ := slices.BinarySearchFunc(c.jobs, job.seq, func(j *Job, seq uint64) int {
+		return int(j.seq) - int(seq)
+	})
+	c.jobs = append(c.jobs[:i], c.jobs[i+1:]...)
+}
+
+// insertJob appends job to the scheduler and, if the scheduler is running,
+// queues its first run, after starting a WithRunOnStart run. The caller must
+// hold c.mutex.
+func (c *CronScheduler) insertJob(job *Job) {
+	c.lastSeq++
+	job.seq = c.lastSeq
+	c.jobs = append(c.jobs, job)
+	c.byID[job.ID] = job
+	for _, id := range slices.Compact(slices.Sorted(slices.Values(job.dependsOn))) {
+		c.dependents[id] = append(c.dependents[id], job)
+	}
+	if !c.running {
+		return
+	}
+	if job.runOnStart && !job.paused && c.tryStart(job, nil) {
+		schedulerCtx := c.ctx
+		c.pool.submit(job.priority, func() { c.execute(schedulerCtx, job, time.Time{}, nil) })
+	}
+	c.enqueue(job, time.Now())
+}
+
+// enqueue computes job's next fire time after now and adds it to the queue.
+// Paused jobs and jobs that will never fire again are left out. The caller must hold c.mutex.
+func (c *CronScheduler) enqueue(job *Job, now time.Time) {
+	if job.paused || job.remainingRuns() == 0 {
+		return
+	}
+	job.next = job.nextAfter(now)
+	if job.next.IsZero() {
+		return
+	}
+	job.drawDelay()
+	l := c.laneFor(job)
+	heap.Push(&l.queue, job)
+	c.emit(EventScheduled, job, nil)
+	l.notify()
+}
+
+// laneFor returns the lane queuing job, giving an isolated job a lane of its
+// own, with its loop, the first time it is queued after Start. The caller
+// must hold c.mutex.
+func (c *CronScheduler) laneFor(job *Job) *lane {
+	if !c.isolated {
+		job.lane = c.lane
+	} else if job.lane == nil || job.lane == c.lane {
+		job.lane = newLane()
+		go c.loop(c.stop, job.lane, job.ctx.Done())
+	}
+	return job.lane
+}
+
+// drawDelay picks the start delay of the job's next run.
+func (j *Job) drawDelay() {
+	j.delay = 0
+	if j.jitter > 0 {
+		j.delay = rand.N(j.jitter)
+	}
+}
+
+// startAt returns when the job's next run starts, after its delay.
+func (j *Job) startAt() time.Time {
+	return roundUp(j.next.Add(j.delay), j.resolution)
+}
+
+// roundUp returns the first whole multiple of d not before t, or t if d is
+// not positive.
+func roundUp(t time.Time, d time.Duration) time.Time {
+	if d <= 0 {
+		return t
+	}
+	rounded := t.Truncate(d)
+	if rounded.Before(t) {
+		rounded = rounded.Add(d)
+	}
+	return rounded
+}
+
+// notify wakes the lane's loop so it re-reads the head of the queue.
+func (l *lane) notify() {
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+// nextAfter returns the job's first fire time after t that none of its
+// calendars exclude, evaluated in the job's location, or the zero time if it
+// will never fire.
+func (j *Job) nextAfter(t time.Time) time.Time {
+	next := j.nextFire(t)
+	for i := 0; len(j.calendars) > 0 && !next.IsZero() && j.excluded(next); i++ {
+		if i == maxExcludedRuns {
+			return time.Time{}
+		}
+		end := j.skipExcluded(next)
+		switch {
+		case end.IsZero():
+			return time.Time{}
+		case !end.After(next):
+			next = j.nextFire(next)
+		case j.every() > 0:
+			// Keep the interval's cadence past the blackout.
+			steps := (end.Sub(next) + j.every() - 1) / j.every()
+			next = j.nextFire(next.Add((steps - 1) * j.every()))
+		default:
+			// Fire times are whole seconds, so one at end is kept.
+			next = j.nextFire(end.Add(-time.Nanosecond))
+		}
+	}
+	return next
+}
+
+// nextFire returns the job's first fire time after t within its start and
+// end dates, or the zero time if there is none. A schedule returning a time
+// that is not after the one it was given never fires again, rather than
+// firing in a busy loop.
+func (j *Job) nextFire(t time.Time) time.Time {
+	var next time.Time
+	switch from := t; {
+	case !j.startDate.IsZero() && t.Before(j.startDate) && j.every() > 0:
+		next = j.startDate
+	case !j.startDate.IsZero() && t.Before(j.startDate):
+		// Fire times are whole seconds, so one at startDate is kept.
+		from = j.startDate.Add(-time.Nanosecond)
+		fallthrough
+	default:
+		next = j.schedule.Next(from.In(j.location))
+		if !next.After(from) {
+			return time.Time{}
+		}
+	}
+	next = roundUp(next, j.resolution)
+	if !j.endDate.IsZero() && next.After(j.endDate) {
+		return time.Time{}
+	}
+	return next.In(j.location)
+}
+
+// cron returns the job's schedule if it is a cron expression, or nil.
+func (j *Job) cron() *CronExpression {
+	expr, _ := j.schedule.(*CronExpression)
+	return expr
+}
+
+// every returns the interval of an "@every" job, or zero for other jobs.
+func (j *Job) every() time.Duration {
+	if expr := j.cron(); expr != nil {
+		return expr.interval
+	}
+	return 0
 }
 
-// CronScheduler represents a cron job scheduler.
-type CronScheduler struct {
-	Jobs    []*Job
-	mutex   sync.Mutex
-	running bool
-	stop    chan struct{}
+// remainingRuns returns how many more scheduled runs the job may start, or
+// -1 if it has no limit.
+func (j *Job) remainingRuns() int {
+	if j.maxRuns <= 0 {
+		return -1
+	}
+	return max(j.maxRuns-j.scheduledRuns, 0)
 }
 
-// NewCronScheduler creates a new CronScheduler.
-func NewCronScheduler() *CronScheduler {
-	return &CronScheduler{
-		Jobs: make([]*Job, 0),
+// inWindow reports whether t is within the job's start and end dates.
+func (j *Job) inWindow(t time.Time) bool {
+	return (j.startDate.IsZero() || !t.Before(j.startDate)) && (j.endDate.IsZero() || !t.After(j.endDate))
+}
+
+// setID gives job its ID, re-resolving the "H" fields of its schedule,
+// which newJob parsed before the ID was known.
+func (c *CronScheduler) setID(job *Job, id string) {
+	job.ID = id
+	if expr := job.cron(); expr != nil && expr.hashed {
+		// The expression parsed once already, so it parses again.
+		job.schedule, _ = c.parseSchedule(job.expr, id)
 	}
 }
 
-// AddJob adds a new job to the scheduler.
-func (c *CronScheduler) AddJob(expr string, task func()) error {
-	schedule, err := ParseCronExpression(expr)
-	if err != nil {
-		return err
+// generateID returns an ID that is not used by any scheduled job.
+// The caller must hold c.mutex.
+func (c *CronScheduler) generateID() string {
+	for {
+		c.lastID++
+		id := fmt.Sprintf("job-%d", c.lastID)
+		if _, ok := c.byID[id]; !ok {
+			return id
+		}
 	}
-	job := &Job{
-		Schedule: schedule,
-		Task:     task,
+}
+
+// Start starts the scheduler. Jobs scheduled with "@reboot" run once each
+// time the scheduler is started. If the scheduler has a JobStore, jobs saved
+// in it are first restored for every name with a registered task. With
+// WithInitialDelay, Start returns at once and the scheduler starts after
+// the delay, as with StartAt.
+//
+// A stopped scheduler can be started again, any number of times. Every job
+// is then queued afresh from its next fire time, runs missed while stopped
+// are made up according to each job's CatchUpPolicy, and run state such as
+// history, statistics and pauses is kept. Runs of the earlier start that are
+// still in progress count towards overlap policies.
+func (c *CronScheduler) Start() {
+	if c.initialDelay > 0 {
+		c.StartAt(time.Now().Add(c.initialDelay))
+		return
 	}
+	c.start(nil)
+}
+
+// StartAt makes the scheduler start at t, so the application can finish
+// initializing before jobs fire, and returns at once. Nothing is scheduled
+// before t: runs due earlier are not made up unless the job's CatchUpPolicy
+// says so. Stop cancels a pending start, and a t in the past starts the
+// scheduler right away. It does nothing if the scheduler is running or a
+// start is already pending; without WithInitialDelay, Start starts a
+// scheduler with a pending start right away.
+func (c *CronScheduler) StartAt(t time.Time) {
 	c.mutex.Lock()
-	c.Jobs = append(c.Jobs, job)
+	if c.running || c.pendingStart != nil {
+		c.mutex.Unlock()
+		return
+	}
+	delay := time.Until(t)
+	if delay <= 0 {
+		c.mutex.Unlock()
+		c.start(nil)
+		return
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		c.mutex.Lock()
+		pending := timer
+		c.mutex.Unlock()
+		c.start(pending)
+	})
+	c.pendingStart = timer
 	c.mutex.Unlock()
-	return nil
 }
 
-// RemoveJob removes a job from the scheduler by index.
-func (c *CronScheduler) RemoveJob(index int) error {
+// start starts the scheduler now. pending is the timer of the delayed
+// start being fired, which is abandoned if it was cancelled, or nil for a
+// direct start, which replaces any pending one.
+func (c *CronScheduler) start(pending *time.Timer) {
 	c.mutex.Lock()
-	defer c.mutex.Unlock()
-	if index < 0 || index >= len(c.Jobs) {
-		return fmt.Errorf("index out of range")
+	running := c.running
+	c.mutex.Unlock()
+	if running {
+		return
 	}
-	c.Jobs = append(c.Jobs[:index], c.Jobs[index+1:]...)
-	return nil
-}
+	c.restoreJobs()
 
-// Start starts the scheduler.
-func (c *CronScheduler) Start() {
 	c.mutex.Lock()
-	if c.running {
+	if c.running || pending != nil && c.pendingStart != pending {
 		c.mutex.Unlock()
 		return
 	}
+	if c.pendingStart != nil {
+		c.pendingStart.Stop()
+		c.pendingStart = nil
+	}
 	c.running = true
 	if c.stop == nil {
 		c.stop = make(chan struct{})
 	}
+	stop := c.stop
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	schedulerCtx := c.ctx
+	c.pool = newWorkerPool(c.workers, c.starvationLimit)
+	pool := c.pool
+	now := time.Now()
+	c.lastTick = now
+	var startJobs []*Job
+	missed := make(map[*Job]int)
+	interrupted := make(map[*Job][]time.Time)
+	for _, job := range c.jobs {
+		reboot := job.cron() != nil && job.cron().reboot && job.inWindow(now) && !job.excluded(now.In(job.location))
+		if (reboot || job.runOnStart && !job.paused) && c.tryStart(job, nil) {
+			startJobs = append(startJobs, job)
+		}
+		if job.catchUp != IgnoreMissed {
+			if n := job.missedRuns(now); n > 0 {
+				if job.catchUp == RunOnceOnStartupIfMissed {
+					n = 1
+				}
+				if c.paused {
+					job.held += n
+				} else {
+					missed[job] = n
+				}
+			}
+		}
+		if ticks := c.takeInterrupted(job); ticks != nil {
+			interrupted[job] = ticks
+		}
+		c.enqueue(job, now)
+	}
 	c.mutex.Unlock()
 
-	go func() {
-		for {
+	for _, job := range startJobs {
+		pool.submit(job.priority, func() { c.execute(schedulerCtx, job, time.Time{}, nil) })
+	}
+	for job, n := range missed {
+		go c.catchUp(job, n)
+	}
+	for job, ticks := range interrupted {
+		go c.recoverRuns(job, ticks)
+	}
+
+	go c.loop(stop, c.lane, nil)
+}
+
+// maxLoopSleep caps how long the scheduling loop sleeps at once, so a jump
+// of the wall clock is noticed within that time.
+const maxLoopSleep = time.Minute
+
+// clockJumpThreshold is how far the wall clock may drift from real time
+// during one sleep of the loop before it counts as a jump.
+const clockJumpThreshold = time.Second
+
+// loop sleeps until the earliest job in l's queue is due, runs every due
+// job, and repeats until stop or done is closed.
+//
+// Sleeps are measured on the monotonic clock, and fire times on the wall
+// clock, so the loop compares the two after every sleep. If the wall clock
+// jumped forward, the runs it skipped over are started once each, as
+// runDueJobs does after any late wake-up; if it went back, queued runs keep
+// their fire times, so none is repeated.
+func (c *CronScheduler) loop(stop <-chan struct{}, l *lane, done <-chan struct{}) {
+	for {
+		c.mutex.Lock()
+		if l == c.lane {
+			c.lastTick = time.Now()
+		}
+		wait := maxLoopSleep
+		if c.resolution > 0 {
+			// Wake on a boundary that fire times share.
 			now := time.Now()
-			c.mutex.Lock()
-			if !c.running {
-				c.mutex.Unlock()
-				return
-			}
-			c.mutex.Unlock()
+			wait = now.Truncate(maxLoopSleep).Add(maxLoopSleep).Sub(now)
+		}
+		if len(l.queue) > 0 {
+			wait = min(time.Until(l.queue[0].startAt()), wait)
+		}
+		c.mutex.Unlock()
 
-			nextRun := c.timeUntilNextJob(now)
-			if nextRun <= 0 {
-				// Run due jobs immediately
-				c.runDueJobs(now)
-				continue
-			}
-			timer := time.NewTimer(nextRun)
-			select {
-			case <-timer.C:
-				c.runDueJobs(time.Now())
-			case <-c.stop:
-				timer.Stop()
+		if wait <= 0 {
+			// Run due jobs immediately
+			if !c.runDueJobs(stop, l, time.Now()) {
 				return
 			}
+			continue
 		}
-	}()
+		slept := time.Now()
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-l.wake:
+			timer.Stop()
+		case <-stop:
+			timer.Stop()
+			return
+		case <-done:
+			timer.Stop()
+			return
+		}
+		now := time.Now()
+		if l == c.lane {
+			c.checkClock(now.Round(0).Sub(slept.Round(0)), now.Sub(slept))
+		}
+		if !c.runDueJobs(stop, l, now) {
+			return
+		}
+	}
+}
+
+// checkClock sends an EventClockJump if the wall clock moved by wall while
+// elapsed passed on the monotonic clock, and the two differ by more than
+// clockJumpThreshold.
+func (c *CronScheduler) checkClock(wall, elapsed time.Duration) {
+	jump := wall - elapsed
+	if jump > -clockJumpThreshold && jump < clockJumpThreshold {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.publish(JobEvent{Type: EventClockJump, Time: time.Now(), Jump: jump})
 }
 
-// Stop stops the scheduler.
+// Stop stops the scheduler, or cancels its pending start, and cancels the
+// context of every running task. It does not wait for the tasks to return;
+// use StopAndWait for that. The scheduler can be started again with Start.
 func (c *CronScheduler) Stop() {
 	c.mutex.Lock()
-	if c.running {
-		c.running = false
-		close(c.stop)
-		c.stop = nil
+	if c.stopScheduling() {
+		c.cancel()
 	}
 	c.mutex.Unlock()
 }
 
+// StopAndWait stops scheduling new runs and waits for the running tasks to
+// finish. If ctx ends first, the context of every remaining task is cancelled
+// and a *StillRunningError listing those jobs is returned.
+func (c *CronScheduler) StopAndWait(ctx context.Context) error {
+	c.mutex.Lock()
+	stopped := c.stopScheduling()
+	cancel := c.cancel
+	c.mutex.Unlock()
+	if stopped {
+		defer cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		c.mutex.Lock()
+		ids := make([]string, 0, len(c.active))
+		for job := range c.active {
+			ids = append(ids, job.ID)
+		}
+		c.mutex.Unlock()
+		sort.Strings(ids)
+		return &StillRunningError{JobIDs: ids, Err: ctx.Err()}
+	}
+}
+
+// stopScheduling stops the scheduling loop without cancelling running tasks
+// and reports whether the scheduler was running. The caller must hold c.mutex.
+func (c *CronScheduler) stopScheduling() bool {
+	if c.pendingStart != nil {
+		c.pendingStart.Stop()
+		c.pendingStart = nil
+	}
+	if !c.running {
+		return false
+	}
+	c.running = false
+	close(c.stop)
+	c.stop = nil
+	c.pool.stop()
+	c.pool = nil
+	c.lane.queue = c.lane.queue[:0]
+	for _, job := range c.jobs {
+		job.index = -1
+		job.lane = nil
+		job.held = 0
+	}
+	return true
+}
+
+// nextRunTime returns the first time strictly after fromTime, at second
+// resolution, that matches expr in fromTime's location. Rather than testing
+// every second, it skips whole months, days, hours and minutes whose field
+// doesn't match, and whole years outside the year field. It returns the zero
+// time if nothing matches within five years of the first allowed year.
 func nextRunTime(expr *CronExpression, fromTime time.Time) time.Time {
-	// Start from the next minute
-	nextTime := fromTime.Add(time.Minute - time.Duration(fromTime.Second())*time.Second - time.Duration(fromTime.Nanosecond()))
-	// Limit to prevent infinite loops in case of errors
-	maxIterations := 1000000
-	for i := 0; i < maxIterations; i++ {
-		if isTimeMatching(expr, nextTime) {
-			return nextTime
+	if expr.reboot {
+		return time.Time{}
+	}
+	if expr.interval > 0 {
+		return fromTime.Add(expr.interval)
+	}
+	next := nextMatchingTime(expr, fromTime)
+	if expr.DSTPolicy == DSTFireOnce && expr.hours.len() < 24 {
+		until := next
+		if until.IsZero() {
+			until = fromTime.AddDate(5, 0, 0)
+		}
+		if skipped := nextSkippedTime(expr, fromTime, until); !skipped.IsZero() {
+			return skipped
+		}
+	}
+	return next
+}
+
+// nextMatchingTime returns the first time after fromTime, in its location,
+// whose wall clock matches expr, counting a wall clock time repeated when
+// clocks go back only once unless expr fires every hour.
+func nextMatchingTime(expr *CronExpression, fromTime time.Time) time.Time {
+	loc := fromTime.Location()
+	// Start from the next second
+	t := fromTime.Truncate(time.Second).Add(time.Second)
+	yearLimit := t.Year() + 5
+
+	// Each step moves t forward to the start of the next month, day, hour,
+	// minute or second. Hours, minutes and seconds are advanced in absolute
+	// time so DST transitions never move t backwards.
+	for t.Year() <= yearLimit {
+		if expr.years != nil && !slices.Contains(expr.years, t.Year()) {
+			year := nextYear(expr.years, t.Year())
+			if year == 0 {
+				return time.Time{}
+			}
+			t = time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+			yearLimit = year + 5
+			continue
+		}
+		if !expr.months.has(int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !isDayMatching(expr, t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !expr.hours.has(t.Hour()) {
+			t = t.Add(time.Hour - time.Duration(t.Minute())*time.Minute - time.Duration(t.Second())*time.Second)
+			continue
+		}
+		if !expr.minutes.has(t.Minute()) {
+			t = t.Add(time.Minute - time.Duration(t.Second())*time.Second)
+			continue
+		}
+		if !expr.seconds.has(t.Second()) {
+			t = t.Add(time.Second)
+			continue
+		}
+		if expr.hours.len() < 24 && isRepeatedWallTime(t) {
+			t = t.Add(time.Second)
+			continue
 		}
-		nextTime = nextTime.Add(time.Minute)
+		return t
 	}
-	// If we exceed maxIterations, return zero time
 	return time.Time{}
 }
 
-func (c *CronScheduler) runDueJobs(now time.Time) {
+// nextYear returns the first of years after year, or 0 if there is none.
+func nextYear(years []int, year int) int {
+	next := 0
+	for _, y := range years {
+		if y > year && (next == 0 || y < next) {
+			next = y
+		}
+	}
+	return next
+}
+
+// runDueJobs starts every job queued in l whose fire time is not after now
+// and queues its following run. It reports false, doing nothing, if stop is
+// no longer the running scheduler's, so a loop woken around a Stop, or a
+// Stop and a new Start, exits instead of serving the new loops' queues.
+func (c *CronScheduler) runDueJobs(stop <-chan struct{}, l *lane, now time.Time) bool {
 	c.mutex.Lock()
+	if !c.running || c.stop != stop {
+		// Stopped while the loop was waking up.
+		c.mutex.Unlock()
+		return false
+	}
 	jobsToRun := make([]*Job, 0)
-	for _, job := range c.Jobs {
-		if isTimeMatching(job.Schedule, now) {
+	ticks := make([]time.Time, 0)
+	for len(l.queue) > 0 && !l.queue[0].startAt().After(now) {
+		job := l.queue[0]
+		switch {
+		case !job.next.After(job.lastFired):
+			// The occurrence was handled already, as when the clock was
+			// set back and the job requeued: it never runs twice, and
+			// the job waits for its first occurrence after it.
+			job.next = job.lastFired
+		case c.paused:
+			job.held++
+		case len(job.dependsOn) > 0 && c.dependenciesFailed(job, job.next):
+			c.emit(EventSkipped, job, nil)
+			c.releaseDependents(job, job.next, false)
+		case len(job.dependsOn) > 0 && !c.dependenciesSucceeded(job, job.next):
+			// Wait for the dependencies' runs of the same tick, unless
+			// they finished first, as when the job is jittered. A run
+			// still waiting on an earlier tick never starts.
+			if !job.awaiting.IsZero() {
+				c.emit(EventSkipped, job, nil)
+				c.releaseDependents(job, job.awaiting, false)
+			}
+			job.awaiting = job.next
+		case c.tryStart(job, nil):
 			jobsToRun = append(jobsToRun, job)
+			ticks = append(ticks, job.next)
+			job.scheduledRuns++
+		default:
+			// Dropped or queued by the overlap policy, so the run of
+			// this tick that dependents wait on never happens.
+			c.releaseDependents(job, job.next, false)
+		}
+		job.lastFired = job.next
+		// Keep the cadence of interval jobs, but never schedule a run
+		// that is already in the past.
+		next := job.nextAfter(job.next)
+		if !next.IsZero() && !next.After(now) {
+			next = job.nextAfter(now)
 		}
+		if next.IsZero() || job.remainingRuns() == 0 {
+			heap.Pop(&l.queue)
+			continue
+		}
+		job.next = next
+		job.drawDelay()
+		heap.Fix(&l.queue, 0)
+		c.emit(EventScheduled, job, nil)
 	}
+	schedulerCtx := c.ctx
+	pool := c.pool
 	c.mutex.Unlock()
 
-	for _, job := range jobsToRun {
-		go func(job *Job) {
-			defer func() {
-				if r := recover(); r != nil {
-					// Log the panic with stack trace
-					fmt.Printf("Task panicked: %v\nStack trace:\n%s\n", r, debug.Stack())
-				}
-			}()
-			job.Task()
-		}(job)
+	for i, job := range jobsToRun {
+		if job.atLeastOnce {
+			c.beginRun(job, ticks[i])
+		}
+		pool.submit(job.priority, func() { c.execute(schedulerCtx, job, ticks[i], nil) })
+	}
+	return true
+}
+
+// releaseDependents settles the runs of job's dependents waiting on tick
+// once job's run of that tick has finished, or was dropped. Dependents
+// whose dependencies have all succeeded at tick are started and returned,
+// counting the run towards WithMaxRuns; if job failed, they skip this tick,
+// and so do their own dependents. The caller must hold c.mutex.
+func (c *CronScheduler) releaseDependents(job *Job, tick time.Time, succeeded bool) []*Job {
+	job.settledAt = tick
+	var started []*Job
+	for _, dependent := range c.dependents[job.ID] {
+		if !dependent.awaiting.Equal(tick) {
+			continue
+		}
+		if !succeeded {
+			dependent.awaiting = time.Time{}
+			c.emit(EventSkipped, dependent, nil)
+			started = append(started, c.releaseDependents(dependent, tick, false)...)
+			continue
+		}
+		if !c.dependenciesSucceeded(dependent, tick) {
+			continue
+		}
+		dependent.awaiting = time.Time{}
+		if !c.running || !c.tryStart(dependent, nil) {
+			started = append(started, c.releaseDependents(dependent, tick, false)...)
+			continue
+		}
+		started = append(started, dependent)
+		dependent.scheduledRuns++
+		if dependent.remainingRuns() == 0 && dependent.index >= 0 {
+			heap.Remove(&dependent.lane.queue, dependent.index)
+			dependent.lane.notify()
+		}
+	}
+	return started
+}
+
+// dependenciesSucceeded reports whether every dependency of job has
+// succeeded in its run of tick. The caller must hold c.mutex.
+func (c *CronScheduler) dependenciesSucceeded(job *Job, tick time.Time) bool {
+	for _, id := range job.dependsOn {
+		if dependency, ok := c.byID[id]; !ok || !dependency.succeededAt.Equal(tick) {
+			return false
+		}
+	}
+	return true
+}
+
+// dependenciesFailed reports whether a dependency of job is missing or has
+// finished or dropped its run of tick without succeeding. The caller must
+// hold c.mutex.
+func (c *CronScheduler) dependenciesFailed(job *Job, tick time.Time) bool {
+	for _, id := range job.dependsOn {
+		dependency, ok := c.byID[id]
+		if !ok || dependency.settledAt.Equal(tick) && !dependency.succeededAt.Equal(tick) {
+			return true
+		}
+	}
+	return false
+}
+
+// RunNow triggers a run of the job outside its schedule, respecting its
+// overlap policy. It returns without waiting for the run, or ErrJobSkipped if
+// the policy drops it. Runs triggered while the scheduler is stopped are not
+// cancelled by Start or Stop.
+func (c *CronScheduler) RunNow(id string) error {
+	done, err := c.trigger(id)
+	if err != nil {
+		return err
+	}
+	select {
+	case err := <-done:
+		if errors.Is(err, ErrJobSkipped) {
+			return err
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+// RunNowAndWait triggers a run of the job like RunNow and waits for it to
+// finish, returning the task's error. If ctx ends first, it returns ctx.Err()
+// and the run continues in the background.
+func (c *CronScheduler) RunNowAndWait(ctx context.Context, id string) error {
+	done, err := c.trigger(id)
+	if err != nil {
+		return err
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
-func (c *CronScheduler) timeUntilNextJob(now time.Time) time.Duration {
+// trigger starts or queues a manual run of the job and returns a channel
+// that receives the run's result.
+func (c *CronScheduler) trigger(id string) (<-chan error, error) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	minDuration := time.Hour * 24 * 365 // 1 year
-	for _, job := range c.Jobs {
-		nextRun := nextRunTime(job.Schedule, now)
-		if nextRun.IsZero() {
-			continue
+	job, ok := c.byID[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	schedulerCtx := context.Background()
+	if c.running {
+		schedulerCtx = c.ctx
+	}
+	done := make(chan error, 1)
+	if c.tryStart(job, done) {
+		c.pool.submit(job.priority, func() { c.execute(schedulerCtx, job, time.Time{}, done) })
+	}
+	return done, nil
+}
+
+// tryStart applies job's overlap policy to a due run and reports whether it
+// should start now. If done is not nil it receives the run's result, or
+// ErrJobSkipped if the run is dropped. The caller must hold c.mutex and, if
+// tryStart returns true, must call execute with the same done.
+func (c *CronScheduler) tryStart(job *Job, done chan<- error) bool {
+	if job.running > 0 {
+		switch job.overlap {
+		case SkipIfRunning:
+			c.emit(EventSkipped, job, nil)
+			if done != nil {
+				done <- ErrJobSkipped
+			}
+			return false
+		case QueueOne:
+			if len(job.queued) > 0 {
+				c.emit(EventSkipped, job, nil)
+				if done != nil {
+					job.queued[0] = append(job.queued[0], done)
+				}
+				return false
+			}
+			c.queueRun(job, done)
+			return false
+		case QueueAll:
+			c.queueRun(job, done)
+			return false
+		}
+	}
+	job.running++
+	c.active[job] = struct{}{}
+	c.inflight.Add(1)
+	return true
+}
+
+// execute runs job, then any runs queued behind it by the QueueOne or
+// QueueAll policy, delivering each run's result to its waiters. Queued runs
+// are dropped once the scheduler stops or the job is removed. tick is the scheduled time of
+// the first run, or zero if it was not scheduled; when set, the run's
+// outcome releases the jobs depending on job.
+func (c *CronScheduler) execute(schedulerCtx context.Context, job *Job, tick time.Time, done chan<- error) {
+	defer c.inflight.Done()
+	var waiters []chan<- error
+	if done != nil {
+		waiters = append(waiters, done)
+	}
+	for {
+		err := c.runLimited(schedulerCtx, job, tick)
+		if !tick.IsZero() && !errors.Is(err, ErrJobSkipped) {
+			c.finishRun(job, tick)
+		}
+		for _, w := range waiters {
+			w <- err
+		}
+
+		c.mutex.Lock()
+		if !tick.IsZero() {
+			if err == nil {
+				job.succeededAt = tick
+			}
+			for _, dependent := range c.releaseDependents(job, tick, err == nil) {
+				dependentTick := tick
+				c.pool.submit(dependent.priority, func() { c.execute(schedulerCtx, dependent, dependentTick, nil) })
+			}
+			tick = time.Time{}
+		}
+		job.running--
+		var dropped [][]chan<- error
+		if job.dropQueuedOnTimeout && errors.Is(err, ErrJobTimeout) {
+			dropped = c.dequeueRuns(job, len(job.queued))
+		}
+		rerun := len(job.queued) > 0 && c.running && schedulerCtx.Err() == nil && job.ctx.Err() == nil
+		if rerun {
+			waiters = c.dequeueRuns(job, 1)[0]
+			job.running++
+		} else {
+			for range job.queued {
+				c.emit(EventSkipped, job, nil)
+			}
+			dropped = append(dropped, c.dequeueRuns(job, len(job.queued))...)
+			if job.running == 0 {
+				delete(c.active, job)
+			}
+		}
+		c.mutex.Unlock()
+		if !rerun {
+			for _, waiters := range dropped {
+				for _, w := range waiters {
+					w <- ErrJobSkipped
+				}
+			}
+			return
+		}
+	}
+}
+
+// runJob executes a single run of job with a context that is cancelled when
+// either the scheduler is stopped or the job is removed, and returns the
+// run's error. tick is the scheduled time of the run, or zero if it was not
+// scheduled.
+func (c *CronScheduler) runJob(schedulerCtx context.Context, job *Job, tick time.Time) error {
+	cancelCtx, cancel := context.WithCancelCause(job.ctx)
+	defer cancel(nil)
+	stop := context.AfterFunc(schedulerCtx, func() { cancel(nil) })
+	defer stop()
+	output := &runOutput{}
+	ctx := context.WithValue(cancelCtx, runOutputKey{}, output)
+
+	start := time.Now()
+	stopWatchdog := c.watch(job, cancel)
+	c.mutex.Lock()
+	c.checkLateness(job, tick, start)
+	c.emit(EventStarted, job, nil)
+	handler := c.handler(job)
+	run := RunInfo{
+		JobID:      job.ID,
+		Expression: job.expr,
+		Scheduled:  tick,
+		Attempt:    1,
+		Group:      job.group,
+		Metadata:   job.metadata,
+	}
+	c.mutex.Unlock()
+	err := c.attempt(ctx, job, handler, run)
+	for attempt := 1; err != nil && !errors.Is(err, ErrSoftFailure) && attempt < job.retry.MaxAttempts; attempt++ {
+		timer := time.NewTimer(job.retry.delay(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		run.Attempt = attempt + 1
+		err = c.attempt(ctx, job, handler, run)
+	}
+	stopWatchdog()
+	duration := time.Since(start)
+	if errors.Is(context.Cause(ctx), ErrJobStuck) {
+		if err == nil || errors.Is(err, context.Canceled) {
+			err = fmt.Errorf("%w after %v", ErrJobStuck, job.stuckAfter)
+		} else {
+			err = fmt.Errorf("%w after %v: %w", ErrJobStuck, job.stuckAfter, err)
+		}
+	}
+
+	// runErr is the error the run is recorded with, which only differs from
+	// err for soft failures.
+	runErr := err
+	if errors.Is(err, ErrSoftFailure) {
+		err = nil
+	}
+
+	c.mutex.Lock()
+	job.lastRun = start
+	job.lastError = runErr
+	if err == nil {
+		job.lastSuccess = start
+	}
+	job.lastDuration = duration
+	job.runCount++
+	job.stats.record(duration, err)
+	runRecord := RunRecord{
+		Start:    start,
+		End:      start.Add(duration),
+		Duration: duration,
+		Outcome:  outcomeOf(runErr),
+		Err:      runErr,
+	}
+	output.record(&runRecord)
+	job.history.add(runRecord, c.historySize)
+	job.history.trimOutput(job.outputRetention)
+	c.emitResult(job, err)
+	c.checkCircuit(job, err)
+	c.checkPanics(job, err)
+	onError, onComplete := c.onError, job.onComplete
+	save := err == nil && job.persisted && c.store != nil
+	c.mutex.Unlock()
+	var saveErr error
+	if save {
+		saveErr = c.saveRecord(job)
+	}
+
+	c.logRun(job, tick, start, duration, err)
+	if onComplete != nil {
+		onComplete(RunResult{
+			JobID:     job.ID,
+			Scheduled: tick,
+			Start:     start,
+			Duration:  duration,
+			Outcome:   outcomeOf(runErr),
+			Err:       runErr,
+			Attempt:   run.Attempt,
+		})
+	}
+	var panicErr *PanicError
+	if err != nil && !errors.As(err, &panicErr) && onError != nil {
+		onError(job.ID, err)
+	}
+	if saveErr != nil && onError != nil {
+		onError(job.ID, fmt.Errorf("saving last successful run: %w", saveErr))
+	}
+	return err
+}
+
+// attempt makes a single attempt at job's task through handler, enforcing
+// its timeout.
+func (c *CronScheduler) attempt(ctx context.Context, job *Job, handler JobHandler, run RunInfo) error {
+	if job.timeout <= 0 {
+		return c.invoke(ctx, job, handler, run)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, job.timeout)
+	defer cancel()
+	deadline, _ := attemptCtx.Deadline()
+	err := c.invoke(attemptCtx, job, handler, run)
+	// Check the clock too, since the context's timer may not have fired yet
+	// when a task that ignores it returns late.
+	timedOut := errors.Is(attemptCtx.Err(), context.DeadlineExceeded) || !time.Now().Before(deadline)
+	if ctx.Err() == nil && timedOut {
+		if err == nil || errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("%w after %v", ErrJobTimeout, job.timeout)
 		}
-		duration := nextRun.Sub(now)
-		if duration < minDuration {
-			minDuration = duration
+		return fmt.Errorf("%w after %v: %w", ErrJobTimeout, job.timeout, err)
+	}
+	return err
+}
+
+// invoke calls job's task through handler, reporting a panic to the panic
+// handler and returning it as a *PanicError.
+func (c *CronScheduler) invoke(ctx context.Context, job *Job, handler JobHandler, run RunInfo) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			c.handlePanic(job.ID, r, stack)
+			err = &PanicError{Recovered: r, Stack: stack}
 		}
+	}()
+	return handler(ctx, run)
+}
+
+// handlePanic reports a recovered task panic to the panic handler.
+func (c *CronScheduler) handlePanic(jobID string, recovered any, stack []byte) {
+	c.mutex.Lock()
+	handler := c.panicHandler
+	c.mutex.Unlock()
+	if handler == nil {
+		c.logPanic(jobID, recovered, stack)
+		return
 	}
-	return minDuration
+	handler(jobID, recovered, stack)
 }
 
 // ListJobs lists all jobs in the scheduler.
@@ -167,33 +1943,102 @@ func (c *CronScheduler) ListJobs() []string {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 	var jobList []string
-	for i, job := range c.Jobs {
-		jobList = append(jobList, fmt.Sprintf("Job %d: %v", i, job.Schedule))
+	for _, job := range c.jobs {
+		jobList = append(jobList, fmt.Sprintf("Job %s: %s", job.ID, job.expr))
 	}
 	return jobList
 }
 
 func isTimeMatching(expr *CronExpression, t time.Time) bool {
-	if !contains(expr.Minutes, t.Minute()) {
+	if !expr.seconds.has(t.Second()) {
 		return false
 	}
-	if !contains(expr.Hours, t.Hour()) {
+	if !expr.minutes.has(t.Minute()) {
 		return false
 	}
-	if !contains(expr.DayOfMonth, t.Day()) {
+	if !expr.hours.has(t.Hour()) {
 		return false
 	}
-	if !contains(expr.Month, int(t.Month())) {
+	if !expr.months.has(int(t.Month())) {
 		return false
 	}
+	if expr.years != nil && !slices.Contains(expr.years, t.Year()) {
+		return false
+	}
+	return isDayMatching(expr, t)
+}
+
+// isDayMatching reports whether the date of t matches the day-of-month and
+// day-of-week fields of expr, combined as selected by expr.DayMatching.
+func isDayMatching(expr *CronExpression, t time.Time) bool {
+	dayOfMonth := isDayOfMonthMatching(expr, t)
+	dayOfWeek := isDayOfWeekMatching(expr, t)
+	if expr.DayMatching == DayOr && !expr.anyDayOfMonth && !expr.anyDayOfWeek {
+		return dayOfMonth || dayOfWeek
+	}
+	return dayOfMonth && dayOfWeek
+}
+
+func isDayOfMonthMatching(expr *CronExpression, t time.Time) bool {
+	if expr.daysOfMonth.has(t.Day()) {
+		return true
+	}
+	for _, offset := range expr.lastDaysOfMonth {
+		if t.Day() == daysInMonth(t)-offset {
+			return true
+		}
+	}
+	for _, day := range expr.nearestWeekdays {
+		if t.Day() == nearestWeekday(t, day) {
+			return true
+		}
+	}
+	return expr.lastWeekdayOfMonth && t.Day() == nearestWeekday(t, daysInMonth(t))
+}
+
+// isDayOfWeekMatching reports whether t's weekday matches the day-of-week
+// field, where Sunday is always 0 since the parser normalizes 7 to 0.
+func isDayOfWeekMatching(expr *CronExpression, t time.Time) bool {
 	weekday := int(t.Weekday())
-	if weekday == 0 {
-		weekday = 7 // Adjust for Sunday=0 in Go but 7 in cron
+	if expr.daysOfWeek.has(weekday) {
+		return true
 	}
-	if !contains(expr.DayOfWeek, weekday%7) {
-		return false
+	for _, nth := range expr.nthWeekdays {
+		if nth.weekday == weekday && (t.Day()-1)/7+1 == nth.n {
+			return true
+		}
 	}
-	return true
+	// The last given weekday is the one with no such weekday a week later.
+	return contains(expr.lastWeekdays, weekday) && t.Day()+7 > daysInMonth(t)
+}
+
+// nearestWeekday returns the Monday-to-Friday day of t's month nearest to
+// day, never crossing into another month, or 0 if the month has no such day.
+// A Saturday moves to the Friday before and a Sunday to the Monday after,
+// except on the month's first and last days, which move inwards instead.
+func nearestWeekday(t time.Time, day int) int {
+	last := daysInMonth(t)
+	if day > last {
+		return 0
+	}
+	switch time.Date(t.Year(), t.Month(), day, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return 3
+		}
+		return day - 1
+	case time.Sunday:
+		if day == last {
+			return day - 2
+		}
+		return day + 1
+	}
+	return day
+}
+
+// daysInMonth returns the number of days in t's month.
+func daysInMonth(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
 }
 
 func contains(list []int, value int) bool {
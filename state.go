@@ -0,0 +1,140 @@
+package cronjob
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+)
+
+// stateVersion is the version of the format written by ExportState.
+const stateVersion = 1
+
+// SchedulerState is a snapshot of a scheduler's jobs, as written by
+// ExportState.
+type SchedulerState struct {
+	Version    int        `json:"version"`
+	ExportedAt time.Time  `json:"exported_at"`
+	Jobs       []JobState `json:"jobs"`
+}
+
+// JobState is the definition, pause state and statistics of a job in a
+// SchedulerState.
+type JobState struct {
+	ID         string `json:"id"`
+	Expression string `json:"expression"`
+	// Task is the name of the registered task the job runs, for jobs added
+	// with AddRegisteredJob, restored from a JobStore or configured with
+	// ApplyConfig.
+	Task     string            `json:"task,omitempty"`
+	Group    string            `json:"group,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Paused   bool              `json:"paused,omitempty"`
+
+	RunCount      int           `json:"run_count"`
+	ScheduledRuns int           `json:"scheduled_runs"`
+	LastRun       time.Time     `json:"last_run"`
+	LastDuration  time.Duration `json:"last_duration"`
+	LastError     string        `json:"last_error,omitempty"`
+	LastSuccess   time.Time     `json:"last_success"`
+}
+
+// ExportState returns a JSON snapshot of every job's definition, pause
+// state and statistics, for backups or moving jobs to another instance
+// with ImportState. Run history and in-flight runs are not included.
+func (c *CronScheduler) ExportState() ([]byte, error) {
+	c.mutex.Lock()
+	state := SchedulerState{
+		Version:    stateVersion,
+		ExportedAt: time.Now(),
+		Jobs:       make([]JobState, 0, len(c.jobs)),
+	}
+	for _, job := range c.jobs {
+		js := JobState{
+			ID:            job.ID,
+			Expression:    job.expr,
+			Task:          job.task,
+			Group:         job.group,
+			Metadata:      maps.Clone(job.metadata),
+			Paused:        job.paused,
+			RunCount:      job.runCount,
+			ScheduledRuns: job.scheduledRuns,
+			LastRun:       job.lastRun,
+			LastDuration:  job.lastDuration,
+			LastSuccess:   job.lastSuccess,
+		}
+		if job.lastError != nil {
+			js.LastError = job.lastError.Error()
+		}
+		state.Jobs = append(state.Jobs, js)
+	}
+	c.mutex.Unlock()
+	return json.Marshal(state)
+}
+
+// ImportState adds the jobs of a snapshot written by ExportState, with their
+// pause states and statistics. Each job runs the function of tasks under
+// its task name, or under its ID if it has none, with the options that
+// task was registered with by RegisterTask, if any. A job with the ID of
+// one already in the scheduler replaces it, and is saved to the
+// scheduler's JobStore, if it has one. If any job of data cannot be
+// imported, because its task is missing from tasks or its expression is
+// invalid, nothing is changed.
+func (c *CronScheduler) ImportState(data []byte, tasks TaskRegistry) error {
+	var state SchedulerState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("parsing scheduler state: %w", err)
+	}
+	if state.Version != stateVersion {
+		return fmt.Errorf("unsupported scheduler state version: %d", state.Version)
+	}
+
+	var errs []error
+	for _, js := range state.Jobs {
+		if _, ok := tasks[js.taskName()]; !ok {
+			errs = append(errs, fmt.Errorf("job %s: %w: %s", js.ID, ErrTaskNotFound, js.taskName()))
+		} else if err := c.Validate(js.Expression); err != nil {
+			errs = append(errs, fmt.Errorf("job %s: %w", js.ID, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	c.mutex.Lock()
+	taskOptions := c.taskOptions
+	c.mutex.Unlock()
+	for _, js := range state.Jobs {
+		opts := append([]JobOption{WithMetadata(js.Metadata), WithGroup(js.Group), withTask(js.taskName())}, taskOptions[js.taskName()]...)
+		opts = append(opts, js.restore())
+		if _, err := c.addNamedJob(js.ID, js.Expression, tasks[js.taskName()], opts, true, nil); err != nil {
+			errs = append(errs, fmt.Errorf("job %s: %w", js.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// taskName returns the name the job's task is looked up by on import.
+func (js JobState) taskName() string {
+	if js.Task != "" {
+		return js.Task
+	}
+	return js.ID
+}
+
+// restore returns a job option setting the job's pause state and
+// statistics from js.
+func (js JobState) restore() JobOption {
+	return func(j *Job) {
+		j.paused = js.Paused
+		j.runCount = js.RunCount
+		j.scheduledRuns = js.ScheduledRuns
+		j.lastRun = js.LastRun
+		j.lastDuration = js.LastDuration
+		j.lastSuccess = js.LastSuccess
+		if js.LastError != "" {
+			j.lastError = errors.New(js.LastError)
+		}
+	}
+}
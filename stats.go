@@ -0,0 +1,92 @@
+package cronjob
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// statsWindow is the number of recent runs P95Duration is computed over.
+const statsWindow = 100
+
+// JobStats summarizes a job's finished runs, for monitoring SLOs without
+// external tooling.
+type JobStats struct {
+	Runs int
+	// Failures counts the runs that returned an error, panicked or timed
+	// out, and ConsecutiveFailures the ones since the last success.
+	Failures            int
+	ConsecutiveFailures int
+	// SuccessRate is the fraction of runs that succeeded, or 0 if the job
+	// has not run.
+	SuccessRate  float64
+	MeanDuration time.Duration
+	MaxDuration  time.Duration
+	// P95Duration is the 95th percentile duration of the last 100 runs.
+	P95Duration time.Duration
+}
+
+// Stats returns the running statistics of the job's finished runs since it
+// was added.
+func (c *CronScheduler) Stats(id string) (JobStats, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	job, ok := c.byID[id]
+	if !ok {
+		return JobStats{}, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	return job.stats.snapshot(), nil
+}
+
+// jobStats accumulates a job's run statistics.
+type jobStats struct {
+	runs                int
+	failures            int
+	consecutiveFailures int
+	total               time.Duration
+	max                 time.Duration
+	// recent is a ring buffer of the last statsWindow durations, next
+	// slot to overwrite at next.
+	recent []time.Duration
+	next   int
+}
+
+// record adds a finished run that took duration and ended with err.
+func (s *jobStats) record(duration time.Duration, err error) {
+	s.runs++
+	if err != nil {
+		s.failures++
+		s.consecutiveFailures++
+	} else {
+		s.consecutiveFailures = 0
+	}
+	s.total += duration
+	s.max = max(s.max, duration)
+	if len(s.recent) < statsWindow {
+		s.recent = append(s.recent, duration)
+		return
+	}
+	s.recent[s.next] = duration
+	s.next = (s.next + 1) % statsWindow
+}
+
+// snapshot returns the statistics recorded so far.
+func (s *jobStats) snapshot() JobStats {
+	stats := JobStats{
+		Runs:                s.runs,
+		Failures:            s.failures,
+		ConsecutiveFailures: s.consecutiveFailures,
+		MaxDuration:         s.max,
+	}
+	if s.runs == 0 {
+		return stats
+	}
+	stats.SuccessRate = float64(s.runs-s.failures) / float64(s.runs)
+	stats.MeanDuration = s.total / time.Duration(s.runs)
+	// Nearest-rank percentile over the recent durations.
+	sorted := slices.Sorted(slices.Values(s.recent))
+	rank := int(math.Ceil(0.95 * float64(len(sorted))))
+	stats.P95Duration = sorted[rank-1]
+	return stats
+}
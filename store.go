@@ -0,0 +1,336 @@
+package cronjob
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxCatchUpRuns caps how many missed runs RunAllMissed replays for a job.
+const maxCatchUpRuns = 1000
+
+// CatchUpPolicy controls what a persisted job does on Start about the runs
+// it missed while the process was down.
+type CatchUpPolicy int
+
+const (
+	// IgnoreMissed skips missed runs. This is the default.
+	IgnoreMissed CatchUpPolicy = iota
+	// RunOnceOnStartupIfMissed runs the job once if at least one run was
+	// missed.
+	RunOnceOnStartupIfMissed
+	// RunAllMissed runs the job once for every missed run, one after the
+	// other, up to 1000 runs.
+	RunAllMissed
+)
+
+// JobRecord is the persisted definition of a named job.
+type JobRecord struct {
+	Name       string            `json:"name"`
+	Expression string            `json:"expression"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	// LastSuccess is the start time of the job's last successful run.
+	LastSuccess time.Time `json:"last_success"`
+	// Pending holds the scheduled times of the runs of a WithAtLeastOnce
+	// job that were dispatched but have not finished.
+	Pending []time.Time `json:"pending,omitempty"`
+}
+
+// JobStore persists job definitions so they survive restarts.
+// Implementations must be safe for concurrent use.
+type JobStore interface {
+	// Save creates or replaces the record with the same name.
+	Save(record JobRecord) error
+	// Load returns every saved record.
+	Load() ([]JobRecord, error)
+	// Delete removes the record with the given name. Deleting a missing
+	// record is not an error.
+	Delete(name string) error
+}
+
+// TaskRegistry maps task names to their functions, so jobs restored from a
+// JobStore or loaded from configuration can be bound to code.
+type TaskRegistry map[string]func(ctx context.Context) error
+
+// WithStore makes the scheduler save jobs added with AddNamedJob to store,
+// delete them on RemoveJob, and restore them on Start for every name
+// registered with RegisterTask.
+func WithStore(store JobStore) SchedulerOption {
+	return func(c *CronScheduler) {
+		c.store = store
+	}
+}
+
+// WithMetadata attaches metadata to the job, saved with its definition in
+// the scheduler's JobStore.
+func WithMetadata(metadata map[string]string) JobOption {
+	return func(j *Job) {
+		j.metadata = metadata
+	}
+}
+
+// withTask records the name of the registered task the job runs.
+func withTask(name string) JobOption {
+	return func(j *Job) {
+		j.task = name
+	}
+}
+
+// WithCatchUp sets what the job does on Start about runs missed since its
+// last successful run recorded in the scheduler's JobStore.
+func WithCatchUp(policy CatchUpPolicy) JobOption {
+	return func(j *Job) {
+		j.catchUp = policy
+	}
+}
+
+// RegisterTask registers task under name, so a job with that name saved in
+// the scheduler's JobStore is restored with it, and with opts, when the
+// scheduler starts.
+func (c *CronScheduler) RegisterTask(name string, task func(ctx context.Context) error, opts ...JobOption) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.tasks == nil {
+		c.tasks = make(TaskRegistry)
+		c.taskOptions = make(map[string][]JobOption)
+	}
+	c.tasks[name] = task
+	c.taskOptions[name] = opts
+}
+
+// AddRegisteredJob adds a job named name running the task registered under
+// that name with RegisterTask, with the task's options, and returns it. It
+// returns ErrTaskNotFound if no task is registered under name. If the
+// scheduler has a JobStore, the job's definition is saved to it, and
+// restored on Start.
+func (c *CronScheduler) AddRegisteredJob(name, expr string) (*Job, error) {
+	c.mutex.Lock()
+	task, ok := c.tasks[name]
+	opts := append([]JobOption{withTask(name)}, c.taskOptions[name]...)
+	c.mutex.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, name)
+	}
+	return c.addNamedJob(name, expr, task, opts, false, nil)
+}
+
+// restoreJobs adds the jobs saved in the store that have a registered task
+// and are not already scheduled. Load failures and invalid records are
+// reported to the OnError handler under the job's name.
+func (c *CronScheduler) restoreJobs() {
+	c.mutex.Lock()
+	store, onError := c.store, c.onError
+	c.mutex.Unlock()
+	if store == nil {
+		return
+	}
+
+	records, err := store.Load()
+	if err != nil {
+		if onError != nil {
+			onError("", fmt.Errorf("loading jobs: %w", err))
+		}
+		return
+	}
+	for _, record := range records {
+		c.mutex.Lock()
+		task, ok := c.tasks[record.Name]
+		opts := append([]JobOption{WithMetadata(record.Metadata), withTask(record.Name)}, c.taskOptions[record.Name]...)
+		_, exists := c.byID[record.Name]
+		c.mutex.Unlock()
+		if !ok || exists {
+			continue
+		}
+		_, err := c.addNamedJob(record.Name, record.Expression, task, opts, false, &record)
+		if err != nil && !errors.Is(err, ErrDuplicateJobID) && onError != nil {
+			onError(record.Name, fmt.Errorf("restoring job: %w", err))
+		}
+	}
+}
+
+// findRecord returns the record saved under name, if any.
+func findRecord(store JobStore, name string) (JobRecord, bool, error) {
+	records, err := store.Load()
+	if err != nil {
+		return JobRecord{}, false, err
+	}
+	for _, record := range records {
+		if record.Name == name {
+			return record, true, nil
+		}
+	}
+	return JobRecord{}, false, nil
+}
+
+// missedRuns returns how many times job should have fired between its last
+// successful run, or its last interrupted run if later, and now, capped at
+// maxCatchUpRuns. The caller must hold c.mutex.
+func (j *Job) missedRuns(now time.Time) int {
+	if j.lastSuccess.IsZero() {
+		return 0
+	}
+	from := j.lastSuccess
+	for _, tick := range j.interrupted {
+		if tick.After(from) {
+			from = tick
+		}
+	}
+	missed := 0
+	it := j.runs(from)
+	for next := it.Next(); !next.IsZero() && next.Before(now); next = it.Next() {
+		missed++
+		if missed == maxCatchUpRuns {
+			break
+		}
+	}
+	return missed
+}
+
+// catchUp runs job n times in a row, stopping early if the scheduler stops
+// or the job is removed. If the scheduler is paused, the remaining runs are
+// held until it is resumed.
+func (c *CronScheduler) catchUp(job *Job, n int) {
+	for i := 0; i < n; i++ {
+		c.mutex.Lock()
+		if !c.running || job.ctx.Err() != nil {
+			c.mutex.Unlock()
+			return
+		}
+		if c.paused {
+			job.held += n - i
+			c.mutex.Unlock()
+			return
+		}
+		done := make(chan error, 1)
+		ctx := c.ctx
+		if c.tryStart(job, done) {
+			c.pool.submit(job.priority, func() { c.execute(ctx, job, time.Time{}, done) })
+		}
+		c.mutex.Unlock()
+		<-done
+	}
+}
+
+// record returns the persisted definition of job.
+func (j *Job) record() JobRecord {
+	return JobRecord{
+		Name:        j.ID,
+		Expression:  j.expr,
+		Metadata:    j.metadata,
+		LastSuccess: j.lastSuccess,
+		Pending:     slices.Clone(j.pending),
+	}
+}
+
+// FileStore is a JobStore that keeps every record in a single JSON file.
+type FileStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+var _ JobStore = (*FileStore)(nil)
+
+// NewFileStore returns a FileStore backed by the file at path. The file is
+// created on the first Save.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Save implements JobStore.
+func (s *FileStore) Save(record JobRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	records, err := s.read()
+	if err != nil {
+		return err
+	}
+	records[record.Name] = record
+	return s.write(records)
+}
+
+// Load implements JobStore. Records are returned sorted by name.
+func (s *FileStore) Load() ([]JobRecord, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	records, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]JobRecord, 0, len(records))
+	for _, record := range records {
+		list = append(list, record)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// Delete implements JobStore.
+func (s *FileStore) Delete(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	records, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := records[name]; !ok {
+		return nil
+	}
+	delete(records, name)
+	return s.write(records)
+}
+
+// read loads the file's records keyed by name. A missing file holds none.
+func (s *FileStore) read() (map[string]JobRecord, error) {
+	records := make(map[string]JobRecord)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []JobRecord
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", s.path, err)
+	}
+	for _, record := range list {
+		records[record.Name] = record
+	}
+	return records, nil
+}
+
+// write replaces the file atomically by writing to a temporary file in the
+// same directory and renaming it over the original.
+func (s *FileStore) write(records map[string]JobRecord) error {
+	list := make([]JobRecord, 0, len(records))
+	for _, record := range records {
+		list = append(list, record)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
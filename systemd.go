@@ -0,0 +1,257 @@
+package cronjob
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// calendarShorthands maps systemd's named calendar events to their full
+// form.
+var calendarShorthands = map[string]string{
+	"minutely":     "*-*-* *:*:00",
+	"hourly":       "*-*-* *:00:00",
+	"daily":        "*-*-* 00:00:00",
+	"weekly":       "Mon *-*-* 00:00:00",
+	"monthly":      "*-*-01 00:00:00",
+	"quarterly":    "*-01,04,07,10-01 00:00:00",
+	"semiannually": "*-01,07-01 00:00:00",
+	"yearly":       "*-01-01 00:00:00",
+	"annually":     "*-01-01 00:00:00",
+}
+
+// weekdayNames maps the lower case short and long weekday names systemd
+// accepts to their numbers.
+var weekdayNames = map[string]int{
+	"sun": 0, "sunday": 0,
+	"mon": 1, "monday": 1,
+	"tue": 2, "tuesday": 2,
+	"wed": 3, "wednesday": 3,
+	"thu": 4, "thursday": 4,
+	"fri": 5, "friday": 5,
+	"sat": 6, "saturday": 6,
+}
+
+// calendarSchedule is a Schedule parsed from a systemd calendar event.
+type calendarSchedule struct {
+	expr *CronExpression
+	// loc, if set, is the time zone named by the event, which overrides
+	// the job's location.
+	loc  *time.Location
+	text string
+}
+
+// ParseOnCalendar parses a systemd calendar event, as used by the OnCalendar
+// setting of timer units, into a Schedule, for moving systemd timers into
+// the process:
+//
+//	DayOfWeek Year-Month-Day Hour:Minute:Second TimeZone
+//
+// Every part is optional: the weekday defaults to any, the date to
+// "*-*-*", the time to 00:00:00 and the seconds to 00, and the time zone to
+// the job's location. Components take "*", lists ("Mon,Fri"), ranges
+// ("Mon..Fri", "9..17") and repetitions ("*:0/15" for every quarter hour),
+// and "~" counts days from the end of the month ("*-*~01" is the last day).
+// The shorthands minutely, hourly, daily, weekly, monthly, quarterly,
+// semiannually, yearly and annually are accepted. Examples:
+//
+//	Mon..Fri 10:00
+//	*-*-01 00:00:00
+//	Sat *-*-* 02:30 Europe/Lisbon
+func ParseOnCalendar(expr string) (Schedule, error) {
+	text := strings.TrimSpace(expr)
+	if full, ok := calendarShorthands[strings.ToLower(text)]; ok {
+		text = full
+	}
+	tokens := strings.Fields(text)
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("invalid calendar event: %q: empty", expr)
+	}
+	schedule := &calendarSchedule{text: strings.Join(tokens, " ")}
+	if last := tokens[len(tokens)-1]; len(tokens) > 1 && !strings.ContainsAny(last, ":*") {
+		if _, err := parseCalendarValues("day-of-week", last, 0, 6, weekdayNames); err != nil {
+			if loc, err := time.LoadLocation(last); err == nil {
+				schedule.loc = loc
+				tokens = tokens[:len(tokens)-1]
+			}
+		}
+	}
+
+	weekdays, date, clock := "*", "*-*-*", "00:00:00"
+	if first := tokens[0][0]; (first < '0' || first > '9') && first != '*' {
+		weekdays, tokens = tokens[0], tokens[1:]
+	}
+	for _, token := range tokens {
+		switch {
+		case strings.Contains(token, ":") && clock == "00:00:00":
+			clock = token
+		case strings.ContainsAny(token, "-~") && date == "*-*-*":
+			date = token
+		default:
+			return nil, fmt.Errorf("invalid calendar event: %q: unexpected %q", expr, token)
+		}
+	}
+
+	cron, err := parseCalendarEvent(weekdays, date, clock)
+	if err != nil {
+		return nil, fmt.Errorf("invalid calendar event: %q: %w", expr, err)
+	}
+	schedule.expr = cron
+	return schedule, nil
+}
+
+// parseCalendarEvent builds the cron expression matching the weekday, date
+// and time parts of a calendar event.
+func parseCalendarEvent(weekdays, date, clock string) (*CronExpression, error) {
+	expr := &CronExpression{anyDayOfWeek: weekdays == "*", anyDayOfMonth: true}
+	var err error
+	if expr.daysOfWeek, err = parseCalendarSet("day-of-week", weekdays, 0, 6, weekdayNames); err != nil {
+		return nil, err
+	}
+
+	// The date is Year-Month-Day or Month-Day, with "~" instead of the last
+	// "-" counting days from the end of the month.
+	var year, month, day string
+	fromEnd := strings.Contains(date, "~")
+	parts := strings.Split(strings.Replace(date, "~", "-", 1), "-")
+	switch len(parts) {
+	case 2:
+		year, month, day = "*", parts[0], parts[1]
+	case 3:
+		year, month, day = parts[0], parts[1], parts[2]
+	default:
+		return nil, fmt.Errorf("invalid date: %s", date)
+	}
+	if year != "*" {
+		if expr.years, err = parseCalendarValues("year", year, 1970, 2099, nil); err != nil {
+			return nil, err
+		}
+	}
+	if expr.months, err = parseCalendarSet("month", month, 1, 12, nil); err != nil {
+		return nil, err
+	}
+	days, err := parseCalendarValues("day-of-month", day, 1, 31, nil)
+	switch {
+	case err != nil:
+		return nil, err
+	case fromEnd:
+		// "~1" is the last day, which is offset 0 from it.
+		for _, d := range days {
+			expr.lastDaysOfMonth = append(expr.lastDaysOfMonth, d-1)
+		}
+		expr.anyDayOfMonth = false
+	default:
+		expr.daysOfMonth = newFieldSet(days)
+		expr.anyDayOfMonth = day == "*"
+	}
+
+	parts = strings.Split(clock, ":")
+	if len(parts) == 2 {
+		parts = append(parts, "00")
+	}
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid time: %s", clock)
+	}
+	if expr.hours, err = parseCalendarSet("hour", parts[0], 0, 23, nil); err != nil {
+		return nil, err
+	}
+	if expr.minutes, err = parseCalendarSet("minute", parts[1], 0, 59, nil); err != nil {
+		return nil, err
+	}
+	if expr.seconds, err = parseCalendarSet("second", parts[2], 0, 59, nil); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+// parseCalendarSet is parseCalendarValues for fields held as a fieldSet.
+func parseCalendarSet(field, component string, min, max int, names map[string]int) (fieldSet, error) {
+	values, err := parseCalendarValues(field, component, min, max, names)
+	return newFieldSet(values), err
+}
+
+// parseCalendarValues parses one component of a calendar event, a comma
+// separated list of "*", values, "a..b" ranges and "a/n" or "a..b/n"
+// repetitions, into its sorted values. names, if set, maps lower case names
+// to values, and ranges of names may wrap around.
+func parseCalendarValues(field, component string, min, max int, names map[string]int) ([]int, error) {
+	var values []int
+	for _, part := range strings.Split(component, ",") {
+		span, repeat, stepped := strings.Cut(part, "/")
+		step := 1
+		if stepped {
+			n, err := strconv.Atoi(repeat)
+			if err != nil || n <= 0 {
+				return nil, &FieldError{Field: field, Value: component, Err: fmt.Errorf("invalid repetition: %s", part)}
+			}
+			step = n
+		}
+		first, last := min, max
+		if span != "*" {
+			from, to, isRange := strings.Cut(span, "..")
+			var err error
+			if first, err = calendarValue(from, min, max, names); err != nil {
+				return nil, &FieldError{Field: field, Value: component, Err: err}
+			}
+			last = first
+			switch {
+			case isRange:
+				if last, err = calendarValue(to, min, max, names); err != nil {
+					return nil, &FieldError{Field: field, Value: component, Err: err}
+				}
+				if last < first && names == nil {
+					return nil, &FieldError{Field: field, Value: component, Err: fmt.Errorf("invalid range: %s", span)}
+				}
+			case stepped:
+				last = max
+			}
+		}
+		if last < first {
+			// Weekday ranges like "Sat..Sun" wrap around the week.
+			last += max - min + 1
+		}
+		for v := first; v <= last; v += step {
+			values = append(values, min+(v-min)%(max-min+1))
+		}
+	}
+	if len(values) == 0 {
+		return nil, &FieldError{Field: field, Value: component, Err: errors.New("no values")}
+	}
+	return slices.Compact(slices.Sorted(slices.Values(values))), nil
+}
+
+// calendarValue parses a single value of a calendar event component.
+func calendarValue(value string, min, max int, names map[string]int) (int, error) {
+	if n, ok := names[strings.ToLower(value)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value: %s", value)
+	}
+	if n < min || n > max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", n, min, max)
+	}
+	return n, nil
+}
+
+// Next returns the first time after from matching the event, in from's
+// location.
+func (s *calendarSchedule) Next(from time.Time) time.Time {
+	if s.loc == nil {
+		return s.expr.Next(from)
+	}
+	next := s.expr.Next(from.In(s.loc))
+	if next.IsZero() {
+		return next
+	}
+	return next.In(from.Location())
+}
+
+// String returns the event as it was parsed, with shorthands expanded.
+func (s *calendarSchedule) String() string {
+	return s.text
+}
@@ -0,0 +1,47 @@
+package cronjob
+
+import "context"
+
+// AddTypedJob adds a new job whose task computes a value, such as the path
+// of a generated report, and returns it, with its generated ID. After every
+// successful run, onResult is called with the job's ID and the value, on the
+// goroutine that ran the task; errors are passed to the handler set with
+// OnError, as with AddJobWithError. Use ResultChan to receive values on a
+// channel instead.
+//
+// AddTypedJob is a function rather than a method because Go methods cannot
+// have type parameters.
+func AddTypedJob[T any](c *CronScheduler, expr string, task func(ctx context.Context) (T, error), onResult func(jobID string, result T), opts ...JobOption) (*Job, error) {
+	var job *Job
+	run := func(ctx context.Context) error {
+		result, err := task(ctx)
+		if err != nil {
+			return err
+		}
+		if onResult != nil {
+			onResult(job.ID, result)
+		}
+		return nil
+	}
+	job, err := c.newJob(expr, run, opts)
+	if err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.setID(job, c.generateID())
+	c.insertJob(job)
+	return job, nil
+}
+
+// ResultChan returns an AddTypedJob result callback sending each value to
+// ch. Values are never waited on: if ch is full, the value is dropped, so
+// give ch enough buffer for its consumer to keep up.
+func ResultChan[T any](ch chan<- T) func(jobID string, result T) {
+	return func(_ string, result T) {
+		select {
+		case ch <- result:
+		default:
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package cronjob
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// WithStuckThreshold makes the scheduler flag a run of the job that is
+// still going d after it started, retries included, by sending an
+// EventStuck and, with WithLogger, logging a warning, so hung tasks are
+// surfaced instead of silently holding a goroutine. Unlike WithTimeout, the
+// run is left alone unless WithCancelStuck is also set.
+func WithStuckThreshold(d time.Duration) JobOption {
+	return func(j *Job) {
+		j.stuckAfter = d
+	}
+}
+
+// WithCancelStuck makes a run flagged by WithStuckThreshold have its context
+// cancelled, with ErrJobStuck as the cause. Once it returns, the run fails
+// with ErrJobStuck.
+func WithCancelStuck() JobOption {
+	return func(j *Job) {
+		j.cancelStuck = true
+	}
+}
+
+// watch starts the watchdog of a run of job started now, which cancels the
+// run through cancel if the job says so. The returned function stops it.
+func (c *CronScheduler) watch(job *Job, cancel context.CancelCauseFunc) (stop func() bool) {
+	if job.stuckAfter <= 0 {
+		return func() bool { return false }
+	}
+	timer := time.AfterFunc(job.stuckAfter, func() {
+		c.mutex.Lock()
+		c.emit(EventStuck, job, nil)
+		c.mutex.Unlock()
+		if c.logger != nil {
+			c.logger.Warn("job stuck",
+				slog.String("job", job.ID),
+				slog.Duration("running_for", job.stuckAfter),
+				slog.Bool("cancelled", job.cancelStuck))
+		}
+		if job.cancelStuck {
+			cancel(ErrJobStuck)
+		}
+	})
+	return timer.Stop
+}
@@ -0,0 +1,145 @@
+package cronjob
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// SignatureHeader is the header a Webhook with a Secret signs its requests
+// in, as "sha256=" followed by the hex HMAC-SHA256 of the body.
+const SignatureHeader = "X-Cronjob-Signature"
+
+// Webhook posts the scheduler's job events as JSON to URLs, so alerts can
+// be wired into Slack or incident tooling without glue code:
+//
+//	webhook := &cronjob.Webhook{URLs: []string{"https://hooks.example.com/cron"}, Secret: secret}
+//	stop := webhook.Watch(scheduler)
+//	defer stop()
+//
+// Each event is posted as a WebhookPayload.
+type Webhook struct {
+	URLs []string
+	// Events lists the event types posted. If it is nil, they are
+	// EventStarted, EventSucceeded, EventFailed and EventPanicked.
+	Events []EventType
+	// Secret, if set, signs every request in the SignatureHeader header,
+	// so receivers can check it came from the scheduler.
+	Secret []byte
+	// Retry controls how deliveries failing with a network error or a 429
+	// or 5xx response are retried. If MaxAttempts is 0, deliveries are
+	// tried 3 times with exponential backoff from one second.
+	Retry RetryPolicy
+	// Client sends the requests. If it is nil, a client with a 10 second
+	// timeout is used.
+	Client *http.Client
+	// OnError, if set, is called when a delivery fails after its retries.
+	OnError func(url string, event JobEvent, err error)
+}
+
+// WebhookPayload is the JSON body a Webhook posts for an event.
+type WebhookPayload struct {
+	// Event is the event type's name, such as "failed".
+	Event string    `json:"event"`
+	JobID string    `json:"job_id"`
+	Time  time.Time `json:"time"`
+	// Error is the run's error, for failed and panicked runs.
+	Error string `json:"error,omitempty"`
+}
+
+// webhookEventBuffer is how many events a Webhook holds while it delivers
+// earlier ones.
+const webhookEventBuffer = 256
+
+// Watch subscribes the webhook to the scheduler's events and starts posting
+// them. Deliveries happen in the background, each event to every URL
+// concurrently, so a slow receiver never delays a run. The returned
+// function stops it.
+func (w *Webhook) Watch(c *CronScheduler) (stop func()) {
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	retry := w.Retry
+	if retry.MaxAttempts == 0 {
+		retry = RetryPolicy{MaxAttempts: 3, Backoff: ExponentialBackoff, Delay: time.Second}
+	}
+	events := w.Events
+	if events == nil {
+		events = []EventType{EventStarted, EventSucceeded, EventFailed, EventPanicked}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	stopWatching := c.watchEvents(webhookEventBuffer, func(event JobEvent) {
+		if !slices.Contains(events, event.Type) {
+			return
+		}
+		payload := WebhookPayload{Event: event.Type.String(), JobID: event.JobID, Time: event.Time}
+		if event.Err != nil {
+			payload.Error = event.Err.Error()
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return
+		}
+		for _, url := range w.URLs {
+			go func() {
+				if err := w.deliver(ctx, client, retry, url, body); err != nil && w.OnError != nil {
+					w.OnError(url, event, err)
+				}
+			}()
+		}
+	})
+	return func() {
+		stopWatching()
+		cancel()
+	}
+}
+
+// deliver posts body to url, retrying according to retry, until ctx is
+// cancelled.
+func (w *Webhook) deliver(ctx context.Context, client *http.Client, retry RetryPolicy, url string, body []byte) error {
+	retryable, err := w.post(ctx, client, url, body)
+	for attempt := 1; err != nil && retryable && attempt < retry.MaxAttempts; attempt++ {
+		timer := time.NewTimer(retry.delay(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+		retryable, err = w.post(ctx, client, url, body)
+	}
+	return err
+}
+
+// post makes a single delivery attempt of body to url, reporting whether a
+// failure is worth retrying.
+func (w *Webhook) post(ctx context.Context, client *http.Client, url string, body []byte) (retryable bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(w.Secret) > 0 {
+		mac := hmac.New(sha256.New, w.Secret)
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retryable, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return false, nil
+}